@@ -5,10 +5,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"sync"
-	"time"
 
 	"github.com/platforma-dev/platforma/database"
 	"github.com/platforma-dev/platforma/log"
@@ -17,6 +20,9 @@ import (
 // ErrUnknownCommand is returned when an unknown CLI command is provided.
 var ErrUnknownCommand = errors.New("unknown command")
 
+// ErrConflictingRepository is returned when a repository with migrations is registered in more than one database.
+var ErrConflictingRepository = errors.New("repository with migrations is registered in multiple databases")
+
 // ErrDatabaseMigrationFailed is an error type that represents a failed database migration.
 type ErrDatabaseMigrationFailed struct {
 	err error
@@ -32,18 +38,31 @@ func (e *ErrDatabaseMigrationFailed) Unwrap() error {
 	return e.err
 }
 
+type migrator interface {
+	Migrations() fs.FS
+}
+
 // Application manages startup tasks and services for the application lifecycle.
 type Application struct {
 	startupTasks   []startupTask
 	services       map[string]Runner
+	serviceDeps    map[string][]string
 	healthcheckers map[string]Healthchecker
 	databases      map[string]*database.Database
+	migrationRepos map[string][]string
 	health         *Health
+	autoMigrate    bool
+	withoutSignals bool
 }
 
 // New creates and returns a new Application instance.
-func New() *Application {
-	return &Application{services: make(map[string]Runner), healthcheckers: make(map[string]Healthchecker), databases: make(map[string]*database.Database), health: NewHealth()}
+func New(opts ...Option) *Application {
+	a := &Application{services: make(map[string]Runner), serviceDeps: make(map[string][]string), healthcheckers: make(map[string]Healthchecker), databases: make(map[string]*database.Database), migrationRepos: make(map[string][]string), health: NewHealth()}
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
 }
 
 // Health returns the current health status of the application.
@@ -51,6 +70,8 @@ func (a *Application) Health(ctx context.Context) *Health {
 	for hcName, hc := range a.healthcheckers {
 		a.health.SetServiceData(hcName, hc.Healthcheck(ctx))
 	}
+	a.health.updateOverallStatus()
+
 	return a.health
 }
 
@@ -70,8 +91,14 @@ func (a *Application) RegisterDatabase(dbName string, db *database.Database) {
 }
 
 // RegisterRepository adds a repository to the application.
+// Registering a repository with migrations under the same name in several databases
+// makes migrate fail with ErrConflictingRepository.
 func (a *Application) RegisterRepository(dbName string, repoName string, repository any) {
 	a.databases[dbName].RegisterRepository(repoName, repository)
+
+	if _, ok := repository.(migrator); ok && !slices.Contains(a.migrationRepos[repoName], dbName) {
+		a.migrationRepos[repoName] = append(a.migrationRepos[repoName], dbName)
+	}
 }
 
 // RegisterService adds a named service to the application.
@@ -100,9 +127,27 @@ func (a *Application) printUsage() {
 	fmt.Println("Commands:")
 	fmt.Println("  run       Start the application")
 	fmt.Println("  migrate   Run database migrations")
+	fmt.Println("  task      Run startup tasks and exit")
+}
+
+// checkRepositories reports repositories whose migrations are registered in several databases.
+// Such databases may point at the same physical database and share its migrations table.
+func (a *Application) checkRepositories() error {
+	for _, repoName := range slices.Sorted(maps.Keys(a.migrationRepos)) {
+		dbNames := a.migrationRepos[repoName]
+		if len(dbNames) > 1 {
+			return fmt.Errorf("%w: %s in %s", ErrConflictingRepository, repoName, strings.Join(dbNames, ", "))
+		}
+	}
+
+	return nil
 }
 
 func (a *Application) migrate(ctx context.Context) error {
+	if err := a.checkRepositories(); err != nil {
+		return err
+	}
+
 	if len(a.databases) == 0 {
 		log.WarnContext(ctx, "no databases registered")
 		return nil
@@ -120,29 +165,139 @@ func (a *Application) migrate(ctx context.Context) error {
 	return nil
 }
 
-func (a *Application) run(ctx context.Context) error {
-	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, os.Kill)
-	defer cancel()
+func (a *Application) closeDatabases(ctx context.Context) {
+	for dbName, db := range a.databases {
+		if err := db.Close(); err != nil {
+			log.ErrorContext(ctx, "error closing database", "error", err, "database", dbName)
+		}
+	}
+}
 
-	log.InfoContext(ctx, "starting application", "startupTasks", len(a.startupTasks))
+// notifySignals returns a context that is cancelled on interrupt, unless signal handling is disabled.
+func (a *Application) notifySignals(ctx context.Context) (context.Context, context.CancelFunc) {
+	if a.withoutSignals {
+		return ctx, func() {}
+	}
 
-	for i, task := range a.startupTasks {
-		log.InfoContext(ctx, "running task", "task", task.config.Name, "index", i)
+	return signal.NotifyContext(ctx, os.Interrupt, os.Kill)
+}
+
+// autoMigrateDatabases migrates databases if the application was created with WithAutoMigrate.
+func (a *Application) autoMigrateDatabases(ctx context.Context) error {
+	if !a.autoMigrate {
+		return nil
+	}
 
+	log.InfoContext(ctx, "auto-migrating databases", "databases", len(a.databases))
+
+	err := a.migrate(ctx)
+	a.health.SetMigration(err)
+
+	return err
+}
+
+// runStartupTasks runs startup tasks in registration order and stops at the first failed task with AbortOnError.
+func (a *Application) runStartupTasks(ctx context.Context) error {
+	for i, task := range a.startupTasks {
 		taskCtx := context.WithValue(ctx, log.StartupTaskKey, task.config.Name)
 
-		err := task.runner.Run(taskCtx)
+		err := a.runStartupTask(taskCtx, i, task)
 		if err != nil {
 			log.ErrorContext(ctx, "error in startup task", "error", err, "task", task.config.Name)
 
 			if task.config.AbortOnError {
-				return &ErrStartupTaskFailed{err: err}
+				return &ErrStartupTaskFailed{Name: task.config.Name, Index: i, Err: err}
 			}
 		}
 	}
 
+	return nil
+}
+
+// runStartupTask runs a single startup task. Tasks with RunOnce are skipped if their database
+// recorded a successful run, and a successful run is recorded after they return.
+func (a *Application) runStartupTask(ctx context.Context, index int, task startupTask) error {
+	if !task.config.RunOnce {
+		log.InfoContext(ctx, "running task", "task", task.config.Name, "index", index)
+		return task.runner.Run(ctx)
+	}
+
+	if task.config.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidRunOnceTask)
+	}
+
+	db, ok := a.databases[task.config.Database]
+	if !ok {
+		return fmt.Errorf("%w: database %q is not registered", ErrInvalidRunOnceTask, task.config.Database)
+	}
+
+	completed, err := db.StartupTaskCompleted(ctx, task.config.Name)
+	if err != nil {
+		return fmt.Errorf("failed to check startup task: %w", err)
+	}
+
+	if completed {
+		log.InfoContext(ctx, "skipping completed run-once task", "task", task.config.Name, "index", index)
+		return nil
+	}
+
+	log.InfoContext(ctx, "running task", "task", task.config.Name, "index", index, "runOnce", true)
+
+	if err := task.runner.Run(ctx); err != nil {
+		return err
+	}
+
+	if err := db.MarkStartupTaskCompleted(ctx, task.config.Name); err != nil {
+		return fmt.Errorf("failed to record startup task: %w", err)
+	}
+
+	return nil
+}
+
+// runTasks runs startup tasks without starting services, for one-shot job binaries.
+func (a *Application) runTasks(ctx context.Context) error {
+	ctx, cancel := a.notifySignals(ctx)
+	defer cancel()
+	defer a.closeDatabases(context.WithoutCancel(ctx))
+
+	if err := a.autoMigrateDatabases(ctx); err != nil {
+		return err
+	}
+
+	log.InfoContext(ctx, "running startup tasks", "startupTasks", len(a.startupTasks))
+
+	return a.runStartupTasks(ctx)
+}
+
+func (a *Application) run(ctx context.Context) error {
+	ctx, cancel := a.notifySignals(ctx)
+	defer cancel()
+	defer a.closeDatabases(context.WithoutCancel(ctx))
+
+	if err := a.checkServiceDeps(); err != nil {
+		return err
+	}
+
+	if err := a.autoMigrateDatabases(ctx); err != nil {
+		return err
+	}
+
+	log.InfoContext(ctx, "starting application", "startupTasks", len(a.startupTasks))
+
+	if err := a.runStartupTasks(ctx); err != nil {
+		return err
+	}
+
+	a.health.StartApplication()
+
 	var wg sync.WaitGroup
 
+	// started channels are closed once a service reaches STARTED so that its dependents can start
+	started := make(map[string]chan struct{}, len(a.services))
+	for serviceName := range a.services {
+		started[serviceName] = make(chan struct{})
+	}
+
 	for serviceName, service := range a.services {
 		wg.Add(1)
 
@@ -156,8 +311,18 @@ func (a *Application) run(ctx context.Context) error {
 				}
 			}()
 
+			for _, dep := range a.serviceDeps[serviceName] {
+				select {
+				case <-started[dep]:
+				case <-ctx.Done():
+					log.InfoContext(ctx, "service not started due to shutdown", string(log.ServiceNameKey), serviceName, "waitingFor", dep)
+					return
+				}
+			}
+
 			log.InfoContext(ctx, "starting service", string(log.ServiceNameKey), serviceName)
 			a.health.StartService(serviceName)
+			close(started[serviceName])
 
 			err := service.Run(serviceCtx)
 			if err != nil {
@@ -167,33 +332,42 @@ func (a *Application) run(ctx context.Context) error {
 		}()
 	}
 
-	a.health.StartedAt = time.Now()
-
 	wg.Wait()
 
 	return nil
 }
 
 // Run parses CLI arguments and executes the appropriate command.
-// Supported commands: run (start services), migrate (run database migrations).
+// Supported commands: run (start services), migrate (run database migrations),
+// task (run startup tasks and exit, for one-shot jobs).
 // Returns nil on success, ErrUnknownCommand for unknown commands.
 func (a *Application) Run(ctx context.Context) error {
-	if ctx == nil {
-		ctx = context.Background()
-	}
-
 	args := os.Args
 	if len(args) < 2 {
 		a.printUsage()
 		return nil
 	}
 
-	command := args[1]
+	return a.RunCommand(ctx, args[1])
+}
+
+// RunCommand executes command as if it was passed on the command line, without reading os.Args.
+// It lets tests run a fully wired application in-process.
+func (a *Application) RunCommand(ctx context.Context, command string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
 	switch command {
 	case "run":
+		a.logStartupSummary(ctx, command)
 		return a.run(ctx)
 	case "migrate":
+		a.logStartupSummary(ctx, command)
 		return a.migrate(ctx)
+	case "task":
+		a.logStartupSummary(ctx, command)
+		return a.runTasks(ctx)
 	case "--help", "-h":
 		a.printUsage()
 		return nil
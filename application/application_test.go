@@ -0,0 +1,259 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/platforma-dev/platforma/application"
+)
+
+func TestRegisterServiceWithDeps(t *testing.T) {
+	t.Parallel()
+
+	t.Run("dependency starts first", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+
+		var dependencyStarted atomic.Bool
+		app.RegisterService("cache", application.RunnerFunc(func(_ context.Context) error {
+			return nil
+		}))
+		app.RegisterServiceWithDeps("api", application.RunnerFunc(func(ctx context.Context) error {
+			status := app.Health(ctx).Services["cache"].Status
+			dependencyStarted.Store(status == application.ServiceStatusStarted)
+			return nil
+		}), []string{"cache"})
+
+		err := app.RunCommand(context.Background(), "run")
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		if !dependencyStarted.Load() {
+			t.Fatalf("expected cache to be started before api")
+		}
+	})
+
+	t.Run("cycle is reported", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+
+		var ran atomic.Bool
+		service := application.RunnerFunc(func(_ context.Context) error {
+			ran.Store(true)
+			return nil
+		})
+		app.RegisterServiceWithDeps("a", service, []string{"b"})
+		app.RegisterServiceWithDeps("b", service, []string{"a"})
+
+		err := app.RunCommand(context.Background(), "run")
+		if !errors.Is(err, application.ErrServiceDependencyCycle) {
+			t.Fatalf("expected dependency cycle error, got: %v", err)
+		}
+
+		if err.Error() != "service dependency cycle: a -> b -> a" {
+			t.Fatalf("expected cycle path in error, got: %v", err)
+		}
+
+		if ran.Load() {
+			t.Fatalf("expected no service to run")
+		}
+	})
+
+	t.Run("unknown dependency is reported", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+		app.RegisterServiceWithDeps("api", application.RunnerFunc(func(_ context.Context) error {
+			return nil
+		}), []string{"missing"})
+
+		err := app.RunCommand(context.Background(), "run")
+		if !errors.Is(err, application.ErrUnknownServiceDependency) {
+			t.Fatalf("expected unknown dependency error, got: %v", err)
+		}
+	})
+}
+
+func TestRunCommand(t *testing.T) {
+	t.Parallel()
+
+	t.Run("run starts services", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+
+		var ran atomic.Bool
+		app.RegisterService("worker", application.RunnerFunc(func(_ context.Context) error {
+			ran.Store(true)
+			return nil
+		}))
+
+		err := app.RunCommand(context.Background(), "run")
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		if !ran.Load() {
+			t.Fatalf("expected service to run")
+		}
+	})
+
+	t.Run("migrate without databases", func(t *testing.T) {
+		t.Parallel()
+
+		err := application.New().RunCommand(context.Background(), "migrate")
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	})
+
+	t.Run("task runs startup tasks without services", func(t *testing.T) {
+		t.Parallel()
+
+		app := application.New()
+
+		var taskRan, serviceRan atomic.Bool
+		app.OnStartFunc(func(_ context.Context) error {
+			taskRan.Store(true)
+			return nil
+		}, application.StartupTaskConfig{Name: "job", AbortOnError: true})
+		app.OnStartFunc(func(_ context.Context) error { return errors.New("ignored") }, application.StartupTaskConfig{Name: "optional"})
+		app.RegisterService("worker", application.RunnerFunc(func(_ context.Context) error {
+			serviceRan.Store(true)
+			return nil
+		}))
+
+		err := app.RunCommand(context.Background(), "task")
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		if !taskRan.Load() || serviceRan.Load() {
+			t.Fatalf("expected only the startup task to run, task: %v, service: %v", taskRan.Load(), serviceRan.Load())
+		}
+	})
+
+	t.Run("task fails on aborting task", func(t *testing.T) {
+		t.Parallel()
+
+		errJob := errors.New("job failed")
+
+		app := application.New()
+		app.OnStartFunc(func(_ context.Context) error { return errJob }, application.StartupTaskConfig{Name: "job", AbortOnError: true})
+
+		err := app.RunCommand(context.Background(), "task")
+		if !errors.Is(err, errJob) {
+			t.Fatalf("expected job error, got: %v", err)
+		}
+
+		var taskErr *application.ErrStartupTaskFailed
+		if !errors.As(err, &taskErr) || taskErr.Name != "job" {
+			t.Fatalf("expected startup task error for job, got: %v", err)
+		}
+	})
+
+	t.Run("task fails on run-once task without database", func(t *testing.T) {
+		t.Parallel()
+
+		var ran atomic.Bool
+		app := application.New()
+		app.OnStartFunc(func(_ context.Context) error {
+			ran.Store(true)
+			return nil
+		}, application.StartupTaskConfig{Name: "backfill", AbortOnError: true, RunOnce: true, Database: "main"})
+
+		err := app.RunCommand(context.Background(), "task")
+		if !errors.Is(err, application.ErrInvalidRunOnceTask) {
+			t.Fatalf("expected invalid run-once task error, got: %v", err)
+		}
+
+		if ran.Load() {
+			t.Fatal("expected run-once task not to run without its database")
+		}
+	})
+
+	t.Run("unknown command", func(t *testing.T) {
+		t.Parallel()
+
+		err := application.New().RunCommand(context.Background(), "unknown")
+		if !errors.Is(err, application.ErrUnknownCommand) {
+			t.Fatalf("expected unknown command error, got: %v", err)
+		}
+	})
+}
+
+func TestWithoutSignalHandling(t *testing.T) {
+	t.Parallel()
+
+	app := application.New(application.WithoutSignalHandling())
+
+	started := make(chan struct{})
+	app.RegisterService("worker", application.RunnerFunc(func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- app.RunCommand(ctx, "run") }()
+
+	<-started
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected run to stop after context cancellation")
+	}
+}
+
+func TestStartupTaskFailed(t *testing.T) {
+	t.Parallel()
+
+	errSeed := errors.New("seed failed")
+
+	app := application.New()
+	app.OnStartFunc(func(_ context.Context) error { return nil }, application.StartupTaskConfig{Name: "warmup", AbortOnError: true})
+	app.OnStartFunc(func(_ context.Context) error { return errors.New("ignored") }, application.StartupTaskConfig{Name: "optional"})
+	app.OnStartFunc(func(_ context.Context) error { return errSeed }, application.StartupTaskConfig{Name: "seed", AbortOnError: true})
+
+	var serviceRan atomic.Bool
+	app.RegisterService("worker", application.RunnerFunc(func(_ context.Context) error {
+		serviceRan.Store(true)
+		return nil
+	}))
+
+	err := app.RunCommand(context.Background(), "run")
+
+	var taskErr *application.ErrStartupTaskFailed
+	if !errors.As(err, &taskErr) {
+		t.Fatalf("expected startup task error, got: %v", err)
+	}
+
+	if taskErr.Name != "seed" || taskErr.Index != 2 {
+		t.Fatalf("expected task seed at index 2, got %s at %d", taskErr.Name, taskErr.Index)
+	}
+
+	if !errors.Is(err, errSeed) {
+		t.Fatalf("expected task error to be wrapped, got: %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "seed") {
+		t.Fatalf("expected task name in error message, got: %v", err)
+	}
+
+	if serviceRan.Load() {
+		t.Fatal("expected services not to start after aborted startup")
+	}
+}
@@ -0,0 +1,176 @@
+//go:build linux
+
+package application_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/platforma-dev/platforma/application"
+	"github.com/platforma-dev/platforma/database"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+)
+
+//nolint:paralleltest // subtests share one database and would race creating the migrations table
+func TestAutoMigrate(t *testing.T) {
+	t.Parallel()
+
+	dbURL := startPostgres(t)
+
+	tests := []struct {
+		name        string
+		opts        []application.Option
+		table       string
+		wantTable   bool
+		wantMigrate bool
+	}{
+		{name: "enabled migrates before services start", opts: []application.Option{application.WithAutoMigrate()}, table: "auto_enabled", wantTable: true, wantMigrate: true},
+		{name: "disabled skips migrations", table: "auto_disabled", wantTable: false, wantMigrate: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := database.New(dbURL)
+			if err != nil {
+				t.Fatalf("failed to initialize database: %s", err.Error())
+			}
+
+			app := application.New(tt.opts...)
+			app.RegisterDatabase("main", db)
+			app.RegisterRepository("main", tt.table, migrationRepo{fsys: fstest.MapFS{
+				"001_init.sql": &fstest.MapFile{Data: []byte("-- +migrate Up\nCREATE TABLE " + tt.table + " (id INT);")},
+			}})
+
+			var tableExists bool
+			app.RegisterService("checker", application.RunnerFunc(func(ctx context.Context) error {
+				return db.Connection().GetContext(ctx, &tableExists, "SELECT to_regclass($1) IS NOT NULL", tt.table)
+			}))
+
+			err = app.RunCommand(context.Background(), "run")
+			if err != nil {
+				t.Fatalf("expected no error, got: %s", err.Error())
+			}
+
+			if tableExists != tt.wantTable {
+				t.Fatalf("expected table %s to exist when service starts: %v, got: %v", tt.table, tt.wantTable, tableExists)
+			}
+
+			migration := app.Health(context.Background()).Migration
+			if !tt.wantMigrate {
+				if migration != nil {
+					t.Fatalf("expected no migration in health, got: %+v", migration)
+				}
+				return
+			}
+
+			if migration == nil || migration.Status != application.MigrationStatusMigrated {
+				t.Fatalf("expected migration status %s in health, got: %+v", application.MigrationStatusMigrated, migration)
+			}
+		})
+	}
+}
+
+func TestConflictingRepository(t *testing.T) {
+	t.Parallel()
+
+	dbURL := startPostgres(t)
+
+	app := application.New()
+	for _, dbName := range []string{"main", "replica"} {
+		db, err := database.New(dbURL)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+		t.Cleanup(func() { _ = db.Close() })
+
+		app.RegisterDatabase(dbName, db)
+		app.RegisterRepository(dbName, "users", migrationRepo{fsys: fstest.MapFS{
+			"001_init.sql": &fstest.MapFile{Data: []byte("-- +migrate Up\nCREATE TABLE users (id INT);")},
+		}})
+	}
+
+	err := app.RunCommand(context.Background(), "migrate")
+	if !errors.Is(err, application.ErrConflictingRepository) {
+		t.Fatalf("expected conflicting repository error, got: %v", err)
+	}
+
+	if err.Error() != "repository with migrations is registered in multiple databases: users in main, replica" {
+		t.Fatalf("expected repository and databases in error, got: %s", err.Error())
+	}
+}
+
+func TestRunOnceStartupTask(t *testing.T) {
+	t.Parallel()
+
+	dbURL := startPostgres(t)
+
+	runs := 0
+	boot := func() {
+		t.Helper()
+
+		db, err := database.New(dbURL)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+
+		app := application.New()
+		app.RegisterDatabase("main", db)
+		app.OnStartFunc(func(_ context.Context) error {
+			runs++
+			return nil
+		}, application.StartupTaskConfig{Name: "backfill", AbortOnError: true, RunOnce: true, Database: "main"})
+
+		if err := app.RunCommand(context.Background(), "task"); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+	}
+
+	boot()
+	if runs != 1 {
+		t.Fatalf("expected run-once task to run on first boot, got %d runs", runs)
+	}
+
+	boot()
+	if runs != 1 {
+		t.Fatalf("expected run-once task to be skipped on second boot, got %d runs", runs)
+	}
+}
+
+type migrationRepo struct {
+	fsys fs.FS
+}
+
+func (r migrationRepo) Migrations() fs.FS {
+	return r.fsys
+}
+
+func startPostgres(t *testing.T) string {
+	t.Helper()
+
+	ctx := context.Background()
+	ctr, err := postgres.Run(
+		ctx,
+		"postgres:18-alpine",
+		postgres.WithDatabase("platforma"),
+		postgres.WithUsername("platforma"),
+		postgres.WithPassword("platforma"),
+		postgres.BasicWaitStrategies(),
+	)
+	if err != nil {
+		t.Fatalf("failed to initialize database: %s", err.Error())
+	}
+
+	t.Cleanup(func() {
+		_ = ctr.Terminate(ctx)
+	})
+
+	dbURL, err := ctr.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		t.Fatalf("failed to get connection string: %s", err.Error())
+	}
+
+	return dbURL
+}
@@ -0,0 +1,162 @@
+package application
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/platforma-dev/platforma/log"
+)
+
+const (
+	debugPprofPrefix        = "/debug/pprof/"
+	defaultCPUProfileLength = 30 * time.Second
+	defaultTraceLength      = time.Second
+)
+
+// DebugHandler serves runtime profiles under /debug/pprof/ and the application description
+// at /debug/info to authorized requests.
+//
+// Profiles are written with runtime/pprof rather than net/http/pprof, whose import registers
+// unauthenticated handlers on http.DefaultServeMux.
+type DebugHandler struct {
+	authz func(*http.Request) bool
+	mux   *http.ServeMux
+}
+
+// NewDebugHandler creates a DebugHandler for app that serves profiles only when authz returns true
+// and responds with 403 Forbidden otherwise. A nil authz rejects every request.
+// Mount it at /debug/ without stripping the prefix, as profile names are derived from the path.
+func NewDebugHandler(app describer, authz func(*http.Request) bool) *DebugHandler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /debug/info", NewInfoHandler(app))
+	mux.HandleFunc("GET /debug/pprof/{$}", servePprofIndex)
+	mux.HandleFunc("GET /debug/pprof/cmdline", servePprofCmdline)
+	mux.HandleFunc("GET /debug/pprof/profile", servePprofCPU)
+	mux.HandleFunc("GET /debug/pprof/trace", servePprofTrace)
+	mux.HandleFunc("GET /debug/pprof/{name}", servePprofProfile)
+
+	return &DebugHandler{authz: authz, mux: mux}
+}
+
+func (h *DebugHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.authz == nil || !h.authz(r) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	h.mux.ServeHTTP(w, r)
+}
+
+// servePprofIndex lists the available profiles.
+func servePprofIndex(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	var b strings.Builder
+	b.WriteString("<html><body><ul>\n")
+	for _, p := range pprof.Profiles() {
+		name := html.EscapeString(p.Name())
+		fmt.Fprintf(&b, "<li><a href=\"%s?debug=1\">%s</a> (%d)</li>\n", name, name, p.Count())
+	}
+	b.WriteString("<li><a href=\"profile\">profile</a> (CPU)</li>\n")
+	b.WriteString("<li><a href=\"trace\">trace</a></li>\n")
+	b.WriteString("</ul></body></html>\n")
+
+	if _, err := w.Write([]byte(b.String())); err != nil {
+		log.ErrorContext(r.Context(), "failed to write pprof index", "error", err)
+	}
+}
+
+// servePprofCmdline writes the command line arguments separated by NUL bytes.
+func servePprofCmdline(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	if _, err := w.Write([]byte(strings.Join(os.Args, "\x00"))); err != nil {
+		log.ErrorContext(r.Context(), "failed to write cmdline", "error", err)
+	}
+}
+
+// servePprofProfile writes a named profile such as heap or goroutine. The debug query
+// parameter selects the text format, and gc=1 runs a garbage collection before a heap profile.
+func servePprofProfile(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	profile := pprof.Lookup(name)
+	if profile == nil {
+		http.Error(w, "unknown profile: "+name, http.StatusNotFound)
+		return
+	}
+
+	debug, _ := strconv.Atoi(r.URL.Query().Get("debug"))
+	if name == "heap" && r.URL.Query().Get("gc") != "" {
+		runtime.GC()
+	}
+
+	if debug > 0 {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+	}
+
+	if err := profile.WriteTo(w, debug); err != nil {
+		log.ErrorContext(r.Context(), "failed to write profile", "profile", name, "error", err)
+	}
+}
+
+// servePprofCPU records a CPU profile for the number of seconds in the seconds query parameter.
+func servePprofCPU(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="profile"`)
+
+	if err := pprof.StartCPUProfile(w); err != nil {
+		w.Header().Del("Content-Disposition")
+		http.Error(w, "failed to start CPU profile: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer pprof.StopCPUProfile()
+
+	sleep(r, durationParam(r, defaultCPUProfileLength))
+}
+
+// servePprofTrace records an execution trace for the number of seconds in the seconds query parameter.
+func servePprofTrace(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="trace"`)
+
+	if err := trace.Start(w); err != nil {
+		w.Header().Del("Content-Disposition")
+		http.Error(w, "failed to start trace: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer trace.Stop()
+
+	sleep(r, durationParam(r, defaultTraceLength))
+}
+
+func durationParam(r *http.Request, fallback time.Duration) time.Duration {
+	seconds, err := strconv.ParseFloat(r.URL.Query().Get("seconds"), 64)
+	if err != nil || seconds <= 0 {
+		return fallback
+	}
+
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// sleep waits for d or until the request is canceled.
+func sleep(r *http.Request, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-r.Context().Done():
+	}
+}
@@ -0,0 +1,76 @@
+package application_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/platforma-dev/platforma/application"
+)
+
+func TestDebugHandler(t *testing.T) {
+	t.Parallel()
+
+	authz := func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "Bearer admin"
+	}
+
+	tests := []struct {
+		name       string
+		authz      func(*http.Request) bool
+		path       string
+		header     string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "index when authorized", authz: authz, path: "/debug/pprof/", header: "Bearer admin", wantStatus: http.StatusOK, wantBody: "goroutine"},
+		{name: "profile when authorized", authz: authz, path: "/debug/pprof/goroutine?debug=1", header: "Bearer admin", wantStatus: http.StatusOK, wantBody: "goroutine profile"},
+		{name: "cmdline when authorized", authz: authz, path: "/debug/pprof/cmdline", header: "Bearer admin", wantStatus: http.StatusOK},
+		{name: "cpu profile when authorized", authz: authz, path: "/debug/pprof/profile?seconds=0.01", header: "Bearer admin", wantStatus: http.StatusOK},
+		{name: "unknown profile", authz: authz, path: "/debug/pprof/unknown", header: "Bearer admin", wantStatus: http.StatusNotFound},
+		{name: "info when authorized", authz: authz, path: "/debug/info", header: "Bearer admin", wantStatus: http.StatusOK, wantBody: `"worker"`},
+		{name: "info when unauthorized", authz: authz, path: "/debug/info", wantStatus: http.StatusForbidden},
+		{name: "index when unauthorized", authz: authz, path: "/debug/pprof/", header: "Bearer user", wantStatus: http.StatusForbidden},
+		{name: "profile when unauthorized", authz: authz, path: "/debug/pprof/heap", wantStatus: http.StatusForbidden},
+		{name: "nil authz rejects", path: "/debug/pprof/", header: "Bearer admin", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := application.New()
+			app.RegisterService("worker", application.RunnerFunc(func(_ context.Context) error { return nil }))
+			handler := application.NewDebugHandler(app, tt.authz)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Fatalf("expected body to contain %q, got %q", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestDebugHandlerLeavesDefaultServeMuxAlone(t *testing.T) {
+	t.Parallel()
+
+	_ = application.NewDebugHandler(application.New(), func(*http.Request) bool { return true })
+
+	_, pattern := http.DefaultServeMux.Handler(httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+	if pattern != "" {
+		t.Fatalf("expected no handler on http.DefaultServeMux, got pattern %q", pattern)
+	}
+}
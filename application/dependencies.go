@@ -0,0 +1,74 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrServiceDependencyCycle is returned when service dependencies form a cycle.
+var ErrServiceDependencyCycle = errors.New("service dependency cycle")
+
+// ErrUnknownServiceDependency is returned when a service depends on a service that is not registered.
+var ErrUnknownServiceDependency = errors.New("unknown service dependency")
+
+// RegisterServiceWithDeps adds a named service that is started only after all services
+// listed in deps have reached the STARTED status.
+func (a *Application) RegisterServiceWithDeps(serviceName string, service Runner, deps []string) {
+	a.RegisterService(serviceName, service)
+	a.serviceDeps[serviceName] = slices.Clone(deps)
+}
+
+// checkServiceDeps verifies that all dependencies are registered and do not form a cycle.
+func (a *Application) checkServiceDeps() error {
+	names := make([]string, 0, len(a.serviceDeps))
+	for name := range a.serviceDeps {
+		names = append(names, name)
+	}
+	// Sorted to report the same cycle on every run
+	slices.Sort(names)
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[string]int, len(a.services))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			start := slices.Index(path, name)
+			cycle := append(slices.Clone(path[start:]), name)
+			return fmt.Errorf("%w: %s", ErrServiceDependencyCycle, strings.Join(cycle, " -> "))
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+
+		for _, dep := range a.serviceDeps[name] {
+			if _, ok := a.services[dep]; !ok {
+				return fmt.Errorf("%w: service %q depends on %q", ErrUnknownServiceDependency, name, dep)
+			}
+
+			if err := visit(dep, path); err != nil {
+				return err
+			}
+		}
+
+		state[name] = visited
+
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
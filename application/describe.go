@@ -0,0 +1,70 @@
+package application
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/platforma-dev/platforma/httpserver"
+	"github.com/platforma-dev/platforma/log"
+)
+
+// Description is a machine-readable description of an application.
+type Description struct {
+	Services     []ServiceDescription `json:"services"`
+	Databases    []string             `json:"databases"`
+	StartupTasks []string             `json:"startupTasks"`
+}
+
+// ServiceDescription describes a registered service.
+type ServiceDescription struct {
+	Name         string   `json:"name"`
+	Dependencies []string `json:"dependencies,omitempty"`
+	// Routes are the HTTP routes of services that list them, such as httpserver.HTTPServer.
+	Routes []string `json:"routes,omitempty"`
+}
+
+type router interface {
+	Routes() []string
+}
+
+// Describe returns the registered services with their dependencies and HTTP routes,
+// databases and startup tasks. Services and databases are sorted by name.
+func (a *Application) Describe() Description {
+	summary := a.StartupSummary("")
+
+	services := make([]ServiceDescription, 0, len(summary.Services))
+	for _, name := range slices.Sorted(maps.Keys(a.services)) {
+		service := ServiceDescription{Name: name, Dependencies: slices.Clone(a.serviceDeps[name])}
+		if r, ok := a.services[name].(router); ok {
+			service.Routes = r.Routes()
+		}
+		services = append(services, service)
+	}
+
+	return Description{
+		Services:     services,
+		Databases:    summary.Databases,
+		StartupTasks: summary.StartupTasks,
+	}
+}
+
+type describer interface {
+	Describe() Description
+}
+
+// InfoHandler serves the application description as JSON, e.g. at /debug/info.
+type InfoHandler struct {
+	app describer
+}
+
+// NewInfoHandler creates an InfoHandler for the given application.
+func NewInfoHandler(app describer) *InfoHandler {
+	return &InfoHandler{app: app}
+}
+
+func (h *InfoHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := httpserver.WriteJSON(w, http.StatusOK, h.app.Describe()); err != nil {
+		log.ErrorContext(r.Context(), "failed to write info response", "error", err)
+	}
+}
@@ -0,0 +1,79 @@
+package application_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/platforma-dev/platforma/application"
+	"github.com/platforma-dev/platforma/database"
+	"github.com/platforma-dev/platforma/httpserver"
+)
+
+func TestDescribe(t *testing.T) {
+	t.Parallel()
+
+	app := application.New()
+
+	server := httpserver.New("8080", 0)
+	server.HandleFunc("GET /users", func(_ http.ResponseWriter, _ *http.Request) {})
+
+	noop := application.RunnerFunc(func(_ context.Context) error { return nil })
+	app.RegisterService("worker", noop)
+	app.RegisterServiceWithDeps("api", server, []string{"worker"})
+	app.RegisterDatabase("main", &database.Database{})
+
+	description := app.Describe()
+
+	names := make([]string, 0, len(description.Services))
+	for _, service := range description.Services {
+		names = append(names, service.Name)
+	}
+
+	if !slices.Equal(names, []string{"api", "worker"}) {
+		t.Fatalf("expected services [api worker], got %v", names)
+	}
+
+	api := description.Services[0]
+	if !slices.Equal(api.Dependencies, []string{"worker"}) {
+		t.Errorf("expected api dependencies [worker], got %v", api.Dependencies)
+	}
+
+	if !slices.Equal(api.Routes, []string{"GET /users"}) {
+		t.Errorf("expected api routes [GET /users], got %v", api.Routes)
+	}
+
+	if description.Services[1].Routes != nil {
+		t.Errorf("expected no routes for worker, got %v", description.Services[1].Routes)
+	}
+
+	if !slices.Equal(description.Databases, []string{"main"}) {
+		t.Errorf("expected databases [main], got %v", description.Databases)
+	}
+}
+
+func TestInfoHandler(t *testing.T) {
+	t.Parallel()
+
+	app := application.New()
+	app.RegisterService("worker", application.RunnerFunc(func(_ context.Context) error { return nil }))
+
+	w := httptest.NewRecorder()
+	application.NewInfoHandler(app).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/info", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var description application.Description
+	if err := json.NewDecoder(w.Body).Decode(&description); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(description.Services) != 1 || description.Services[0].Name != "worker" {
+		t.Fatalf("expected worker service in description, got %+v", description.Services)
+	}
+}
@@ -2,6 +2,8 @@ package application
 
 import (
 	"encoding/json"
+	"fmt"
+	"sync"
 	"time"
 )
 
@@ -26,10 +28,46 @@ type ServiceHealth struct {
 	Data      any           `json:"data,omitempty"`
 }
 
+// MigrationStatus represents the result of migrations run on startup.
+type MigrationStatus string
+
+const (
+	// MigrationStatusMigrated indicates all databases were migrated successfully.
+	MigrationStatusMigrated MigrationStatus = "MIGRATED"
+	// MigrationStatusError indicates migration of a database failed.
+	MigrationStatusError MigrationStatus = "ERROR"
+)
+
+// MigrationHealth contains the result of migrations run on startup.
+type MigrationHealth struct {
+	Status     MigrationStatus `json:"status"`
+	FinishedAt time.Time       `json:"finishedAt"`
+	Error      string          `json:"error,omitempty"`
+}
+
+// HealthStatus represents the aggregated state of the application.
+type HealthStatus string
+
+const (
+	// HealthStatusOK indicates all services are running.
+	HealthStatusOK HealthStatus = "OK"
+	// HealthStatusStarting indicates some services have not started yet.
+	HealthStatusStarting HealthStatus = "STARTING"
+	// HealthStatusError indicates a service or the startup migration failed.
+	HealthStatusError HealthStatus = "ERROR"
+)
+
 // Health contains overall application health and service states.
+// Its methods are safe for concurrent use, e.g. by services starting while a health endpoint is served.
 type Health struct {
-	StartedAt time.Time                 `json:"startedAt"`
-	Services  map[string]*ServiceHealth `json:"services"`
+	mu sync.Mutex
+
+	// OverallStatus is the aggregated status of services and migration, see Status.
+	// It is updated by Application.Health.
+	OverallStatus HealthStatus              `json:"overallStatus"`
+	StartedAt     time.Time                 `json:"startedAt"`
+	Migration     *MigrationHealth          `json:"migration,omitempty"`
+	Services      map[string]*ServiceHealth `json:"services"`
 }
 
 // NewHealth creates an ApplicationHealth with initialized storage.
@@ -39,6 +77,9 @@ func NewHealth() *Health {
 
 // StartService marks the given service as started and stores start time.
 func (h *Health) StartService(serviceName string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
 	if service, ok := h.Services[serviceName]; ok {
 		service.Status = ServiceStatusStarted
 
@@ -51,6 +92,9 @@ func (h *Health) StartService(serviceName string) {
 
 // FailService marks the given service as failed and stores the error.
 func (h *Health) FailService(serviceName string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
 	if service, ok := h.Services[serviceName]; ok {
 		service.Status = ServiceStatusError
 
@@ -63,14 +107,83 @@ func (h *Health) FailService(serviceName string, err error) {
 	}
 }
 
+// SetMigration stores the result of migrations run on startup.
+func (h *Health) SetMigration(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	migration := &MigrationHealth{Status: MigrationStatusMigrated, FinishedAt: time.Now()}
+	if err != nil {
+		migration.Status = MigrationStatusError
+		migration.Error = err.Error()
+	}
+
+	h.Migration = migration
+}
+
+// Status aggregates service and migration states: HealthStatusError if any service failed or
+// the startup migration failed, HealthStatusStarting if any service has not started yet,
+// and HealthStatusOK otherwise.
+func (h *Health) Status() HealthStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.status()
+}
+
+// updateOverallStatus sets OverallStatus to the current Status.
+func (h *Health) updateOverallStatus() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.OverallStatus = h.status()
+}
+
+func (h *Health) status() HealthStatus {
+	if h.Migration != nil && h.Migration.Status == MigrationStatusError {
+		return HealthStatusError
+	}
+
+	status := HealthStatusOK
+	for _, service := range h.Services {
+		switch service.Status {
+		case ServiceStatusError:
+			return HealthStatusError
+		case ServiceStatusNotStarted:
+			status = HealthStatusStarting
+		case ServiceStatusStarted:
+		}
+	}
+
+	return status
+}
+
 // SetServiceData stores additional health payload for the given service.
 func (h *Health) SetServiceData(serviceName string, data any) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
 	if service, ok := h.Services[serviceName]; ok {
 		service.Data = data
 		h.Services[serviceName] = service
 	}
 }
 
+// MarshalJSON encodes the health while holding its lock, so that it is not changed during encoding.
+func (h *Health) MarshalJSON() ([]byte, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	type health Health
+
+	b, err := json.Marshal((*health)(h))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal health: %w", err)
+	}
+
+	return b, nil
+}
+
 func (h *Health) String() string {
 	b, _ := json.Marshal(h)
 	return string(b)
@@ -78,5 +191,8 @@ func (h *Health) String() string {
 
 // StartApplication marks application start time.
 func (h *Health) StartApplication() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
 	h.StartedAt = time.Now()
 }
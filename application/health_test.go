@@ -0,0 +1,66 @@
+package application_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/platforma-dev/platforma/application"
+)
+
+func TestHealthStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		services map[string]application.ServiceStatus
+		failed   bool
+		want     application.HealthStatus
+	}{
+		{name: "no services", want: application.HealthStatusOK},
+		{name: "all started", services: map[string]application.ServiceStatus{"api": application.ServiceStatusStarted, "queue": application.ServiceStatusStarted}, want: application.HealthStatusOK},
+		{name: "some not started", services: map[string]application.ServiceStatus{"api": application.ServiceStatusStarted, "queue": application.ServiceStatusNotStarted}, want: application.HealthStatusStarting},
+		{name: "error wins over not started", services: map[string]application.ServiceStatus{"api": application.ServiceStatusError, "queue": application.ServiceStatusNotStarted}, want: application.HealthStatusError},
+		{name: "failed migration", services: map[string]application.ServiceStatus{"api": application.ServiceStatusStarted}, failed: true, want: application.HealthStatusError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			health := application.NewHealth()
+			for name, status := range tt.services {
+				health.Services[name] = &application.ServiceHealth{Status: status}
+			}
+			if tt.failed {
+				health.SetMigration(errors.New("migration failed"))
+			}
+
+			if status := health.Status(); status != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, status)
+			}
+		})
+	}
+}
+
+func TestApplicationHealthOverallStatus(t *testing.T) {
+	t.Parallel()
+
+	app := application.New()
+	app.RegisterService("worker", application.RunnerFunc(func(_ context.Context) error { return nil }))
+
+	health := app.Health(context.Background())
+	if health.OverallStatus != application.HealthStatusStarting {
+		t.Fatalf("expected STARTING before run, got %s", health.OverallStatus)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal([]byte(health.String()), &decoded); err != nil {
+		t.Fatalf("failed to decode health: %v", err)
+	}
+
+	if decoded["overallStatus"] != string(application.HealthStatusStarting) {
+		t.Fatalf("expected overallStatus in JSON, got %v", decoded)
+	}
+}
@@ -0,0 +1,112 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/platforma-dev/platforma/httpserver"
+	"github.com/platforma-dev/platforma/log"
+)
+
+// HealthServerName is the service name of the server registered by EnableHealthServer.
+const HealthServerName = "healthServer"
+
+const healthServerShutdownTimeout = 5 * time.Second
+
+// EnableHealthServer registers a service that serves health endpoints on addr, e.g. ":9090",
+// separately from the application's own HTTP servers:
+//   - /healthz responds 200 OK while the application is running, for liveness probes;
+//   - /readyz responds like ReadinessHandler, for readiness probes;
+//   - /health serves the application health as JSON like HealthCheckHandler.
+//
+// The server stops gracefully when the application shuts down.
+func (a *Application) EnableHealthServer(addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.Handle("GET /readyz", NewReadinessHandler(a))
+	mux.Handle("GET /health", NewHealthCheckHandler(a))
+
+	a.RegisterService(HealthServerName, &healthServer{addr: addr, handler: mux})
+}
+
+// ReadinessHandler serves the overall application health status as JSON, responding with
+// 200 OK when it is HealthStatusOK and 503 Service Unavailable otherwise.
+type ReadinessHandler struct {
+	app healther
+}
+
+// NewReadinessHandler creates a ReadinessHandler for the given application.
+func NewReadinessHandler(app healther) *ReadinessHandler {
+	return &ReadinessHandler{app: app}
+}
+
+func (h *ReadinessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	status := h.app.Health(r.Context()).OverallStatus
+
+	code := http.StatusOK
+	if status != HealthStatusOK {
+		code = http.StatusServiceUnavailable
+	}
+
+	if err := httpserver.WriteJSON(w, code, map[string]HealthStatus{"status": status}); err != nil {
+		log.ErrorContext(r.Context(), "failed to write readiness response", "error", err)
+	}
+}
+
+// healthServer serves health endpoints until its context is cancelled.
+type healthServer struct {
+	addr    string
+	handler http.Handler
+}
+
+func (s *healthServer) Run(ctx context.Context) error {
+	// listen before serving, so that e.g. a port in use fails the service
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for health server: %w", err)
+	}
+
+	server := &http.Server{
+		Handler:           s.handler,
+		ReadHeaderTimeout: 1 * time.Second,
+		BaseContext:       func(_ net.Listener) context.Context { return ctx },
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.InfoContext(ctx, "starting health server", "address", listener.Addr().String())
+		serveErr <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("health server error: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), healthServerShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to gracefully shutdown health server: %w", err)
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("health server error: %w", err)
+	}
+
+	return nil
+}
+
+// Healthcheck returns health check information for the health server.
+func (s *healthServer) Healthcheck(_ context.Context) any {
+	return map[string]any{
+		"address": s.addr,
+	}
+}
@@ -0,0 +1,154 @@
+package application_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/platforma-dev/platforma/application"
+)
+
+func TestHealthServer(t *testing.T) {
+	t.Parallel()
+
+	addr := freeAddr(t)
+
+	app := application.New(application.WithoutSignalHandling())
+	app.EnableHealthServer(addr)
+
+	started := make(chan struct{})
+	app.RegisterService("worker", application.RunnerFunc(func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- app.RunCommand(ctx, "run") }()
+
+	<-started
+
+	// the health server starts listening concurrently with the worker
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := http.Get("http://" + addr + "/healthz")
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("expected /healthz status 200, got %d", resp.StatusCode)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected health server to listen on %s, got: %v", addr, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	var ready map[string]string
+	if code := getJSON(t, "http://"+addr+"/readyz", &ready); code != http.StatusOK || ready["status"] != string(application.HealthStatusOK) {
+		t.Fatalf("expected /readyz 200 with status OK, got %d %v", code, ready)
+	}
+
+	var health application.Health
+	if code := getJSON(t, "http://"+addr+"/health", &health); code != http.StatusOK {
+		t.Fatalf("expected /health status 200, got %d", code)
+	}
+
+	if health.OverallStatus != application.HealthStatusOK || health.Services[application.HealthServerName] == nil || health.Services["worker"] == nil {
+		t.Fatalf("expected health of worker and health server, got %s", health.String())
+	}
+
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected run to stop after context cancellation")
+	}
+
+	if _, err := http.Get("http://" + addr + "/healthz"); err == nil {
+		t.Fatal("expected health server to be shut down")
+	}
+}
+
+func TestReadinessHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		status   application.HealthStatus
+		wantCode int
+	}{
+		{status: application.HealthStatusOK, wantCode: http.StatusOK},
+		{status: application.HealthStatusStarting, wantCode: http.StatusServiceUnavailable},
+		{status: application.HealthStatusError, wantCode: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			t.Parallel()
+
+			handler := application.NewReadinessHandler(staticHealth{status: tt.status})
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("expected status %d, got %d", tt.wantCode, w.Code)
+			}
+
+			var body map[string]string
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body["status"] != string(tt.status) {
+				t.Fatalf("expected status %s in body, got %s", tt.status, w.Body.String())
+			}
+		})
+	}
+}
+
+type staticHealth struct {
+	status application.HealthStatus
+}
+
+func (h staticHealth) Health(context.Context) *application.Health {
+	health := application.NewHealth()
+	health.OverallStatus = h.status
+
+	return health
+}
+
+// freeAddr returns a local address with a port that was free when it was checked.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find free port: %v", err)
+	}
+	defer listener.Close()
+
+	return listener.Addr().String()
+}
+
+func getJSON(t *testing.T, url string, dest any) int {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(dest); err != nil {
+		t.Fatalf("failed to decode response of %s: %v", url, err)
+	}
+
+	return resp.StatusCode
+}
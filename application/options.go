@@ -0,0 +1,22 @@
+package application
+
+// Option configures an Application.
+type Option func(*Application)
+
+// WithAutoMigrate makes the run and task commands migrate all registered databases before
+// startup tasks and services are run. It is disabled by default so that migrations
+// are not applied accidentally, e.g. in production; use the migrate command there.
+func WithAutoMigrate() Option {
+	return func(a *Application) {
+		a.autoMigrate = true
+	}
+}
+
+// WithoutSignalHandling disables the interrupt and kill signal handling of the run and task commands,
+// so that an application embedded in a host that manages signals is only stopped by
+// cancelling the context passed to Run or RunCommand.
+func WithoutSignalHandling() Option {
+	return func(a *Application) {
+		a.withoutSignals = true
+	}
+}
@@ -1,26 +1,41 @@
 package application
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
-// ErrStartupTaskFailed represents an error that occurs when a startup task fails.
+// ErrInvalidRunOnceTask is returned when a startup task with RunOnce has no name or no registered database.
+var ErrInvalidRunOnceTask = errors.New("invalid run-once startup task")
+
+// ErrStartupTaskFailed is returned when a startup task with AbortOnError fails.
 type ErrStartupTaskFailed struct {
-	err error
+	// Name is the name of the startup task from StartupTaskConfig.
+	Name string
+	// Index is the position of the task in registration order, starting at 0.
+	Index int
+	Err   error
 }
 
 // Error returns the formatted error message for ErrStartupTaskFailed.
 func (e *ErrStartupTaskFailed) Error() string {
-	return fmt.Sprintf("failed to run startup task: %v", e.err)
+	return fmt.Sprintf("failed to run startup task %s (#%d): %v", e.Name, e.Index, e.Err)
 }
 
 // Unwrap returns the underlying error for ErrStartupTaskFailed.
 func (e *ErrStartupTaskFailed) Unwrap() error {
-	return e.err
+	return e.Err
 }
 
 // StartupTaskConfig contains configuration options for a startup task.
 type StartupTaskConfig struct {
 	Name         string // Name of the startup task
 	AbortOnError bool   // Whether to abort application startup if this task fails
+	// RunOnce skips the task on later boots once a successful run is recorded, e.g. for data backfills.
+	// Runs are recorded by Name in the platforma_startup_tasks table of Database.
+	RunOnce bool
+	// Database is the name of the registered database that records runs of a RunOnce task.
+	Database string
 }
 
 // startupTask represents an individual startup task with its runner and configuration.
@@ -0,0 +1,48 @@
+package application
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/platforma-dev/platforma/log"
+)
+
+// StartupSummary describes what an application starts with.
+type StartupSummary struct {
+	Command      string   `json:"command"`
+	Services     []string `json:"services"`
+	Databases    []string `json:"databases"`
+	StartupTasks []string `json:"startupTasks"`
+}
+
+// StartupSummary returns the registered services, databases and startup tasks for command.
+// Services and databases are sorted by name, startup tasks keep their registration order.
+func (a *Application) StartupSummary(command string) StartupSummary {
+	tasks := make([]string, 0, len(a.startupTasks))
+	for _, task := range a.startupTasks {
+		tasks = append(tasks, task.config.Name)
+	}
+
+	return StartupSummary{
+		Command:      command,
+		Services:     slices.Sorted(maps.Keys(a.services)),
+		Databases:    slices.Sorted(maps.Keys(a.databases)),
+		StartupTasks: tasks,
+	}
+}
+
+// logStartupSummary writes the startup summary as a single structured log record.
+func (a *Application) logStartupSummary(ctx context.Context, command string) {
+	summary := a.StartupSummary(command)
+
+	log.InfoContext(ctx, "startup summary",
+		"command", summary.Command,
+		"services", summary.Services,
+		"serviceCount", len(summary.Services),
+		"databases", summary.Databases,
+		"databaseCount", len(summary.Databases),
+		"startupTasks", summary.StartupTasks,
+		"startupTaskCount", len(summary.StartupTasks),
+	)
+}
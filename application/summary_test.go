@@ -0,0 +1,51 @@
+package application_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/platforma-dev/platforma/application"
+	"github.com/platforma-dev/platforma/database"
+)
+
+func TestStartupSummary(t *testing.T) {
+	t.Parallel()
+
+	app := application.New()
+
+	noop := application.RunnerFunc(func(_ context.Context) error { return nil })
+	app.RegisterService("worker", noop)
+	app.RegisterService("api", noop)
+	app.RegisterDatabase("main", &database.Database{})
+	app.OnStartFunc(func(_ context.Context) error { return nil }, application.StartupTaskConfig{Name: "seed"})
+	app.OnStartFunc(func(_ context.Context) error { return nil }, application.StartupTaskConfig{Name: "warmup"})
+
+	summary := app.StartupSummary("run")
+
+	if summary.Command != "run" {
+		t.Errorf("expected command %q, got %q", "run", summary.Command)
+	}
+
+	if !slices.Equal(summary.Services, []string{"api", "worker"}) {
+		t.Errorf("expected services [api worker], got %v", summary.Services)
+	}
+
+	if !slices.Equal(summary.Databases, []string{"main"}) {
+		t.Errorf("expected databases [main], got %v", summary.Databases)
+	}
+
+	if !slices.Equal(summary.StartupTasks, []string{"seed", "warmup"}) {
+		t.Errorf("expected startup tasks [seed warmup], got %v", summary.StartupTasks)
+	}
+}
+
+func TestStartupSummaryEmpty(t *testing.T) {
+	t.Parallel()
+
+	summary := application.New().StartupSummary("migrate")
+
+	if len(summary.Services) != 0 || len(summary.Databases) != 0 || len(summary.StartupTasks) != 0 {
+		t.Errorf("expected empty summary, got %+v", summary)
+	}
+}
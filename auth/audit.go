@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+// AuditAction identifies a security-relevant action recorded in the audit trail.
+type AuditAction string
+
+const (
+	AuditActionLogin          AuditAction = "login"
+	AuditActionLogout         AuditAction = "logout"
+	AuditActionChangePassword AuditAction = "changePassword"
+)
+
+// AuditOutcome is the result of an audited action.
+type AuditOutcome string
+
+const (
+	AuditOutcomeSuccess AuditOutcome = "success"
+	AuditOutcomeFailure AuditOutcome = "failure"
+)
+
+// AuditEvent describes a single security-relevant action.
+type AuditEvent struct {
+	Action  AuditAction  `json:"action"`
+	Outcome AuditOutcome `json:"outcome"`
+	UserID  string       `json:"userId,omitempty"`
+	IP      string       `json:"ip,omitempty"`
+	Reason  string       `json:"reason,omitempty"`
+	Time    time.Time    `json:"time"`
+}
+
+// AuditSink receives audit events from the auth service.
+type AuditSink interface {
+	RecordAuditEvent(ctx context.Context, event AuditEvent)
+}
+
+// ClientIPFromContext returns the client IP stored in the context by auth handlers.
+func ClientIPFromContext(ctx context.Context) string {
+	ip, ok := ctx.Value(ClientIPContextKey).(string)
+	if !ok {
+		return ""
+	}
+	return ip
+}
+
+// withClientIP stores the IP of the request's client in its context so it can be added to audit events.
+func withClientIP(r *http.Request) context.Context {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	return context.WithValue(r.Context(), ClientIPContextKey, ip)
+}
+
+func (s *Service) audit(ctx context.Context, action AuditAction, userID string, err error) {
+	if s.auditSink == nil {
+		return
+	}
+
+	event := AuditEvent{
+		Action:  action,
+		Outcome: AuditOutcomeSuccess,
+		UserID:  userID,
+		IP:      ClientIPFromContext(ctx),
+		Time:    time.Now(),
+	}
+	if err != nil {
+		event.Outcome = AuditOutcomeFailure
+		event.Reason = err.Error()
+	}
+
+	s.auditSink.RecordAuditEvent(ctx, event)
+}
@@ -0,0 +1,123 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/platforma-dev/platforma/auth"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestServiceAudit(t *testing.T) {
+	t.Parallel()
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte("password123:salt"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+
+	newService := func(sink auth.AuditSink) *auth.Service {
+		store := newMemoryUserStore(auth.User{ID: "user-id", Username: "testuser", Password: string(hashed), Salt: "salt"})
+		service := auth.NewService(store, newMemorySessionStorage(), "session", nil, nil, nil)
+		service.SetAuditSink(sink)
+		return service
+	}
+
+	ctx := context.WithValue(context.Background(), auth.ClientIPContextKey, "10.0.0.1")
+
+	t.Run("login success is recorded", func(t *testing.T) {
+		t.Parallel()
+
+		sink := &mockAuditSink{}
+		service := newService(sink)
+
+		if _, err := service.CreateSessionFromUsernameAndPassword(ctx, "testuser", "password123"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		events := sink.recorded()
+		if len(events) != 1 {
+			t.Fatalf("expected 1 audit event, got %d", len(events))
+		}
+
+		event := events[0]
+		if event.Action != auth.AuditActionLogin {
+			t.Fatalf("expected action %q, got %q", auth.AuditActionLogin, event.Action)
+		}
+		if event.Outcome != auth.AuditOutcomeSuccess {
+			t.Fatalf("expected outcome %q, got %q", auth.AuditOutcomeSuccess, event.Outcome)
+		}
+		if event.UserID != "user-id" {
+			t.Fatalf("expected user id %q, got %q", "user-id", event.UserID)
+		}
+		if event.IP != "10.0.0.1" {
+			t.Fatalf("expected ip %q, got %q", "10.0.0.1", event.IP)
+		}
+		if event.Reason != "" {
+			t.Fatalf("expected empty reason, got %q", event.Reason)
+		}
+	})
+
+	t.Run("login failure is recorded", func(t *testing.T) {
+		t.Parallel()
+
+		sink := &mockAuditSink{}
+		service := newService(sink)
+
+		_, err := service.CreateSessionFromUsernameAndPassword(ctx, "testuser", "wrong-password")
+		if !errors.Is(err, auth.ErrWrongUserOrPassword) {
+			t.Fatalf("expected wrong user or password error, got %v", err)
+		}
+
+		events := sink.recorded()
+		if len(events) != 1 {
+			t.Fatalf("expected 1 audit event, got %d", len(events))
+		}
+
+		event := events[0]
+		if event.Action != auth.AuditActionLogin {
+			t.Fatalf("expected action %q, got %q", auth.AuditActionLogin, event.Action)
+		}
+		if event.Outcome != auth.AuditOutcomeFailure {
+			t.Fatalf("expected outcome %q, got %q", auth.AuditOutcomeFailure, event.Outcome)
+		}
+		if event.UserID != "user-id" {
+			t.Fatalf("expected user id %q, got %q", "user-id", event.UserID)
+		}
+		if event.IP != "10.0.0.1" {
+			t.Fatalf("expected ip %q, got %q", "10.0.0.1", event.IP)
+		}
+		if event.Reason != auth.ErrWrongUserOrPassword.Error() {
+			t.Fatalf("expected reason %q, got %q", auth.ErrWrongUserOrPassword.Error(), event.Reason)
+		}
+	})
+
+	t.Run("audit is optional", func(t *testing.T) {
+		t.Parallel()
+
+		service := newService(nil)
+
+		if _, err := service.CreateSessionFromUsernameAndPassword(ctx, "testuser", "password123"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+}
+
+type mockAuditSink struct {
+	mu     sync.Mutex
+	events []auth.AuditEvent
+}
+
+func (m *mockAuditSink) RecordAuditEvent(_ context.Context, event auth.AuditEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, event)
+}
+
+func (m *mockAuditSink) recorded() []auth.AuditEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]auth.AuditEvent(nil), m.events...)
+}
@@ -7,7 +7,8 @@ import (
 type contextKey string
 
 const (
-	UserContextKey contextKey = "user"
+	UserContextKey     contextKey = "user"
+	ClientIPContextKey contextKey = "clientIP"
 )
 
 func UserFromContext(ctx context.Context) *User {
@@ -0,0 +1,181 @@
+package auth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/platforma-dev/platforma/auth"
+)
+
+func TestCookieConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("login sets configured attributes and csrf token", func(t *testing.T) {
+		t.Parallel()
+
+		domain := newCSRFDomain(t)
+
+		w := serveAuth(domain, http.MethodPost, "/login", `{"login":"testuser","password":"password123"}`, nil)
+		session := sessionCookie(t, w)
+		if !session.Secure || !session.HttpOnly || session.SameSite != http.SameSiteStrictMode {
+			t.Errorf("expected Secure, HttpOnly, SameSite=Strict session cookie, got %+v", session)
+		}
+
+		csrf := csrfCookie(t, w)
+		if csrf.Value == "" || csrf.HttpOnly || !csrf.Secure {
+			t.Errorf("expected non-empty, script-readable, Secure csrf cookie, got %+v", csrf)
+		}
+	})
+
+	t.Run("defaults keep secure lax cookie without csrf", func(t *testing.T) {
+		t.Parallel()
+
+		domain := auth.NewWithStore(newMemoryUserStore(), newMemorySessionStorage(), "session", nil, nil, nil)
+		serveAuth(domain, http.MethodPost, "/register", `{"login":"testuser","password":"password123"}`, nil)
+
+		w := serveAuth(domain, http.MethodPost, "/login", `{"login":"testuser","password":"password123"}`, nil)
+		if cookies := w.Result().Cookies(); len(cookies) != 1 || cookies[0].SameSite != http.SameSiteLaxMode || !cookies[0].Secure {
+			t.Fatalf("expected single Secure lax session cookie, got %+v", cookies)
+		}
+
+		w = serveAuth(domain, http.MethodPost, "/change-password", `{"currentPassword":"password123","newPassword":"newpassword123"}`, sessionCookie(t, w))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 without csrf protection, got %d", w.Code)
+		}
+	})
+
+	t.Run("insecure allows cookies over plain http", func(t *testing.T) {
+		t.Parallel()
+
+		domain := auth.NewWithStore(newMemoryUserStore(), newMemorySessionStorage(), "session", nil, nil, nil)
+		domain.Service.SetCookieConfig(auth.CookieConfig{Insecure: true, CSRF: true})
+		serveAuth(domain, http.MethodPost, "/register", `{"login":"testuser","password":"password123"}`, nil)
+
+		w := serveAuth(domain, http.MethodPost, "/login", `{"login":"testuser","password":"password123"}`, nil)
+		if session := sessionCookie(t, w); session.Secure || session.SameSite != http.SameSiteLaxMode {
+			t.Errorf("expected non-Secure lax session cookie, got %+v", session)
+		}
+
+		if csrf := csrfCookie(t, w); csrf.Secure {
+			t.Errorf("expected non-Secure csrf cookie, got %+v", csrf)
+		}
+	})
+}
+
+func TestCSRFProtection(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		token  func(csrf *http.Cookie) string
+		status int
+	}{
+		{name: "missing token", token: func(_ *http.Cookie) string { return "" }, status: http.StatusForbidden},
+		{name: "invalid token", token: func(_ *http.Cookie) string { return "forged" }, status: http.StatusForbidden},
+		{name: "valid token", token: func(csrf *http.Cookie) string { return csrf.Value }, status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			domain := newCSRFDomain(t)
+			w := serveAuth(domain, http.MethodPost, "/login", `{"login":"testuser","password":"password123"}`, nil)
+			session, csrf := sessionCookie(t, w), csrfCookie(t, w)
+
+			r := httptest.NewRequest(http.MethodPost, "/change-password", strings.NewReader(`{"currentPassword":"password123","newPassword":"newpassword123"}`))
+			r.AddCookie(session)
+			r.AddCookie(csrf)
+			if token := tt.token(csrf); token != "" {
+				r.Header.Set(auth.CSRFHeaderName, token)
+			}
+
+			w = httptest.NewRecorder()
+			domain.HandleGroup.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, w.Code)
+			}
+		})
+	}
+
+	t.Run("existing session without token gets one", func(t *testing.T) {
+		t.Parallel()
+
+		domain := newCSRFDomain(t)
+		w := serveAuth(domain, http.MethodPost, "/login", `{"login":"testuser","password":"password123"}`, nil)
+		session := sessionCookie(t, w)
+
+		// the session was created before the client stored the token, e.g. before protection was enabled
+		w = serveAuth(domain, http.MethodPost, "/change-password", `{"currentPassword":"password123","newPassword":"newpassword123"}`, session)
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("expected status 403 without token, got %d", w.Code)
+		}
+		csrf := csrfCookie(t, w)
+
+		r := httptest.NewRequest(http.MethodPost, "/change-password", strings.NewReader(`{"currentPassword":"password123","newPassword":"newpassword123"}`))
+		r.AddCookie(session)
+		r.AddCookie(csrf)
+		r.Header.Set(auth.CSRFHeaderName, csrf.Value)
+
+		w = httptest.NewRecorder()
+		domain.HandleGroup.ServeHTTP(w, r)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 with issued token, got %d", w.Code)
+		}
+	})
+
+	t.Run("unauthenticated request gets 401", func(t *testing.T) {
+		t.Parallel()
+
+		domain := newCSRFDomain(t)
+
+		w := serveAuth(domain, http.MethodPost, "/change-password", `{"currentPassword":"password123","newPassword":"newpassword123"}`, nil)
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("expected status 401, got %d", w.Code)
+		}
+	})
+
+	t.Run("safe methods don't require token", func(t *testing.T) {
+		t.Parallel()
+
+		domain := newCSRFDomain(t)
+		w := serveAuth(domain, http.MethodPost, "/login", `{"login":"testuser","password":"password123"}`, nil)
+
+		w = serveAuth(domain, http.MethodGet, "/me", "", sessionCookie(t, w))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 on me, got %d", w.Code)
+		}
+	})
+}
+
+// newCSRFDomain returns a domain with CSRF protection and a registered "testuser".
+func newCSRFDomain(t *testing.T) *auth.Domain {
+	t.Helper()
+
+	domain := auth.NewWithStore(newMemoryUserStore(), newMemorySessionStorage(), "session", nil, nil, nil)
+	domain.Service.SetCookieConfig(auth.CookieConfig{SameSite: http.SameSiteStrictMode, CSRF: true})
+
+	w := serveAuth(domain, http.MethodPost, "/register", `{"login":"testuser","password":"password123"}`, nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201 on register, got %d", w.Code)
+	}
+
+	return domain
+}
+
+func csrfCookie(t *testing.T, w *httptest.ResponseRecorder) *http.Cookie {
+	t.Helper()
+
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == auth.CSRFCookieName {
+			return cookie
+		}
+	}
+
+	t.Fatal("expected csrf cookie to be set")
+	return nil
+}
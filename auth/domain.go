@@ -5,19 +5,29 @@ import (
 )
 
 type Domain struct {
-	Repository  *Repository
+	Repository  *Repository // nil when the domain was created with NewWithStore
+	Store       UserStore
 	Service     *Service
 	HandleGroup *httpserver.HandlerGroup
 	Middleware  httpserver.Middleware
 }
 
 func (d *Domain) GetRepository() any {
-	return d.Repository
+	return d.Store
 }
 
 func New(db db, authStorage authStorage, sessionCookieName string, usernameValidator, passwordValidator func(string) error, cleanupEnqueuer cleanupEnqueuer) *Domain {
 	repository := NewRepository(db)
-	service := NewService(repository, authStorage, sessionCookieName, usernameValidator, passwordValidator, cleanupEnqueuer)
+
+	domain := NewWithStore(repository, authStorage, sessionCookieName, usernameValidator, passwordValidator, cleanupEnqueuer)
+	domain.Repository = repository
+
+	return domain
+}
+
+// NewWithStore creates the auth domain on top of any UserStore, e.g. an in-memory store in tests.
+func NewWithStore(store UserStore, authStorage authStorage, sessionCookieName string, usernameValidator, passwordValidator func(string) error, cleanupEnqueuer cleanupEnqueuer) *Domain {
+	service := NewService(store, authStorage, sessionCookieName, usernameValidator, passwordValidator, cleanupEnqueuer)
 
 	authMiddleware := NewAuthenticationMiddleware(service)
 	registerHandler := NewRegisterHandler(service)
@@ -26,6 +36,8 @@ func New(db db, authStorage authStorage, sessionCookieName string, usernameValid
 	getUserHandler := NewGetHandler(service)
 	changePasswordHandler := authMiddleware.Wrap(NewChangePasswordHandler(service))
 	deleteHandler := authMiddleware.Wrap(NewDeleteHandler(service))
+	totpEnrollHandler := authMiddleware.Wrap(NewTOTPEnrollHandler(service))
+	totpConfirmHandler := authMiddleware.Wrap(NewTOTPConfirmHandler(service))
 
 	authAPI := httpserver.NewHandlerGroup()
 	authAPI.Handle("POST /register", registerHandler)
@@ -34,9 +46,11 @@ func New(db db, authStorage authStorage, sessionCookieName string, usernameValid
 	authAPI.Handle("GET /me", getUserHandler)
 	authAPI.Handle("POST /change-password", changePasswordHandler)
 	authAPI.Handle("DELETE /me", deleteHandler)
+	authAPI.Handle("POST /totp/enroll", totpEnrollHandler)
+	authAPI.Handle("POST /totp/confirm", totpConfirmHandler)
 
 	return &Domain{
-		Repository:  repository,
+		Store:       store,
 		Service:     service,
 		HandleGroup: authAPI,
 		Middleware:  authMiddleware,
@@ -14,4 +14,10 @@ var (
 	ErrShortPassword            = errors.New("short password")
 	ErrLongPassword             = errors.New("long password")
 	ErrCurrentPasswordIncorrect = errors.New("current password is incorrect")
+
+	ErrTOTPRequired       = errors.New("totp code required")
+	ErrInvalidTOTPCode    = errors.New("invalid totp code")
+	ErrTOTPNotEnrolled    = errors.New("totp not enrolled")
+	ErrTOTPAlreadyEnabled = errors.New("totp already enabled")
+	ErrTOTPNotSupported   = errors.New("totp not supported by user store")
 )
@@ -34,7 +34,7 @@ func (h *ChangePasswordHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	err := h.service.ChangePassword(r.Context(), req.CurrentPassword, req.NewPassword)
+	err := h.service.ChangePassword(withClientIP(r), req.CurrentPassword, req.NewPassword)
 	log.DebugContext(r.Context(), "error from change password", "error", err)
 
 	if err != nil {
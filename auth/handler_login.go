@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"time"
 )
 
 type LoginHandler struct {
@@ -25,6 +26,7 @@ func (h *LoginHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Login    string `json:"login"`
 		Password string `json:"password"` //nolint:gosec // Password in request
+		Code     string `json:"code"`     // TOTP code, required when the user has TOTP enabled
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -32,9 +34,9 @@ func (h *LoginHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sessionId, err := h.service.CreateSessionFromUsernameAndPassword(r.Context(), req.Login, req.Password)
+	sessionId, err := h.service.CreateSessionFromCredentials(withClientIP(r), req.Login, req.Password, req.Code)
 	if err != nil {
-		if errors.Is(err, ErrWrongUserOrPassword) {
+		if errors.Is(err, ErrWrongUserOrPassword) || errors.Is(err, ErrTOTPRequired) || errors.Is(err, ErrInvalidTOTPCode) {
 			http.Error(w, err.Error(), http.StatusUnauthorized)
 			return
 		}
@@ -48,13 +50,10 @@ func (h *LoginHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     h.service.CookieName(),
-		Value:    sessionId,
-		Path:     "/",
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-	})
+	http.SetCookie(w, h.service.sessionCookie(sessionId, time.Time{}))
+	if h.service.CookieConfig().CSRF {
+		http.SetCookie(w, h.service.csrfCookie(newCSRFToken(), time.Time{}))
+	}
 
 	w.WriteHeader(http.StatusOK)
 }
@@ -30,19 +30,16 @@ func (h *LogoutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Delete session from database
-	if err := h.service.DeleteSession(r.Context(), cookie.Value); err != nil {
+	if err := h.service.DeleteSession(withClientIP(r), cookie.Value); err != nil {
 		http.Error(w, "failed to logout", http.StatusInternalServerError)
 		return
 	}
 
 	// Clear session cookie by setting it to expire immediately
-	http.SetCookie(w, &http.Cookie{
-		Name:     h.service.CookieName(),
-		Value:    "",
-		Path:     "/",
-		Expires:  time.Unix(0, 0),
-		HttpOnly: true,
-	})
+	http.SetCookie(w, h.service.sessionCookie("", time.Unix(0, 0)))
+	if h.service.CookieConfig().CSRF {
+		http.SetCookie(w, h.service.csrfCookie("", time.Unix(0, 0)))
+	}
 
 	// Return success response
 	w.WriteHeader(http.StatusOK)
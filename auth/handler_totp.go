@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/platforma-dev/platforma/httpserver"
+	"github.com/platforma-dev/platforma/log"
+)
+
+type TOTPEnrollHandler struct {
+	service *Service
+}
+
+func NewTOTPEnrollHandler(service *Service) *TOTPEnrollHandler {
+	return &TOTPEnrollHandler{
+		service: service,
+	}
+}
+
+func (h *TOTPEnrollHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	enrollment, err := h.service.EnrollTOTP(ctx)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		case errors.Is(err, ErrTOTPAlreadyEnabled):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, ErrTOTPNotSupported):
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err := httpserver.WriteJSON(w, http.StatusOK, enrollment); err != nil {
+		log.ErrorContext(ctx, "failed to write totp enrollment response", "error", err)
+	}
+}
+
+type TOTPConfirmHandler struct {
+	service *Service
+}
+
+func NewTOTPConfirmHandler(service *Service) *TOTPConfirmHandler {
+	return &TOTPConfirmHandler{
+		service: service,
+	}
+}
+
+func (h *TOTPConfirmHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Code string `json:"code"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	err := h.service.ConfirmTOTP(r.Context(), req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound), errors.Is(err, ErrInvalidTOTPCode):
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		case errors.Is(err, ErrTOTPNotEnrolled):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, ErrTOTPNotSupported):
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/platforma-dev/platforma/log"
 )
@@ -13,6 +14,11 @@ type userService interface {
 	CookieName() string
 }
 
+// cookieConfigProvider is implemented by services that configure CSRF protection, such as Service.
+type cookieConfigProvider interface {
+	CookieConfig() CookieConfig
+}
+
 type AuthenticationMiddleware struct {
 	userService userService
 }
@@ -40,6 +46,20 @@ func (m *AuthenticationMiddleware) Wrap(next http.Handler) http.Handler {
 			return
 		}
 
+		// the CSRF token is checked once the session is known to be valid, so that
+		// unauthenticated requests get 401 and sessions without a token can get one
+		if config, ok := m.csrfConfig(); ok {
+			if _, err := r.Cookie(CSRFCookieName); err != nil {
+				// sessions created before CSRF protection was enabled have no token yet
+				http.SetCookie(w, newCSRFCookie(config, newCSRFToken(), time.Time{}))
+			}
+
+			if isStateChangingMethod(r.Method) && !validCSRFToken(r) {
+				http.Error(w, "invalid csrf token", http.StatusForbidden)
+				return
+			}
+		}
+
 		newRequest := r
 		if user != nil {
 			if event := log.EventFromContext(r.Context()); event != nil {
@@ -57,3 +77,13 @@ func (m *AuthenticationMiddleware) Wrap(next http.Handler) http.Handler {
 		next.ServeHTTP(w, newRequest)
 	})
 }
+
+// csrfConfig returns the cookie configuration if CSRF protection is enabled.
+func (m *AuthenticationMiddleware) csrfConfig() (CookieConfig, bool) {
+	provider, ok := m.userService.(cookieConfigProvider)
+	if !ok || !provider.CookieConfig().CSRF {
+		return CookieConfig{}, false
+	}
+
+	return provider.CookieConfig(), true
+}
@@ -4,8 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"embed"
+	"errors"
 	"fmt"
 	"io/fs"
+	"time"
+
+	"github.com/jmoiron/sqlx"
 )
 
 type db interface {
@@ -15,16 +19,35 @@ type db interface {
 	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
 }
 
+// Repositories run on a connection pool or inside a transaction, see WithTx.
+var (
+	_ db = (*sqlx.DB)(nil)
+	_ db = (*sqlx.Tx)(nil)
+)
+
 type Repository struct {
 	db db
 }
 
+var (
+	_ UserStore = (*Repository)(nil)
+	_ TOTPStore = (*Repository)(nil)
+)
+
 func NewRepository(db db) *Repository {
 	return &Repository{
 		db: db,
 	}
 }
 
+// WithTx returns a copy of the repository that runs its queries in tx,
+// e.g. inside database.Database.RunInTx. Both *sqlx.DB and *sqlx.Tx can be used.
+func (r *Repository) WithTx(tx db) *Repository {
+	return &Repository{
+		db: tx,
+	}
+}
+
 //go:embed migrations/*.sql
 var migrations embed.FS
 
@@ -84,3 +107,44 @@ func (r *Repository) Delete(ctx context.Context, id string) error {
 	}
 	return nil
 }
+
+func (r *Repository) GetTOTPSecret(ctx context.Context, userID string) (*TOTPSecret, error) {
+	var secret TOTPSecret
+	err := r.db.GetContext(ctx, &secret, "SELECT * FROM totp_secrets WHERE user_id = $1", userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTOTPNotEnrolled
+		}
+		return nil, fmt.Errorf("failed to get totp secret: %w", err)
+	}
+	return &secret, nil
+}
+
+func (r *Repository) SaveTOTPSecret(ctx context.Context, secret *TOTPSecret) error {
+	query := `
+		INSERT INTO totp_secrets (user_id, secret, enabled, last_used_step, created, updated)
+		VALUES (:user_id, :secret, :enabled, :last_used_step, :created, :updated)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, enabled = EXCLUDED.enabled,
+			last_used_step = GREATEST(totp_secrets.last_used_step, EXCLUDED.last_used_step), updated = EXCLUDED.updated
+	`
+	_, err := r.db.NamedExecContext(ctx, query, secret)
+	if err != nil {
+		return fmt.Errorf("failed to save totp secret: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error) {
+	query := `UPDATE totp_secrets SET last_used_step = $2, updated = $3 WHERE user_id = $1 AND last_used_step < $2`
+	result, err := r.db.ExecContext(ctx, query, userID, step, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to use totp step: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to use totp step: %w", err)
+	}
+	return affected == 1, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/platforma-dev/platforma/log"
@@ -12,7 +13,9 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-type repository interface {
+// UserStore persists users. Repository is the SQL implementation; any other
+// backend can be passed to NewWithStore.
+type UserStore interface {
 	Get(ctx context.Context, id string) (*User, error)
 	GetByUsername(ctx context.Context, username string) (*User, error)
 	Create(ctx context.Context, user *User) error
@@ -28,15 +31,19 @@ type authStorage interface {
 }
 
 type Service struct {
-	repo              repository
+	repo              UserStore
 	authStorage       authStorage
 	sessionCookieName string
 	usernameValidator func(string) error
 	passwordValidator func(string) error
 	cleanupEnqueuer   cleanupEnqueuer
+	auditSink         AuditSink
+	cookieConfig      CookieConfig
+	totpStore         TOTPStore // nil when repo doesn't implement TOTPStore
+	totpIssuer        string
 }
 
-func NewService(repo repository, authStorage authStorage, sessionCookieName string, usernameValidator, passwordValidator func(string) error, cleanupEnqueuer cleanupEnqueuer) *Service {
+func NewService(repo UserStore, authStorage authStorage, sessionCookieName string, usernameValidator, passwordValidator func(string) error, cleanupEnqueuer cleanupEnqueuer) *Service {
 	if usernameValidator == nil {
 		usernameValidator = defaultUsernameValidator
 	}
@@ -45,6 +52,8 @@ func NewService(repo repository, authStorage authStorage, sessionCookieName stri
 		passwordValidator = defaultPasswordValidator
 	}
 
+	totpStore, _ := repo.(TOTPStore)
+
 	return &Service{
 		repo:              repo,
 		authStorage:       authStorage,
@@ -52,9 +61,18 @@ func NewService(repo repository, authStorage authStorage, sessionCookieName stri
 		usernameValidator: usernameValidator,
 		passwordValidator: passwordValidator,
 		cleanupEnqueuer:   cleanupEnqueuer,
+		cookieConfig:      CookieConfig{SameSite: http.SameSiteLaxMode},
+		totpStore:         totpStore,
+		totpIssuer:        DefaultTOTPIssuer,
 	}
 }
 
+// SetAuditSink sets the sink that receives audit events for logins, logouts and password changes.
+// Auditing is disabled when sink is nil.
+func (s *Service) SetAuditSink(sink AuditSink) {
+	s.auditSink = sink
+}
+
 func (s *Service) Get(ctx context.Context, id string) (*User, error) {
 	user, err := s.repo.Get(ctx, id)
 	if err != nil {
@@ -111,29 +129,59 @@ func (s *Service) CreateWithLoginAndPassword(ctx context.Context, username, pass
 	return nil
 }
 
+// CreateSessionFromUsernameAndPassword logs in users without TOTP enabled, see CreateSessionFromCredentials.
 func (s *Service) CreateSessionFromUsernameAndPassword(ctx context.Context, username, password string) (string, error) {
+	return s.CreateSessionFromCredentials(ctx, username, password, "")
+}
+
+// CreateSessionFromCredentials checks the password and, for users with TOTP enabled, totpCode,
+// and creates a session. A missing code fails with ErrTOTPRequired and a wrong, expired or
+// already used one with ErrInvalidTOTPCode.
+func (s *Service) CreateSessionFromCredentials(ctx context.Context, username, password, totpCode string) (string, error) {
 	user, err := s.repo.GetByUsername(ctx, username)
 	if err != nil {
+		s.audit(ctx, AuditActionLogin, "", ErrWrongUserOrPassword)
 		return "", ErrWrongUserOrPassword
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password+":"+user.Salt))
 	if err != nil {
+		s.audit(ctx, AuditActionLogin, user.ID, ErrWrongUserOrPassword)
 		return "", ErrWrongUserOrPassword
 	}
 
+	err = s.verifyTOTP(ctx, user.ID, totpCode)
+	if err != nil {
+		s.audit(ctx, AuditActionLogin, user.ID, err)
+		return "", err
+	}
+
 	session, err := s.authStorage.CreateSessionForUser(ctx, user.ID)
 	if err != nil {
-		return "", fmt.Errorf("failed to create session: %w", err)
+		err = fmt.Errorf("failed to create session: %w", err)
+		s.audit(ctx, AuditActionLogin, user.ID, err)
+		return "", err
 	}
+
+	s.audit(ctx, AuditActionLogin, user.ID, nil)
 	return session, nil
 }
 
 func (s *Service) DeleteSession(ctx context.Context, sessionId string) error {
+	var userId string
+	if s.auditSink != nil {
+		// Resolve the user before the session is gone so that the logout can be attributed
+		userId, _ = s.authStorage.GetUserIdFromSessionId(ctx, sessionId)
+	}
+
 	err := s.authStorage.DeleteSession(ctx, sessionId)
 	if err != nil {
-		return fmt.Errorf("failed to delete session: %w", err)
+		err = fmt.Errorf("failed to delete session: %w", err)
+		s.audit(ctx, AuditActionLogout, userId, err)
+		return err
 	}
+
+	s.audit(ctx, AuditActionLogout, userId, nil)
 	return nil
 }
 
@@ -147,6 +195,12 @@ func (s *Service) ChangePassword(ctx context.Context, currentPassword, newPasswo
 		return ErrUserNotFound
 	}
 
+	err := s.changePassword(ctx, user, currentPassword, newPassword)
+	s.audit(ctx, AuditActionChangePassword, user.ID, err)
+	return err
+}
+
+func (s *Service) changePassword(ctx context.Context, user *User, currentPassword, newPassword string) error {
 	if s.passwordValidator != nil {
 		log.DebugContext(ctx, "validating new password")
 		err := s.passwordValidator(newPassword)
@@ -0,0 +1,265 @@
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/platforma-dev/platforma/auth"
+)
+
+func TestDomainWithMemoryStore(t *testing.T) {
+	t.Parallel()
+
+	t.Run("register, login, change password and logout", func(t *testing.T) {
+		t.Parallel()
+
+		store := newMemoryUserStore()
+		sessions := newMemorySessionStorage()
+		domain := auth.NewWithStore(store, sessions, "session", nil, nil, nil)
+
+		w := serveAuth(domain, http.MethodPost, "/register", `{"login":"testuser","password":"password123"}`, nil)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected status 201 on register, got %d", w.Code)
+		}
+
+		w = serveAuth(domain, http.MethodPost, "/login", `{"login":"testuser","password":"password123"}`, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 on login, got %d", w.Code)
+		}
+		cookie := sessionCookie(t, w)
+
+		w = serveAuth(domain, http.MethodGet, "/me", "", cookie)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 on me, got %d", w.Code)
+		}
+		if !strings.Contains(w.Body.String(), `"username":"testuser"`) {
+			t.Fatalf("expected username in response, got %s", w.Body.String())
+		}
+
+		w = serveAuth(domain, http.MethodPost, "/change-password", `{"currentPassword":"password123","newPassword":"newpassword123"}`, cookie)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 on change password, got %d", w.Code)
+		}
+
+		w = serveAuth(domain, http.MethodPost, "/login", `{"login":"testuser","password":"password123"}`, nil)
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("expected status 401 on login with old password, got %d", w.Code)
+		}
+
+		w = serveAuth(domain, http.MethodPost, "/login", `{"login":"testuser","password":"newpassword123"}`, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 on login with new password, got %d", w.Code)
+		}
+
+		w = serveAuth(domain, http.MethodPost, "/logout", "", cookie)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 on logout, got %d", w.Code)
+		}
+
+		if userID, _ := sessions.GetUserIdFromSessionId(context.Background(), cookie.Value); userID != "" {
+			t.Fatalf("expected session to be deleted, got user %q", userID)
+		}
+	})
+
+	t.Run("delete removes user from store", func(t *testing.T) {
+		t.Parallel()
+
+		store := newMemoryUserStore()
+		domain := auth.NewWithStore(store, newMemorySessionStorage(), "session", nil, nil, nil)
+
+		serveAuth(domain, http.MethodPost, "/register", `{"login":"testuser","password":"password123"}`, nil)
+		w := serveAuth(domain, http.MethodPost, "/login", `{"login":"testuser","password":"password123"}`, nil)
+		cookie := sessionCookie(t, w)
+
+		w = serveAuth(domain, http.MethodDelete, "/me", "", cookie)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 on delete, got %d", w.Code)
+		}
+
+		if _, err := store.GetByUsername(context.Background(), "testuser"); err == nil {
+			t.Fatal("expected user to be deleted from store")
+		}
+	})
+}
+
+func serveAuth(domain *auth.Domain, method, path, body string, cookie *http.Cookie) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if cookie != nil {
+		r.AddCookie(cookie)
+	}
+
+	w := httptest.NewRecorder()
+	domain.HandleGroup.ServeHTTP(w, r)
+
+	return w
+}
+
+func sessionCookie(t *testing.T, w *httptest.ResponseRecorder) *http.Cookie {
+	t.Helper()
+
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == "session" {
+			return cookie
+		}
+	}
+
+	t.Fatal("expected session cookie to be set")
+	return nil
+}
+
+// memoryUserStore is an in-memory auth.UserStore and auth.TOTPStore.
+type memoryUserStore struct {
+	mu    sync.Mutex
+	users map[string]auth.User
+	totp  map[string]auth.TOTPSecret
+}
+
+func newMemoryUserStore(users ...auth.User) *memoryUserStore {
+	s := &memoryUserStore{users: map[string]auth.User{}, totp: map[string]auth.TOTPSecret{}}
+	for _, user := range users {
+		s.users[user.ID] = user
+	}
+	return s
+}
+
+func (s *memoryUserStore) Get(_ context.Context, id string) (*auth.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil, auth.ErrUserNotFound
+	}
+	return &user, nil
+}
+
+func (s *memoryUserStore) GetByUsername(_ context.Context, username string) (*auth.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.users {
+		if user.Username == username {
+			return &user, nil
+		}
+	}
+	return nil, auth.ErrUserNotFound
+}
+
+func (s *memoryUserStore) Create(_ context.Context, user *auth.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users[user.ID] = *user
+	return nil
+}
+
+func (s *memoryUserStore) UpdatePassword(_ context.Context, id, password, salt string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return auth.ErrUserNotFound
+	}
+	user.Password = password
+	user.Salt = salt
+	s.users[id] = user
+	return nil
+}
+
+func (s *memoryUserStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.users, id)
+	return nil
+}
+
+func (s *memoryUserStore) GetTOTPSecret(_ context.Context, userID string) (*auth.TOTPSecret, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secret, ok := s.totp[userID]
+	if !ok {
+		return nil, auth.ErrTOTPNotEnrolled
+	}
+	return &secret, nil
+}
+
+func (s *memoryUserStore) SaveTOTPSecret(_ context.Context, secret *auth.TOTPSecret) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := *secret
+	if existing, ok := s.totp[secret.UserID]; ok && existing.LastUsedStep > saved.LastUsedStep {
+		saved.LastUsedStep = existing.LastUsedStep
+	}
+	s.totp[secret.UserID] = saved
+	return nil
+}
+
+func (s *memoryUserStore) UseTOTPStep(_ context.Context, userID string, step int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secret, ok := s.totp[userID]
+	if !ok || secret.LastUsedStep >= step {
+		return false, nil
+	}
+	secret.LastUsedStep = step
+	secret.Updated = time.Now()
+	s.totp[userID] = secret
+	return true, nil
+}
+
+// memorySessionStorage is an in-memory session storage for the auth domain.
+type memorySessionStorage struct {
+	mu       sync.Mutex
+	sessions map[string]string
+}
+
+func newMemorySessionStorage() *memorySessionStorage {
+	return &memorySessionStorage{sessions: map[string]string{}}
+}
+
+func (s *memorySessionStorage) GetUserIdFromSessionId(_ context.Context, sessionId string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sessions[sessionId], nil
+}
+
+func (s *memorySessionStorage) CreateSessionForUser(_ context.Context, userId string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessionId := uuid.NewString()
+	s.sessions[sessionId] = userId
+	return sessionId, nil
+}
+
+func (s *memorySessionStorage) DeleteSession(_ context.Context, sessionId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, sessionId)
+	return nil
+}
+
+func (s *memorySessionStorage) DeleteSessionsByUserId(_ context.Context, userId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sessionId, id := range s.sessions {
+		if id == userId {
+			delete(s.sessions, sessionId)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // HMAC-SHA1 is the algorithm of RFC 6238 and authenticator apps
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	totpPeriod     = 30 * time.Second
+	totpDigits     = 6
+	totpSkewSteps  = 1
+	totpSecretSize = 20
+
+	// DefaultTOTPIssuer is the issuer shown by authenticator apps unless set with SetTOTPIssuer.
+	DefaultTOTPIssuer = "platforma"
+)
+
+// TOTPSecret is the time-based one-time password secret of a user. It is stored when the user
+// enrolls and enabled once the user confirms a code, after which login requires a code.
+type TOTPSecret struct {
+	UserID  string `db:"user_id" json:"userId"`
+	Secret  string `db:"secret"  json:"-"`
+	Enabled bool   `db:"enabled" json:"enabled"`
+	// LastUsedStep is the time step of the last accepted code, so that a code can't be used twice.
+	LastUsedStep int64     `db:"last_used_step" json:"-"`
+	Created      time.Time `db:"created"        json:"created"`
+	Updated      time.Time `db:"updated"        json:"updated"`
+}
+
+// TOTPStore persists TOTP secrets. A UserStore that also implements TOTPStore, like Repository,
+// enables two-factor authentication in the service.
+type TOTPStore interface {
+	// GetTOTPSecret returns ErrTOTPNotEnrolled if the user has no secret.
+	GetTOTPSecret(ctx context.Context, userID string) (*TOTPSecret, error)
+	// SaveTOTPSecret creates or replaces the secret of a user, keeping the greater LastUsedStep.
+	SaveTOTPSecret(ctx context.Context, secret *TOTPSecret) error
+	// UseTOTPStep sets LastUsedStep of the user to step only if it is lower, in a single atomic update,
+	// and reports whether it did. A code is accepted only if its step was used this way.
+	UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error)
+}
+
+// TOTPEnrollment is returned by EnrollTOTP. URL is an otpauth:// URL to show as a QR code.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URL    string `json:"url"`
+}
+
+// SetTOTPIssuer sets the issuer shown by authenticator apps for enrolled secrets.
+func (s *Service) SetTOTPIssuer(issuer string) {
+	s.totpIssuer = issuer
+}
+
+// EnrollTOTP generates a new TOTP secret for the user in context. Login doesn't require a code
+// until the user confirms the enrollment with ConfirmTOTP, so that a lost secret doesn't lock them out.
+// Enrolling again before confirming replaces the secret; enrolling with TOTP enabled fails with ErrTOTPAlreadyEnabled.
+func (s *Service) EnrollTOTP(ctx context.Context) (*TOTPEnrollment, error) {
+	user := UserFromContext(ctx)
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if s.totpStore == nil {
+		return nil, ErrTOTPNotSupported
+	}
+
+	existing, err := s.totpStore.GetTOTPSecret(ctx, user.ID)
+	if err != nil && !errors.Is(err, ErrTOTPNotEnrolled) {
+		return nil, fmt.Errorf("failed to get totp secret: %w", err)
+	}
+	if existing != nil && existing.Enabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+
+	key := make([]byte, totpSecretSize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate totp secret: %w", err)
+	}
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(key)
+
+	now := time.Now()
+	err = s.totpStore.SaveTOTPSecret(ctx, &TOTPSecret{UserID: user.ID, Secret: secret, Created: now, Updated: now})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save totp secret: %w", err)
+	}
+
+	return &TOTPEnrollment{Secret: secret, URL: totpURL(s.totpIssuer, user.Username, secret)}, nil
+}
+
+// ConfirmTOTP enables TOTP for the user in context if code is valid for the enrolled secret.
+func (s *Service) ConfirmTOTP(ctx context.Context, code string) error {
+	user := UserFromContext(ctx)
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	if s.totpStore == nil {
+		return ErrTOTPNotSupported
+	}
+
+	secret, err := s.totpStore.GetTOTPSecret(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, ErrTOTPNotEnrolled) {
+			return ErrTOTPNotEnrolled
+		}
+		return fmt.Errorf("failed to get totp secret: %w", err)
+	}
+
+	step, err := s.acceptTOTPCode(ctx, secret, code)
+	if err != nil {
+		return err
+	}
+
+	secret.Enabled = true
+	secret.LastUsedStep = step
+	secret.Updated = time.Now()
+	if err := s.totpStore.SaveTOTPSecret(ctx, secret); err != nil {
+		return fmt.Errorf("failed to save totp secret: %w", err)
+	}
+
+	return nil
+}
+
+// verifyTOTP checks code if the user has TOTP enabled. It returns ErrTOTPRequired when
+// the code is empty and ErrInvalidTOTPCode when it doesn't match.
+func (s *Service) verifyTOTP(ctx context.Context, userID, code string) error {
+	if s.totpStore == nil {
+		return nil
+	}
+
+	secret, err := s.totpStore.GetTOTPSecret(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrTOTPNotEnrolled) {
+			return nil
+		}
+		return fmt.Errorf("failed to get totp secret: %w", err)
+	}
+
+	if !secret.Enabled {
+		return nil
+	}
+
+	if code == "" {
+		return ErrTOTPRequired
+	}
+
+	_, err = s.acceptTOTPCode(ctx, secret, code)
+	return err
+}
+
+// acceptTOTPCode validates code against secret and marks the step of the code as used,
+// rejecting codes of steps that were already used, also by concurrent requests.
+func (s *Service) acceptTOTPCode(ctx context.Context, secret *TOTPSecret, code string) (int64, error) {
+	step, ok := validateTOTP(secret.Secret, code, time.Now())
+	if !ok || step <= secret.LastUsedStep {
+		return 0, ErrInvalidTOTPCode
+	}
+
+	used, err := s.totpStore.UseTOTPStep(ctx, secret.UserID, step)
+	if err != nil {
+		return 0, fmt.Errorf("failed to use totp step: %w", err)
+	}
+	if !used {
+		return 0, ErrInvalidTOTPCode
+	}
+
+	return step, nil
+}
+
+// TOTPCode returns the RFC 6238 code of a base32 secret at t, using HMAC-SHA1, 30 second steps
+// and 6 digits like common authenticator apps. It is mostly useful in tests and clients.
+func TOTPCode(secret string, t time.Time) (string, error) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return "", err
+	}
+
+	return totpCode(key, totpStep(t)), nil
+}
+
+// validateTOTP reports whether code matches secret within the allowed clock skew and returns its step.
+func validateTOTP(secret, code string, now time.Time) (int64, bool) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := totpStep(now)
+	for step := current - totpSkewSteps; step <= current+totpSkewSteps; step++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+func totpStep(t time.Time) int64 {
+	return t.Unix() / int64(totpPeriod/time.Second)
+}
+
+// totpCode computes the HOTP value of RFC 4226 for the counter step.
+func totpCode(key []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step)) //nolint:gosec // steps of real times are positive
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%06d", value%1_000_000)
+}
+
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	normalized := strings.TrimRight(strings.ToUpper(strings.ReplaceAll(secret, " ", "")), "=")
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(normalized)
+	if err != nil {
+		return nil, fmt.Errorf("invalid totp secret: %w", err)
+	}
+
+	return key, nil
+}
+
+// totpURL returns the otpauth:// URL understood by authenticator apps.
+func totpURL(issuer, username, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(int(totpPeriod/time.Second)))
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + username,
+		RawQuery: query.Encode(),
+	}
+
+	return u.String()
+}
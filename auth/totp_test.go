@@ -0,0 +1,238 @@
+package auth_test
+
+import (
+	"context"
+	"encoding/base32"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/platforma-dev/platforma/auth"
+)
+
+func TestTOTPCode(t *testing.T) {
+	t.Parallel()
+
+	// test vectors of RFC 6238 appendix B for SHA1, truncated to 6 digits
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.unix), func(t *testing.T) {
+			t.Parallel()
+
+			code, err := auth.TOTPCode(secret, time.Unix(tt.unix, 0))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			if code != tt.want {
+				t.Fatalf("expected code %s, got %s", tt.want, code)
+			}
+		})
+	}
+}
+
+func TestTOTPLogin(t *testing.T) {
+	t.Parallel()
+
+	// enroll registers testuser, logs in, enrolls and confirms TOTP, and returns the secret
+	enroll := func(t *testing.T, domain *auth.Domain) string {
+		t.Helper()
+
+		serveAuth(domain, http.MethodPost, "/register", `{"login":"testuser","password":"password123"}`, nil)
+		cookie := sessionCookie(t, serveAuth(domain, http.MethodPost, "/login", `{"login":"testuser","password":"password123"}`, nil))
+
+		w := serveAuth(domain, http.MethodPost, "/totp/enroll", "", cookie)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 on enroll, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var enrollment auth.TOTPEnrollment
+		if err := json.Unmarshal(w.Body.Bytes(), &enrollment); err != nil {
+			t.Fatalf("failed to decode enrollment: %v", err)
+		}
+
+		w = serveAuth(domain, http.MethodPost, "/totp/confirm", fmt.Sprintf(`{"code":%q}`, totpCode(t, enrollment.Secret, time.Now())), cookie)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 on confirm, got %d: %s", w.Code, w.Body.String())
+		}
+
+		return enrollment.Secret
+	}
+
+	t.Run("enrollment returns secret and otpauth url", func(t *testing.T) {
+		t.Parallel()
+
+		store := newMemoryUserStore()
+		domain := auth.NewWithStore(store, newMemorySessionStorage(), "session", nil, nil, nil)
+
+		serveAuth(domain, http.MethodPost, "/register", `{"login":"testuser","password":"password123"}`, nil)
+		cookie := sessionCookie(t, serveAuth(domain, http.MethodPost, "/login", `{"login":"testuser","password":"password123"}`, nil))
+
+		w := serveAuth(domain, http.MethodPost, "/totp/enroll", "", cookie)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 on enroll, got %d", w.Code)
+		}
+
+		var enrollment auth.TOTPEnrollment
+		if err := json.Unmarshal(w.Body.Bytes(), &enrollment); err != nil {
+			t.Fatalf("failed to decode enrollment: %v", err)
+		}
+
+		u, err := url.Parse(enrollment.URL)
+		if err != nil || u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/platforma:testuser" {
+			t.Fatalf("expected otpauth url for testuser, got %q", enrollment.URL)
+		}
+
+		if u.Query().Get("secret") != enrollment.Secret || u.Query().Get("issuer") != auth.DefaultTOTPIssuer {
+			t.Fatalf("expected secret and issuer in url, got %q", enrollment.URL)
+		}
+
+		// login doesn't require a code until the enrollment is confirmed
+		w = serveAuth(domain, http.MethodPost, "/login", `{"login":"testuser","password":"password123"}`, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 on login before confirmation, got %d", w.Code)
+		}
+	})
+
+	t.Run("enroll requires authentication", func(t *testing.T) {
+		t.Parallel()
+
+		domain := auth.NewWithStore(newMemoryUserStore(), newMemorySessionStorage(), "session", nil, nil, nil)
+
+		w := serveAuth(domain, http.MethodPost, "/totp/enroll", "", nil)
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("expected status 401, got %d", w.Code)
+		}
+	})
+
+	t.Run("valid code logs in", func(t *testing.T) {
+		t.Parallel()
+
+		domain := auth.NewWithStore(newMemoryUserStore(), newMemorySessionStorage(), "session", nil, nil, nil)
+		secret := enroll(t, domain)
+
+		// the confirmation used the current step, so log in with the code of the next one
+		code := totpCode(t, secret, time.Now().Add(30*time.Second))
+		w := serveAuth(domain, http.MethodPost, "/login", fmt.Sprintf(`{"login":"testuser","password":"password123","code":%q}`, code), nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 on login with valid code, got %d: %s", w.Code, w.Body.String())
+		}
+		sessionCookie(t, w)
+
+		w = serveAuth(domain, http.MethodPost, "/login", fmt.Sprintf(`{"login":"testuser","password":"password123","code":%q}`, code), nil)
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("expected status 401 on reused code, got %d", w.Code)
+		}
+	})
+
+	t.Run("same code submitted concurrently logs in once", func(t *testing.T) {
+		t.Parallel()
+
+		store := &readBarrierStore{memoryUserStore: newMemoryUserStore()}
+		domain := auth.NewWithStore(store, newMemorySessionStorage(), "session", nil, nil, nil)
+		secret := enroll(t, domain)
+
+		// both logins read the secret before either of them uses the step of the code
+		store.barrier.Add(2)
+		code := totpCode(t, secret, time.Now().Add(30*time.Second))
+
+		codes := make(chan int, 2)
+		for range 2 {
+			go func() {
+				codes <- serveAuth(domain, http.MethodPost, "/login", fmt.Sprintf(`{"login":"testuser","password":"password123","code":%q}`, code), nil).Code
+			}()
+		}
+
+		statuses := map[int]int{}
+		for range 2 {
+			statuses[<-codes]++
+		}
+
+		if statuses[http.StatusOK] != 1 || statuses[http.StatusUnauthorized] != 1 {
+			t.Fatalf("expected one login to succeed and one to fail with 401, got: %v", statuses)
+		}
+	})
+
+	t.Run("missing, invalid and expired codes are rejected", func(t *testing.T) {
+		t.Parallel()
+
+		domain := auth.NewWithStore(newMemoryUserStore(), newMemorySessionStorage(), "session", nil, nil, nil)
+		secret := enroll(t, domain)
+
+		codes := map[string]string{
+			"missing": "",
+			"invalid": "not-a-code",
+			"expired": totpCode(t, secret, time.Now().Add(-5*time.Minute)),
+		}
+
+		for name, code := range codes {
+			w := serveAuth(domain, http.MethodPost, "/login", fmt.Sprintf(`{"login":"testuser","password":"password123","code":%q}`, code), nil)
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("expected status 401 on %s code, got %d", name, w.Code)
+			}
+
+			for _, cookie := range w.Result().Cookies() {
+				if cookie.Name == "session" {
+					t.Fatalf("expected no session cookie on %s code", name)
+				}
+			}
+		}
+	})
+
+	t.Run("enrolling again with totp enabled conflicts", func(t *testing.T) {
+		t.Parallel()
+
+		domain := auth.NewWithStore(newMemoryUserStore(), newMemorySessionStorage(), "session", nil, nil, nil)
+		secret := enroll(t, domain)
+
+		code := totpCode(t, secret, time.Now().Add(30*time.Second))
+		cookie := sessionCookie(t, serveAuth(domain, http.MethodPost, "/login", fmt.Sprintf(`{"login":"testuser","password":"password123","code":%q}`, code), nil))
+
+		w := serveAuth(domain, http.MethodPost, "/totp/enroll", "", cookie)
+		if w.Code != http.StatusConflict {
+			t.Fatalf("expected status 409, got %d", w.Code)
+		}
+	})
+}
+
+// readBarrierStore holds each TOTP secret read until as many reads as added to barrier happened.
+type readBarrierStore struct {
+	*memoryUserStore
+
+	barrier sync.WaitGroup
+}
+
+func (s *readBarrierStore) GetTOTPSecret(ctx context.Context, userID string) (*auth.TOTPSecret, error) {
+	secret, err := s.memoryUserStore.GetTOTPSecret(ctx, userID)
+	if secret != nil && secret.Enabled {
+		s.barrier.Done()
+		s.barrier.Wait()
+	}
+	return secret, err
+}
+
+func totpCode(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+
+	code, err := auth.TOTPCode(secret, at)
+	if err != nil {
+		t.Fatalf("failed to compute totp code: %v", err)
+	}
+
+	return code
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrInvalidBatch is returned by BatchInsert when columns are missing or a row doesn't match them.
+var ErrInvalidBatch = errors.New("invalid batch insert")
+
+// maxBindParams is the maximum number of bind parameters in a PostgreSQL statement.
+const maxBindParams = 65535
+
+// BatchInsert inserts rows into table with multi-row INSERT statements, e.g. to seed reference data.
+// Each row holds one value per column. Table and column names are quoted, a table may be schema-qualified
+// as "schema.table". Rows are split into several statements if they exceed the bind parameter limit of
+// the driver; all statements run in one transaction, so either all rows are inserted or none.
+// Each statement is limited by the query timeout. Inserting no rows is a no-op.
+func (db *Database) BatchInsert(ctx context.Context, table string, columns []string, rows [][]any) error {
+	if len(columns) == 0 {
+		return fmt.Errorf("%w: no columns for table %s", ErrInvalidBatch, table)
+	}
+
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			return fmt.Errorf("%w: row %d has %d values, expected %d", ErrInvalidBatch, i, len(row), len(columns))
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // no-op after commit
+
+	rowsPerStatement := maxBindParams / len(columns)
+	for start := 0; start < len(rows); start += rowsPerStatement {
+		chunk := rows[start:min(start+rowsPerStatement, len(rows))]
+		query, args := batchInsertQuery(table, columns, chunk)
+
+		if err := db.execBatch(ctx, tx, tx.Rebind(query), args); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit batch insert: %w", err)
+	}
+
+	return nil
+}
+
+func (db *Database) execBatch(ctx context.Context, tx *sqlx.Tx, query string, args []any) error {
+	ctx, cancel := db.repo.withQueryTimeout(ctx)
+	defer cancel()
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to insert rows: %w", err)
+	}
+
+	return nil
+}
+
+// batchInsertQuery builds a multi-row INSERT with "?" placeholders, to be rebound to the driver's syntax.
+func batchInsertQuery(table string, columns []string, rows [][]any) (string, []any) {
+	quotedColumns := make([]string, len(columns))
+	for i, column := range columns {
+		quotedColumns[i] = quoteIdentifier(column)
+	}
+
+	placeholders := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+
+	var query strings.Builder
+	query.WriteString("INSERT INTO " + quoteQualifiedIdentifier(table))
+	query.WriteString(" (" + strings.Join(quotedColumns, ", ") + ") VALUES ")
+
+	args := make([]any, 0, len(rows)*len(columns))
+	for i, row := range rows {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString(placeholders)
+		args = append(args, row...)
+	}
+
+	return query.String(), args
+}
+
+// quoteQualifiedIdentifier quotes each part of a possibly schema-qualified name such as "public.users".
+func quoteQualifiedIdentifier(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = quoteIdentifier(part)
+	}
+
+	return strings.Join(parts, ".")
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -3,37 +3,70 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/platforma-dev/platforma/log"
 )
 
 // Database represents a database connection with migration capabilities.
 type Database struct {
 	conn         *sqlx.DB
+	replicas     []*sqlx.DB
+	nextReplica  atomic.Uint64
+	repo         *repository
 	repositories map[string]any
 	migrators    map[string]migrator
 	service      *service
+	closeOnce    sync.Once
+	closeErr     error
 }
 
 // New creates a new Database instance with the given connection string.
+// The password is masked in the returned error if the driver includes the connection string.
 func New(connection string) (*Database, error) {
 	db, err := sqlx.Connect("postgres", connection)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+		return nil, newConnectError(connection, err)
 	}
 
-	repository := newRepository(db)
+	return NewFromDB(db), nil
+}
+
+// NewFromDB creates a new Database instance from open connection pools, e.g. ones created with
+// a custom driver configuration. Writes and migrations run on primary, see NewWithReplicas for replicas.
+func NewFromDB(primary *sqlx.DB, replicas ...*sqlx.DB) *Database {
+	repository := newRepository(primary)
 	service := newService(repository)
-	return &Database{conn: db, repositories: make(map[string]any), migrators: make(map[string]migrator), service: service}, nil
+	return &Database{conn: primary, replicas: replicas, repo: repository, repositories: make(map[string]any), migrators: make(map[string]migrator), service: service}
 }
 
-// Connection returns the underlying sqlx database connection.
+// Connection returns the underlying sqlx database connection of the primary.
 func (db *Database) Connection() *sqlx.DB {
 	return db.conn
 }
 
+// Close closes the underlying database connection pools of the primary and replicas.
+// It is safe to call Close multiple times; subsequent calls return the result of the first one.
+func (db *Database) Close() error {
+	db.closeOnce.Do(func() {
+		for _, conn := range append([]*sqlx.DB{db.conn}, db.replicas...) {
+			if err := conn.Close(); err != nil {
+				db.closeErr = errors.Join(db.closeErr, fmt.Errorf("failed to close database connection: %w", err))
+			}
+		}
+	})
+
+	return db.closeErr
+}
+
 // RegisterRepository registers a repository in the database.
 // If repository implements migrator interface, it will migrate when `Migrate` is called.
 func (db *Database) RegisterRepository(name string, repository any) {
@@ -44,8 +77,21 @@ func (db *Database) RegisterRepository(name string, repository any) {
 	}
 }
 
+// SetMigrationRollback sets what Migrate undoes when a migration fails. Defaults to MigrationRevertAll.
+func (db *Database) SetMigrationRollback(mode MigrationRollbackMode) {
+	db.service.rollbackMode = mode
+}
+
+// SetMigrationContinueOnError makes Migrate attempt every repository instead of stopping at the first failure,
+// e.g. in development. The migrations of a failing repository are still rolled back as set by
+// SetMigrationRollback, and the errors of all failed repositories are joined. Disabled by default.
+func (db *Database) SetMigrationContinueOnError(continueOnError bool) {
+	db.service.continueOnError = continueOnError
+}
+
 // Migrate runs all pending migrations for registered repositories.
-func (db *Database) Migrate(ctx context.Context) error {
+// opts are applied when parsing the migrations, e.g. WithVariables to substitute ${NAME} references.
+func (db *Database) Migrate(ctx context.Context, opts ...ParseOption) error {
 	// Ensure that migration table exists
 	err := db.service.migrateSelf(ctx)
 	if err != nil {
@@ -60,15 +106,22 @@ func (db *Database) Migrate(ctx context.Context) error {
 
 	// Get migrations from all migrators
 	migrations := []Migration{}
+	var parseErrs error
 	for name, migrator := range db.migrators {
-		parsed, err := ParseMigrations(migrator.Migrations())
+		parsed, err := parseRepositoryMigrations(name, migrator, opts)
 		if err != nil {
-			return fmt.Errorf("failed to parse migrations for %s: %w", name, err)
-		}
-		for _, migr := range parsed {
-			migr.repository = name
-			migrations = append(migrations, migr)
+			if !db.service.continueOnError {
+				return err
+			}
+			log.ErrorContext(ctx, "failed to parse repository migrations, continuing", "repository", name, "error", err)
+			parseErrs = errors.Join(parseErrs, err)
+			continue
 		}
+		migrations = append(migrations, parsed...)
+	}
+
+	if db.service.continueOnError {
+		return errors.Join(parseErrs, db.service.applyRepositoryMigrations(ctx, migrations, migrationLogs))
 	}
 
 	err = db.service.applyMigrations(ctx, migrations, migrationLogs)
@@ -78,3 +131,54 @@ func (db *Database) Migrate(ctx context.Context) error {
 
 	return nil
 }
+
+// parseRepositoryMigrations parses the migrations of the repository registered under name.
+func parseRepositoryMigrations(name string, m migrator, opts []ParseOption) ([]Migration, error) {
+	parsed, err := ParseMigrations(m.Migrations(), opts...)
+	if err != nil {
+		var parseErr *ErrMigrationParse
+		if errors.As(err, &parseErr) {
+			parseErr.Repository = name
+			return nil, parseErr
+		}
+		return nil, fmt.Errorf("failed to parse migrations for %s: %w", name, err)
+	}
+
+	for i := range parsed {
+		parsed[i].repository = name
+	}
+
+	return parsed, nil
+}
+
+// RunSQLFiles executes every .sql file in the root of fsys, ordered lexicographically by filename.
+// Unlike migrations, files are not recorded in the migrations table and run on every call,
+// so they should be idempotent, e.g. CREATE EXTENSION IF NOT EXISTS. It stops at the first failing file.
+func (db *Database) RunSQLFiles(ctx context.Context, fsys fs.FS) error {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return fmt.Errorf("failed to read sql files directory: %w", err)
+	}
+
+	var filenames []string
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".sql" {
+			filenames = append(filenames, entry.Name())
+		}
+	}
+
+	slices.Sort(filenames)
+
+	for _, filename := range filenames {
+		query, err := fs.ReadFile(fsys, filename)
+		if err != nil {
+			return fmt.Errorf("failed to read sql file %s: %w", filename, err)
+		}
+
+		if _, err := db.ExecContext(ctx, string(query)); err != nil {
+			return fmt.Errorf("failed to execute sql file %s: %w", filename, err)
+		}
+	}
+
+	return nil
+}
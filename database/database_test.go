@@ -4,13 +4,19 @@ package database_test
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"io/fs"
 	"slices"
+	"strings"
 	"testing"
 	"testing/fstest"
 	"time"
 
+	"github.com/jmoiron/sqlx"
+	"github.com/platforma-dev/platforma/auth"
 	"github.com/platforma-dev/platforma/database"
+	"github.com/platforma-dev/platforma/session"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 )
 
@@ -184,6 +190,54 @@ func TestMigrate(t *testing.T) {
 		}
 	})
 
+	// Two Database instances pointing at the same physical database share the migrations table,
+	// so the second one sees the logs of the first and skips already applied migrations
+	t.Run("migrate same repository through two databases sharing one physical database", func(t *testing.T) {
+		t.Cleanup(func() {
+			err = ctr.Restore(ctx)
+			if err != nil {
+				t.Fatalf("failed to restore db: %s", err.Error())
+			}
+		})
+
+		for range 2 {
+			db, err := database.New(dbURL)
+			if err != nil {
+				t.Fatalf("failed to initialize database: %s", err.Error())
+			}
+
+			// fails if applied twice
+			db.RegisterRepository("some_repo", simpleRepo{fsys: migrationFS(database.Migration{
+				ID:   "001_init",
+				Up:   "CREATE TABLE shared_repo (id TEXT)",
+				Down: "DROP TABLE shared_repo",
+			})})
+
+			err = db.Migrate(ctx)
+			if err != nil {
+				t.Fatalf("failed to migrate database: %s", err.Error())
+			}
+
+			db.Close()
+		}
+
+		db, err := database.New(dbURL)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+		defer db.Close()
+
+		var repoLogs int
+		err = db.Connection().GetContext(ctx, &repoLogs, "SELECT count(*) FROM platforma_migrations WHERE repository = 'some_repo'")
+		if err != nil {
+			t.Fatalf("expected no errors, got: %s", err.Error())
+		}
+
+		if repoLogs != 1 {
+			t.Fatalf("expected single migration log for some_repo, got: %d", repoLogs)
+		}
+	})
+
 	t.Run("migrate database with multiple repositories", func(t *testing.T) {
 		t.Cleanup(func() {
 			err = ctr.Restore(ctx)
@@ -252,6 +306,69 @@ func TestMigrate(t *testing.T) {
 		}
 	})
 
+	t.Run("migrate database with unparsable migration", func(t *testing.T) {
+		t.Cleanup(func() {
+			err = ctr.Restore(ctx)
+			if err != nil {
+				t.Fatalf("failed to restore db: %s", err.Error())
+			}
+		})
+
+		db, err := database.New(dbURL)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+
+		db.RegisterRepository("some_repo", simpleRepo{fsys: fstest.MapFS{
+			"001_init.sql": &fstest.MapFile{Data: []byte("CREATE TABLE simple_repo (id TEXT)")},
+		}})
+
+		err = db.Migrate(ctx)
+
+		var parseErr *database.ErrMigrationParse
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("expected ErrMigrationParse, got: %v", err)
+		}
+
+		if parseErr.Repository != "some_repo" || parseErr.File != "001_init.sql" {
+			t.Fatalf("expected some_repo/001_init.sql, got: %s/%s", parseErr.Repository, parseErr.File)
+		}
+	})
+
+	t.Run("migrate database with variables", func(t *testing.T) {
+		t.Cleanup(func() {
+			err = ctr.Restore(ctx)
+			if err != nil {
+				t.Fatalf("failed to restore db: %s", err.Error())
+			}
+		})
+
+		db, err := database.New(dbURL)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+
+		db.RegisterRepository("some_repo", simpleRepo{fsys: migrationFS(database.Migration{
+			ID: "001_init",
+			Up: "CREATE TABLE ${TABLE} (id TEXT)",
+		})})
+
+		err = db.Migrate(ctx, database.WithVariables(map[string]string{}))
+		if !errors.Is(err, database.ErrMissingMigrationVariable) {
+			t.Fatalf("expected missing variable error, got: %v", err)
+		}
+
+		err = db.Migrate(ctx, database.WithVariables(map[string]string{"TABLE": "tenant_repo"}))
+		if err != nil {
+			t.Fatalf("failed to migrate database: %s", err.Error())
+		}
+
+		_, err = db.Connection().ExecContext(ctx, "SELECT * FROM tenant_repo")
+		if err != nil {
+			t.Fatalf("expected no errors, got: %s", err.Error())
+		}
+	})
+
 	t.Run("migrate database with failing migration", func(t *testing.T) {
 		t.Cleanup(func() {
 			err = ctr.Restore(ctx)
@@ -294,6 +411,15 @@ func TestMigrate(t *testing.T) {
 		}
 		t.Logf("migration error: %s", err.Error())
 
+		var applyErr *database.ErrMigrationApply
+		if !errors.As(err, &applyErr) {
+			t.Fatalf("expected ErrMigrationApply, got: %T", err)
+		}
+
+		if applyErr.Repository != "other_repo" || applyErr.ID != "002_failing" {
+			t.Fatalf("expected failing migration other_repo/002_failing, got: %s/%s", applyErr.Repository, applyErr.ID)
+		}
+
 		var migrationLogs []migrationLog
 		err = db.Connection().SelectContext(ctx, &migrationLogs, "SELECT * FROM platforma_migrations")
 		if err != nil {
@@ -337,6 +463,143 @@ func TestMigrate(t *testing.T) {
 		}
 	})
 
+	t.Run("migrate database with failing repository and continue on error", func(t *testing.T) {
+		t.Cleanup(func() {
+			err = ctr.Restore(ctx)
+			if err != nil {
+				t.Fatalf("failed to restore db: %s", err.Error())
+			}
+		})
+
+		db, err := database.New(dbURL)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+		defer db.Close()
+
+		db.SetMigrationContinueOnError(true)
+
+		db.RegisterRepository("some_repo", simpleRepo{fsys: migrationFS(database.Migration{
+			ID:   "001_init",
+			Up:   "CREATE TABLE IF NOT EXISTS simple_repo (id TEXT)",
+			Down: "DROP TABLE simple_repo",
+		})})
+
+		db.RegisterRepository("other_repo", simpleRepo{fsys: migrationFS(
+			database.Migration{
+				ID:   "001_init",
+				Up:   "CREATE TABLE IF NOT EXISTS other_repo (id TEXT)",
+				Down: "DROP TABLE other_repo",
+			},
+			database.Migration{
+				ID:   "002_failing",
+				Up:   "not even SQL here",
+				Down: "no need for this",
+			},
+		)})
+
+		err = db.Migrate(ctx)
+
+		var applyErr *database.ErrMigrationApply
+		if !errors.As(err, &applyErr) {
+			t.Fatalf("expected ErrMigrationApply, got: %v", err)
+		}
+
+		if applyErr.Repository != "other_repo" || applyErr.ID != "002_failing" {
+			t.Fatalf("expected failing migration other_repo/002_failing, got: %s/%s", applyErr.Repository, applyErr.ID)
+		}
+
+		var migrationLogs []migrationLog
+		err = db.Connection().SelectContext(ctx, &migrationLogs, "SELECT * FROM platforma_migrations")
+		if err != nil {
+			t.Fatalf("expected no errors, got: %s", err.Error())
+		}
+
+		if !slices.ContainsFunc(migrationLogs, func(log migrationLog) bool {
+			return log.Repository == "some_repo" && log.MigrationID == "001_init"
+		}) {
+			t.Fatalf("expected succeeding repository to be migrated, got: %v", migrationLogs)
+		}
+
+		if _, err := db.Connection().ExecContext(ctx, "SELECT * FROM simple_repo"); err != nil {
+			t.Fatalf("expected simple_repo table, got: %s", err.Error())
+		}
+
+		// because the failing repository's migrations should be reverted
+		if slices.ContainsFunc(migrationLogs, func(log migrationLog) bool {
+			return log.Repository == "other_repo"
+		}) {
+			t.Fatalf("expected no migration logs for other_repo, got: %v", migrationLogs)
+		}
+
+		if _, err := db.Connection().ExecContext(ctx, "SELECT * FROM other_repo"); err == nil {
+			t.Fatalf("expected other_repo table to be reverted")
+		}
+	})
+
+	t.Run("migrate database with mid-set failure by rollback mode", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			mode        database.MigrationRollbackMode
+			wantApplied []string
+		}{
+			{name: "revert all", mode: database.MigrationRevertAll, wantApplied: nil},
+			{name: "rollback failed", mode: database.MigrationRollbackFailed, wantApplied: []string{"001_first", "002_second"}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Cleanup(func() {
+					err = ctr.Restore(ctx)
+					if err != nil {
+						t.Fatalf("failed to restore db: %s", err.Error())
+					}
+				})
+
+				db, err := database.New(dbURL)
+				if err != nil {
+					t.Fatalf("failed to initialize database: %s", err.Error())
+				}
+				defer db.Close()
+
+				db.SetMigrationRollback(tt.mode)
+				db.RegisterRepository("some_repo", simpleRepo{fsys: migrationFS(
+					database.Migration{ID: "001_first", Up: "CREATE TABLE first (id TEXT)", Down: "DROP TABLE first"},
+					database.Migration{ID: "002_second", Up: "CREATE TABLE second (id TEXT)", Down: "DROP TABLE second"},
+					database.Migration{ID: "003_failing", Up: "CREATE TABLE third (id TEXT); not even SQL here", Down: "DROP TABLE third"},
+				)})
+
+				err = db.Migrate(ctx)
+
+				var applyErr *database.ErrMigrationApply
+				if !errors.As(err, &applyErr) || applyErr.ID != "003_failing" {
+					t.Fatalf("expected ErrMigrationApply for 003_failing, got: %v", err)
+				}
+
+				var applied []string
+				err = db.Connection().SelectContext(ctx, &applied, "SELECT id FROM platforma_migrations WHERE repository = 'some_repo' ORDER BY id")
+				if err != nil {
+					t.Fatalf("expected no errors, got: %s", err.Error())
+				}
+
+				if !slices.Equal(applied, tt.wantApplied) {
+					t.Fatalf("expected applied migrations %v, got: %v", tt.wantApplied, applied)
+				}
+
+				for _, table := range []string{"first", "second"} {
+					_, err = db.Connection().ExecContext(ctx, "SELECT * FROM "+table)
+					if exists := err == nil; exists != (len(tt.wantApplied) > 0) {
+						t.Fatalf("expected table %s to exist: %v, got error: %v", table, len(tt.wantApplied) > 0, err)
+					}
+				}
+
+				if _, err = db.Connection().ExecContext(ctx, "SELECT * FROM third"); err == nil {
+					t.Fatalf("expected failing migration to be rolled back")
+				}
+			})
+		}
+	})
+
 	t.Run("migrate database with failing migration and revert", func(t *testing.T) {
 		t.Cleanup(func() {
 			err = ctr.Restore(ctx)
@@ -418,6 +681,451 @@ func TestMigrate(t *testing.T) {
 	})
 }
 
+func TestMigrateSelf(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dbURL := startPostgres(t)
+
+	t.Run("initialize twice creates index and single init log", func(t *testing.T) {
+		for range 2 {
+			db, err := database.New(dbURL)
+			if err != nil {
+				t.Fatalf("failed to initialize database: %s", err.Error())
+			}
+
+			err = db.Migrate(ctx)
+			if err != nil {
+				t.Fatalf("failed to migrate database: %s", err.Error())
+			}
+
+			db.Close()
+		}
+
+		db, err := database.New(dbURL)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+		defer db.Close()
+
+		var initLogs int
+		err = db.Connection().GetContext(ctx, &initLogs, "SELECT count(*) FROM platforma_migrations WHERE repository = 'platforma_migration' AND id = 'init'")
+		if err != nil {
+			t.Fatalf("expected no errors, got: %s", err.Error())
+		}
+
+		if initLogs != 1 {
+			t.Fatalf("expected single init migration log, got: %d", initLogs)
+		}
+
+		var indexDef string
+		err = db.Connection().GetContext(ctx, &indexDef, "SELECT indexdef FROM pg_indexes WHERE tablename = 'platforma_migrations' AND indexname = 'platforma_migrations_repository_id_idx'")
+		if err != nil {
+			t.Fatalf("expected migrations index to exist, got: %s", err.Error())
+		}
+
+		if !strings.Contains(indexDef, "(repository, id)") {
+			t.Fatalf("expected index on (repository, id), got: %s", indexDef)
+		}
+	})
+
+	t.Run("initialize with existing table without log", func(t *testing.T) {
+		db, err := database.New(dbURL)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+		defer db.Close()
+
+		// simulate a table that was created but whose init log was never saved
+		_, err = db.Connection().ExecContext(ctx, "DELETE FROM platforma_migrations WHERE repository = 'platforma_migration'")
+		if err != nil {
+			t.Fatalf("expected no errors, got: %s", err.Error())
+		}
+
+		err = db.Migrate(ctx)
+		if err != nil {
+			t.Fatalf("failed to migrate database: %s", err.Error())
+		}
+
+		var initLogs int
+		err = db.Connection().GetContext(ctx, &initLogs, "SELECT count(*) FROM platforma_migrations WHERE repository = 'platforma_migration' AND id = 'init'")
+		if err != nil {
+			t.Fatalf("expected no errors, got: %s", err.Error())
+		}
+
+		if initLogs != 1 {
+			t.Fatalf("expected single init migration log, got: %d", initLogs)
+		}
+	})
+}
+
+func TestRunSQLFiles(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dbURL := startPostgres(t)
+
+	db, err := database.New(dbURL)
+	if err != nil {
+		t.Fatalf("failed to initialize database: %s", err.Error())
+	}
+	defer db.Close()
+
+	fsys := fstest.MapFS{
+		"002_table.sql":  &fstest.MapFile{Data: []byte("CREATE TABLE IF NOT EXISTS boot_items (id citext)")},
+		"001_ext.sql":    &fstest.MapFile{Data: []byte("CREATE EXTENSION IF NOT EXISTS citext")},
+		"readme.txt":     &fstest.MapFile{Data: []byte("not sql")},
+		"nested/003.sql": &fstest.MapFile{Data: []byte("SELECT broken")},
+	}
+
+	t.Run("executes files in order", func(t *testing.T) {
+		err := db.RunSQLFiles(ctx, fsys)
+		if err != nil {
+			t.Fatalf("expected no errors, got: %s", err.Error())
+		}
+
+		_, err = db.Connection().ExecContext(ctx, "INSERT INTO boot_items (id) VALUES ('A')")
+		if err != nil {
+			t.Fatalf("expected table with citext column, got: %s", err.Error())
+		}
+	})
+
+	t.Run("rerun is idempotent and not tracked", func(t *testing.T) {
+		err := db.RunSQLFiles(ctx, fsys)
+		if err != nil {
+			t.Fatalf("expected no errors, got: %s", err.Error())
+		}
+
+		var tracked bool
+		err = db.Connection().GetContext(ctx, &tracked, "SELECT to_regclass('platforma_migrations') IS NOT NULL")
+		if err != nil {
+			t.Fatalf("expected no errors, got: %s", err.Error())
+		}
+
+		if tracked {
+			t.Fatal("expected sql files not to create the migrations table")
+		}
+	})
+
+	t.Run("reports failing file", func(t *testing.T) {
+		err := db.RunSQLFiles(ctx, fstest.MapFS{
+			"001_broken.sql": &fstest.MapFile{Data: []byte("CREATE TABLE")},
+		})
+		if err == nil || !strings.Contains(err.Error(), "001_broken.sql") {
+			t.Fatalf("expected error mentioning failing file, got: %v", err)
+		}
+	})
+}
+
+func TestQueryTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dbURL := startPostgres(t)
+
+	db, err := database.New(dbURL)
+	if err != nil {
+		t.Fatalf("failed to initialize database: %s", err.Error())
+	}
+	defer db.Close()
+
+	db.SetQueryTimeout(100 * time.Millisecond)
+
+	t.Run("fast query succeeds", func(t *testing.T) {
+		var value int
+		err := db.GetContext(ctx, &value, "SELECT 1")
+		if err != nil {
+			t.Fatalf("expected no errors, got: %s", err.Error())
+		}
+
+		if value != 1 {
+			t.Fatalf("expected 1, got: %d", value)
+		}
+	})
+
+	t.Run("slow query is cancelled", func(t *testing.T) {
+		start := time.Now()
+
+		var values []string
+		err := db.SelectContext(ctx, &values, "SELECT pg_sleep(5)::text")
+		if err == nil {
+			t.Fatal("expected query to be cancelled")
+		}
+
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Fatalf("expected query to be cancelled by timeout, took %s", elapsed)
+		}
+	})
+
+	t.Run("slow migration is cancelled", func(t *testing.T) {
+		db.RegisterRepository("slow", simpleRepo{fsys: migrationFS(database.Migration{
+			ID: "001_slow",
+			Up: "SELECT pg_sleep(5);",
+		})})
+
+		var applyErr *database.ErrMigrationApply
+		err := db.Migrate(ctx)
+		if !errors.As(err, &applyErr) {
+			t.Fatalf("expected migration apply error, got: %v", err)
+		}
+	})
+}
+
+func TestBatchInsert(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dbURL := startPostgres(t)
+
+	db, err := database.New(dbURL)
+	if err != nil {
+		t.Fatalf("failed to initialize database: %s", err.Error())
+	}
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, `CREATE TABLE countries (code TEXT PRIMARY KEY, "name" TEXT NOT NULL, population BIGINT)`); err != nil {
+		t.Fatalf("failed to create table: %s", err.Error())
+	}
+
+	t.Run("inserts rows in one call", func(t *testing.T) {
+		err := db.BatchInsert(ctx, "public.countries", []string{"code", "name", "population"}, [][]any{
+			{"DE", "Germany", 84000000},
+			{"FR", "France", 68000000},
+			{"IS", "Iceland", nil},
+		})
+		if err != nil {
+			t.Fatalf("expected no errors, got: %s", err.Error())
+		}
+
+		var names []string
+		if err := db.SelectContext(ctx, &names, "SELECT name FROM countries ORDER BY code"); err != nil {
+			t.Fatalf("failed to select rows: %s", err.Error())
+		}
+
+		if !slices.Equal(names, []string{"Germany", "France", "Iceland"}) {
+			t.Fatalf("expected inserted countries, got: %v", names)
+		}
+	})
+
+	t.Run("splits rows over the bind parameter limit", func(t *testing.T) {
+		if _, err := db.ExecContext(ctx, "CREATE TABLE numbers (a INT, b INT)"); err != nil {
+			t.Fatalf("failed to create table: %s", err.Error())
+		}
+
+		rows := make([][]any, 40000)
+		for i := range rows {
+			rows[i] = []any{i, i * 2}
+		}
+
+		if err := db.BatchInsert(ctx, "numbers", []string{"a", "b"}, rows); err != nil {
+			t.Fatalf("expected no errors, got: %s", err.Error())
+		}
+
+		var count int
+		if err := db.GetContext(ctx, &count, "SELECT count(*) FROM numbers"); err != nil {
+			t.Fatalf("failed to count rows: %s", err.Error())
+		}
+
+		if count != len(rows) {
+			t.Fatalf("expected %d rows, got: %d", len(rows), count)
+		}
+	})
+
+	t.Run("failed batch inserts nothing", func(t *testing.T) {
+		err := db.BatchInsert(ctx, "countries", []string{"code", "name"}, [][]any{
+			{"ES", "Spain"},
+			{"ES", "Spain again"},
+		})
+		if err == nil {
+			t.Fatal("expected duplicate key error")
+		}
+
+		var count int
+		if err := db.GetContext(ctx, &count, "SELECT count(*) FROM countries WHERE code = 'ES'"); err != nil {
+			t.Fatalf("failed to count rows: %s", err.Error())
+		}
+
+		if count != 0 {
+			t.Fatalf("expected no rows from failed batch, got: %d", count)
+		}
+	})
+
+	t.Run("rejects rows that don't match columns", func(t *testing.T) {
+		err := db.BatchInsert(ctx, "countries", []string{"code", "name"}, [][]any{{"IT"}})
+		if !errors.Is(err, database.ErrInvalidBatch) {
+			t.Fatalf("expected invalid batch error, got: %v", err)
+		}
+	})
+}
+
+func TestClose(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dbURL := startPostgres(t)
+
+	t.Run("queries fail after close", func(t *testing.T) {
+		db, err := database.New(dbURL)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+
+		_, err = db.Connection().ExecContext(ctx, "SELECT 1")
+		if err != nil {
+			t.Fatalf("expected no errors, got: %s", err.Error())
+		}
+
+		err = db.Close()
+		if err != nil {
+			t.Fatalf("failed to close database: %s", err.Error())
+		}
+
+		_, err = db.Connection().ExecContext(ctx, "SELECT 1")
+		if err == nil {
+			t.Fatalf("expected error after close, got nil")
+		}
+	})
+
+	t.Run("double close is safe", func(t *testing.T) {
+		db, err := database.New(dbURL)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %s", err.Error())
+		}
+
+		err = db.Close()
+		if err != nil {
+			t.Fatalf("failed to close database: %s", err.Error())
+		}
+
+		err = db.Close()
+		if err != nil {
+			t.Fatalf("expected second close to succeed, got: %s", err.Error())
+		}
+	})
+}
+
+func TestRunInTx(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dbURL := startPostgres(t)
+
+	db, err := database.New(dbURL)
+	if err != nil {
+		t.Fatalf("failed to initialize database: %s", err.Error())
+	}
+	defer db.Close()
+
+	users := auth.NewRepository(db.Connection())
+	sessions := session.NewRepository(db.Connection())
+	db.RegisterRepository("users", users)
+	db.RegisterRepository("sessions", sessions)
+
+	if err := db.Migrate(ctx); err != nil {
+		t.Fatalf("expected no errors, got: %s", err.Error())
+	}
+
+	createUserWithSession := func(tx *sqlx.Tx, id string) error {
+		now := time.Now()
+		if err := users.WithTx(tx).Create(ctx, &auth.User{ID: id, Username: id, Created: now, Updated: now, Status: auth.StatusActive}); err != nil {
+			return err
+		}
+
+		return sessions.WithTx(tx).Create(ctx, &session.Session{ID: "session-" + id, User: id, Created: now, Expires: now.Add(time.Hour)})
+	}
+
+	t.Run("commits writes of several repositories", func(t *testing.T) {
+		err := db.RunInTx(ctx, func(tx *sqlx.Tx) error {
+			return createUserWithSession(tx, "committed")
+		})
+		if err != nil {
+			t.Fatalf("expected no errors, got: %s", err.Error())
+		}
+
+		if _, err := users.Get(ctx, "committed"); err != nil {
+			t.Fatalf("expected committed user, got: %s", err.Error())
+		}
+
+		if _, err := sessions.Get(ctx, "session-committed"); err != nil {
+			t.Fatalf("expected committed session, got: %s", err.Error())
+		}
+	})
+
+	t.Run("rolls back writes of several repositories on error", func(t *testing.T) {
+		errAbort := errors.New("abort")
+
+		err := db.RunInTx(ctx, func(tx *sqlx.Tx) error {
+			if err := createUserWithSession(tx, "rolled-back"); err != nil {
+				return err
+			}
+
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Fatalf("expected error of fn, got: %v", err)
+		}
+
+		if _, err := users.Get(ctx, "rolled-back"); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected rolled back user, got: %v", err)
+		}
+
+		if _, err := sessions.Get(ctx, "session-rolled-back"); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected rolled back session, got: %v", err)
+		}
+	})
+
+	t.Run("rolls back on panic", func(t *testing.T) {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected panic to propagate")
+				}
+			}()
+
+			_ = db.RunInTx(ctx, func(tx *sqlx.Tx) error {
+				if err := createUserWithSession(tx, "panicked"); err != nil {
+					return err
+				}
+
+				panic("boom")
+			})
+		}()
+
+		if _, err := users.Get(ctx, "panicked"); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected rolled back user, got: %v", err)
+		}
+	})
+}
+
+// startPostgres starts a PostgreSQL container for the test and returns its connection string.
+func startPostgres(t *testing.T) string {
+	t.Helper()
+
+	ctx := context.Background()
+	ctr, err := postgres.Run(
+		ctx,
+		"postgres:18-alpine",
+		postgres.WithDatabase("hostamat"),
+		postgres.WithUsername("hostamat"),
+		postgres.WithPassword("hostamat"),
+		postgres.BasicWaitStrategies(),
+	)
+	if err != nil {
+		t.Fatalf("failed to initialize database: %s", err.Error())
+	}
+
+	t.Cleanup(func() {
+		_ = ctr.Terminate(ctx)
+	})
+
+	dbURL, err := ctr.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		t.Fatalf("failed to get connection string: %s", err.Error())
+	}
+
+	return dbURL
+}
+
 type migrationLog struct {
 	Repository  string    `db:"repository"`
 	MigrationID string    `db:"id"`
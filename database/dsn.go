@@ -0,0 +1,61 @@
+package database
+
+import (
+	"net"
+	"net/url"
+)
+
+// Option configures a connection string built by DSN and NewFromParams.
+type Option func(*dsnOptions)
+
+type dsnOptions struct {
+	sslMode    string
+	searchPath string
+}
+
+// WithSSLMode sets the sslmode connection parameter, e.g. "disable" or "verify-full".
+func WithSSLMode(mode string) Option {
+	return func(o *dsnOptions) {
+		o.sslMode = mode
+	}
+}
+
+// WithSearchPath sets the search_path used by the connection.
+func WithSearchPath(searchPath string) Option {
+	return func(o *dsnOptions) {
+		o.searchPath = searchPath
+	}
+}
+
+// DSN builds a PostgreSQL connection URL from its components.
+// User, password and database name are URL-encoded, so they may contain any characters.
+func DSN(host, port, user, password, dbname string, opts ...Option) string {
+	var o dsnOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	query := url.Values{}
+	if o.sslMode != "" {
+		query.Set("sslmode", o.sslMode)
+	}
+	if o.searchPath != "" {
+		query.Set("search_path", o.searchPath)
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(user, password),
+		Host:     net.JoinHostPort(host, port),
+		Path:     "/" + dbname,
+		RawQuery: query.Encode(),
+	}
+
+	return dsn.String()
+}
+
+// NewFromParams creates a new Database instance from connection components.
+// Use New to connect with a raw connection string.
+func NewFromParams(host, port, user, password, dbname string, opts ...Option) (*Database, error) {
+	return New(DSN(host, port, user, password, dbname, opts...))
+}
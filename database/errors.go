@@ -0,0 +1,46 @@
+package database
+
+import "fmt"
+
+// ErrMigrationParse is returned when a migration file cannot be parsed.
+type ErrMigrationParse struct {
+	// Repository is the name of the repository the migrations were registered with.
+	// It is empty for errors returned by ParseMigrations.
+	Repository string
+	// File is the name of the migration file.
+	File string
+	Err  error
+}
+
+// Error returns the formatted error message for ErrMigrationParse.
+func (e *ErrMigrationParse) Error() string {
+	if e.Repository == "" {
+		return fmt.Sprintf("failed to parse migration %s: %v", e.File, e.Err)
+	}
+
+	return fmt.Sprintf("failed to parse migration %s of %s: %v", e.File, e.Repository, e.Err)
+}
+
+// Unwrap returns the underlying error for ErrMigrationParse.
+func (e *ErrMigrationParse) Unwrap() error {
+	return e.Err
+}
+
+// ErrMigrationApply is returned when the Up statement of a migration fails.
+type ErrMigrationApply struct {
+	// Repository is the name of the repository the migration belongs to.
+	Repository string
+	// ID is the migration ID.
+	ID  string
+	Err error
+}
+
+// Error returns the formatted error message for ErrMigrationApply.
+func (e *ErrMigrationApply) Error() string {
+	return fmt.Sprintf("failed to apply migration %s of %s: %v", e.ID, e.Repository, e.Err)
+}
+
+// Unwrap returns the underlying error for ErrMigrationApply.
+func (e *ErrMigrationApply) Unwrap() error {
+	return e.Err
+}
@@ -13,12 +13,28 @@ type migrationLog struct {
 
 // Migration represents a database migration with up and down SQL statements.
 type Migration struct {
-	ID         string
-	Up         string
-	Down       string
+	ID   string
+	Up   string
+	Down string
+	// Order is the explicit position set with the -- +migrate Order: N marker, or 0 if the file has none.
+	Order      int
 	repository string
 }
 
+// MigrationRollbackMode defines what Migrate undoes when a migration fails.
+type MigrationRollbackMode int
+
+const (
+	// MigrationRevertAll reverts the migrations applied earlier in the same Migrate call
+	// with their Down statements, so that a failed Migrate leaves no new migrations applied.
+	// It is the default.
+	MigrationRevertAll MigrationRollbackMode = iota
+	// MigrationRollbackFailed runs the migrations in one transaction with a savepoint before each
+	// of them. Only the failing migration is rolled back; migrations applied before it are committed
+	// and logged. Migrations must be able to run in a transaction, e.g. no CREATE INDEX CONCURRENTLY.
+	MigrationRollbackFailed
+)
+
 type migrator interface {
 	Migrations() fs.FS
 }
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrInvalidMigrationName is returned by NewMigrationFile when the name is empty
+// or contains characters other than letters, digits, spaces, dashes and underscores.
+var ErrInvalidMigrationName = errors.New("invalid migration name")
+
+const migrationTimestampLayout = "20060102150405"
+
+const migrationFileTemplate = markerUp + `
+-- SQL in this section is executed when the migration is applied.
+
+` + markerDown + `
+-- SQL in this section is executed when the migration is reverted.
+`
+
+// NewMigrationFile scaffolds a migration in dir named YYYYMMDDHHMMSS_name.sql with the current UTC time,
+// so that files sort in creation order, and returns its path. The file contains empty Up and Down sections.
+// Spaces and dashes in name are replaced with underscores and letters are lowercased, e.g. "Add users"
+// becomes "add_users". An existing file is never overwritten.
+func NewMigrationFile(dir, name string) (string, error) {
+	normalized, err := normalizeMigrationName(name)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, time.Now().UTC().Format(migrationTimestampLayout)+"_"+normalized+".sql")
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644) //nolint:gosec // migrations are not secret
+	if err != nil {
+		return "", fmt.Errorf("failed to create migration file: %w", err)
+	}
+
+	if _, err := file.WriteString(migrationFileTemplate); err != nil {
+		_ = file.Close()
+		return "", fmt.Errorf("failed to write migration file: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write migration file: %w", err)
+	}
+
+	return path, nil
+}
+
+// normalizeMigrationName converts name to the lowercase snake case used in migration filenames.
+func normalizeMigrationName(name string) (string, error) {
+	normalized := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		case r == ' ' || r == '-':
+			return '_'
+		default:
+			return -1
+		}
+	}, strings.TrimSpace(name))
+
+	if normalized == "" || len(normalized) != len(strings.TrimSpace(name)) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidMigrationName, name)
+	}
+
+	return normalized, nil
+}
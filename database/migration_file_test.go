@@ -0,0 +1,119 @@
+package database_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/platforma-dev/platforma/database"
+)
+
+func TestNewMigrationFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("creates timestamped file with markers", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		before := time.Now().UTC().Truncate(time.Second)
+
+		path, err := database.NewMigrationFile(dir, "Add users-table")
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		if filepath.Dir(path) != dir {
+			t.Fatalf("expected file in %s, got %s", dir, path)
+		}
+
+		filename := filepath.Base(path)
+		if !regexp.MustCompile(`^\d{14}_add_users_table\.sql$`).MatchString(filename) {
+			t.Fatalf("expected YYYYMMDDHHMMSS_add_users_table.sql, got %s", filename)
+		}
+
+		created, err := time.Parse("20060102150405", filename[:14])
+		if err != nil {
+			t.Fatalf("expected timestamp prefix, got %s: %v", filename, err)
+		}
+		if created.Before(before) || created.After(time.Now().UTC()) {
+			t.Fatalf("expected current UTC timestamp, got %s", created)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read file: %v", err)
+		}
+
+		up := strings.Index(string(content), "-- +migrate Up\n")
+		down := strings.Index(string(content), "-- +migrate Down\n")
+		if up != 0 || down <= up {
+			t.Fatalf("expected Up marker followed by Down marker, got:\n%s", content)
+		}
+	})
+
+	t.Run("scaffolded file parses once filled in", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path, err := database.NewMigrationFile(dir, "create_posts")
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		content, _ := os.ReadFile(path)
+		content = []byte(strings.Replace(string(content), "-- +migrate Down\n", "CREATE TABLE posts (id INT);\n-- +migrate Down\n", 1) + "DROP TABLE posts;\n")
+		if err := os.WriteFile(path, content, 0o600); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+
+		migrations, err := database.ParseMigrations(os.DirFS(dir))
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		if len(migrations) != 1 || migrations[0].ID != strings.TrimSuffix(filepath.Base(path), ".sql") {
+			t.Fatalf("expected migration with the file ID, got %+v", migrations)
+		}
+		if !strings.HasSuffix(migrations[0].Up, "CREATE TABLE posts (id INT);") || !strings.HasSuffix(migrations[0].Down, "DROP TABLE posts;") {
+			t.Fatalf("expected filled in sections, got %+v", migrations[0])
+		}
+	})
+
+	t.Run("rejects invalid names", func(t *testing.T) {
+		t.Parallel()
+
+		for _, name := range []string{"", "   ", "../escape", "users;drop", "naïve"} {
+			if _, err := database.NewMigrationFile(t.TempDir(), name); !errors.Is(err, database.ErrInvalidMigrationName) {
+				t.Fatalf("expected invalid name error for %q, got: %v", name, err)
+			}
+		}
+	})
+
+	t.Run("does not overwrite existing file", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		for {
+			first, err := database.NewMigrationFile(dir, "same")
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			_, err = database.NewMigrationFile(dir, "same")
+			if err == nil {
+				// the clock ticked between the calls, so the files got different timestamps
+				_ = os.Remove(first)
+				continue
+			}
+
+			if !errors.Is(err, os.ErrExist) {
+				t.Fatalf("expected file exists error, got: %v", err)
+			}
+			return
+		}
+	})
+}
@@ -6,23 +6,73 @@ import (
 	"fmt"
 	"io/fs"
 	"path/filepath"
+	"regexp"
 	"slices"
+	"strconv"
 	"strings"
+
+	"github.com/platforma-dev/platforma/log"
 )
 
 const (
-	markerUp   = "-- +migrate Up"
-	markerDown = "-- +migrate Down"
-	markerID   = "-- +migrate ID:"
+	markerUp    = "-- +migrate Up"
+	markerDown  = "-- +migrate Down"
+	markerID    = "-- +migrate ID:"
+	markerOrder = "-- +migrate Order:"
+
+	markerAllowDestructive = "-- +migrate AllowDestructive"
 )
 
+// ErrDestructiveMigration is returned in strict lint mode when an Up section drops or truncates a table
+// and the file does not carry the -- +migrate AllowDestructive marker.
+var ErrDestructiveMigration = errors.New("destructive statement in Up section without AllowDestructive marker")
+
+// ErrMissingMigrationVariable is returned when a migration references a ${NAME} variable
+// that is not in the map passed to WithVariables.
+var ErrMissingMigrationVariable = errors.New("missing migration variable")
+
+var destructiveStatement = regexp.MustCompile(`(?i)\b(DROP\s+TABLE|TRUNCATE)\b`)
+
+var migrationVariable = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
 var (
 	errMissingUpSection  = errors.New("missing or empty Up section")
 	errEmptyIDOverride   = errors.New("empty ID override")
 	errDuplicateIDMarker = errors.New("duplicate ID override marker")
 	errIDMarkerNotFirst  = errors.New("ID override marker must be the first marker")
+	errInvalidOrder      = errors.New("order must be a positive integer")
+	errDuplicateOrder    = errors.New("duplicate Order marker")
+	errOrderInUse        = errors.New("order is used by another migration")
 )
 
+// ParseOption configures ParseMigrations.
+type ParseOption func(*parseOptions)
+
+type parseOptions struct {
+	destructiveLint bool
+	strict          bool
+	variables       map[string]string
+}
+
+// WithDestructiveLint flags Up sections containing DROP TABLE or TRUNCATE statements
+// unless the file carries the -- +migrate AllowDestructive marker.
+// Flagged migrations are logged as warnings, or rejected with ErrDestructiveMigration when strict is true.
+func WithDestructiveLint(strict bool) ParseOption {
+	return func(o *parseOptions) {
+		o.destructiveLint = true
+		o.strict = strict
+	}
+}
+
+// WithVariables replaces ${NAME} references in Up and Down sections with values from variables,
+// e.g. environment-specific tablespace or role names. A reference to a variable missing from the map
+// fails with ErrMissingMigrationVariable. Without this option migrations are left unchanged.
+func WithVariables(variables map[string]string) ParseOption {
+	return func(o *parseOptions) {
+		o.variables = variables
+	}
+}
+
 // ParseMigrations parses SQL migration files from an fs.FS.
 // Files must have .sql extension and contain -- +migrate Up marker.
 // The -- +migrate Down marker is optional.
@@ -30,8 +80,17 @@ var (
 // unless overridden with -- +migrate ID: <custom_id> as the first marker.
 // Only one ID override marker is allowed and it must appear before any other markers.
 // Returns an error if ID marker appears after Up/Down markers or if multiple ID markers exist.
-// Migrations are returned sorted lexicographically by filename.
-func ParseMigrations(fsys fs.FS) ([]Migration, error) {
+// Migrations are returned sorted lexicographically by filename, so numeric prefixes must be zero-padded
+// (001_, 002_, ...), unless files carry a -- +migrate Order: N marker with a positive N. Migrations with
+// the marker are sorted by N and come before migrations without it. Two files with the same N are rejected.
+// Destructive statements are only checked when WithDestructiveLint is passed,
+// and variables are only substituted when WithVariables is passed.
+func ParseMigrations(fsys fs.FS, opts ...ParseOption) ([]Migration, error) {
+	var o parseOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	entries, err := fs.ReadDir(fsys, ".")
 	if err != nil {
 		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
@@ -52,26 +111,118 @@ func ParseMigrations(fsys fs.FS) ([]Migration, error) {
 
 	migrations := make([]Migration, 0, len(filenames))
 	for _, filename := range filenames {
-		migration, err := parseMigrationFile(fsys, filename)
+		migration, allowDestructive, err := parseMigrationFile(fsys, filename)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse migration %s: %w", filename, err)
+			return nil, &ErrMigrationParse{File: filename, Err: err}
+		}
+
+		if o.variables != nil {
+			if migration, err = substituteVariables(migration, o.variables); err != nil {
+				return nil, &ErrMigrationParse{File: filename, Err: err}
+			}
+		}
+
+		if o.destructiveLint && !allowDestructive {
+			if statement := findDestructiveStatement(migration.Up); statement != "" {
+				if o.strict {
+					return nil, &ErrMigrationParse{File: filename, Err: fmt.Errorf("%w: %s", ErrDestructiveMigration, statement)}
+				}
+				log.Warn("destructive statement in migration", "migrationId", migration.ID, "statement", statement)
+			}
 		}
 		migrations = append(migrations, migration)
 	}
 
+	if err := sortMigrations(migrations, filenames); err != nil {
+		return nil, err
+	}
+
 	return migrations, nil
 }
 
-func parseMigrationFile(fsys fs.FS, filename string) (Migration, error) {
+// sortMigrations stably sorts migrations parsed from filenames by their Order, keeping migrations
+// without one after them in filename order.
+func sortMigrations(migrations []Migration, filenames []string) error {
+	files := make(map[int]string, len(migrations))
+	for i, migration := range migrations {
+		if migration.Order == 0 {
+			continue
+		}
+		if other, ok := files[migration.Order]; ok {
+			return &ErrMigrationParse{File: filenames[i], Err: fmt.Errorf("%w: %d, see %s", errOrderInUse, migration.Order, other)}
+		}
+		files[migration.Order] = filenames[i]
+	}
+
+	slices.SortStableFunc(migrations, func(a, b Migration) int {
+		switch {
+		case a.Order == b.Order:
+			return 0
+		case a.Order == 0:
+			return 1
+		case b.Order == 0:
+			return -1
+		default:
+			return a.Order - b.Order
+		}
+	})
+
+	return nil
+}
+
+// substituteVariables replaces ${NAME} references in the Up and Down sections of migration.
+func substituteVariables(migration Migration, variables map[string]string) (Migration, error) {
+	var missing []string
+	substitute := func(sql string) string {
+		return migrationVariable.ReplaceAllStringFunc(sql, func(reference string) string {
+			name := migrationVariable.FindStringSubmatch(reference)[1]
+			value, ok := variables[name]
+			if !ok {
+				missing = append(missing, name)
+			}
+			return value
+		})
+	}
+
+	migration.Up = substitute(migration.Up)
+	migration.Down = substitute(migration.Down)
+
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return Migration{}, fmt.Errorf("%w: %s", ErrMissingMigrationVariable, strings.Join(slices.Compact(missing), ", "))
+	}
+
+	return migration, nil
+}
+
+// findDestructiveStatement returns the first line of sql that drops or truncates a table, ignoring comment lines.
+func findDestructiveStatement(sql string) string {
+	for line := range strings.SplitSeq(sql, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		if destructiveStatement.MatchString(trimmed) {
+			return trimmed
+		}
+	}
+
+	return ""
+}
+
+// parseMigrationFile parses a single migration file and reports whether it carries the AllowDestructive marker.
+func parseMigrationFile(fsys fs.FS, filename string) (Migration, bool, error) {
 	file, err := fsys.Open(filename)
 	if err != nil {
-		return Migration{}, fmt.Errorf("failed to open file: %w", err)
+		return Migration{}, false, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer func() { _ = file.Close() }()
 
 	id := strings.TrimSuffix(filename, ".sql")
 	idOverridden := false
 	anyMarkerSeen := false
+	allowDestructive := false
+	order := 0
 
 	var upBuilder, downBuilder strings.Builder
 	var currentSection *strings.Builder
@@ -84,14 +235,14 @@ func parseMigrationFile(fsys fs.FS, filename string) (Migration, error) {
 		// Check for ID override marker
 		if strings.HasPrefix(trimmed, markerID) {
 			if idOverridden {
-				return Migration{}, errDuplicateIDMarker
+				return Migration{}, false, errDuplicateIDMarker
 			}
 			if anyMarkerSeen {
-				return Migration{}, errIDMarkerNotFirst
+				return Migration{}, false, errIDMarkerNotFirst
 			}
 			overrideID := strings.TrimSpace(strings.TrimPrefix(trimmed, markerID))
 			if overrideID == "" {
-				return Migration{}, errEmptyIDOverride
+				return Migration{}, false, errEmptyIDOverride
 			}
 			id = overrideID
 			idOverridden = true
@@ -99,7 +250,22 @@ func parseMigrationFile(fsys fs.FS, filename string) (Migration, error) {
 			continue
 		}
 
+		if strings.HasPrefix(trimmed, markerOrder) {
+			if order != 0 {
+				return Migration{}, false, errDuplicateOrder
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(trimmed, markerOrder)))
+			if err != nil || n <= 0 {
+				return Migration{}, false, errInvalidOrder
+			}
+			order = n
+			continue
+		}
+
 		switch trimmed {
+		case markerAllowDestructive:
+			allowDestructive = true
+			continue
 		case markerUp:
 			currentSection = &upBuilder
 			anyMarkerSeen = true
@@ -117,17 +283,18 @@ func parseMigrationFile(fsys fs.FS, filename string) (Migration, error) {
 	}
 
 	if err := scanner.Err(); err != nil {
-		return Migration{}, fmt.Errorf("failed to read file: %w", err)
+		return Migration{}, false, fmt.Errorf("failed to read file: %w", err)
 	}
 
 	up := strings.TrimSpace(upBuilder.String())
 	if up == "" {
-		return Migration{}, errMissingUpSection
+		return Migration{}, false, errMissingUpSection
 	}
 
 	return Migration{
-		ID:   id,
-		Up:   up,
-		Down: strings.TrimSpace(downBuilder.String()),
-	}, nil
+		ID:    id,
+		Up:    up,
+		Down:  strings.TrimSpace(downBuilder.String()),
+		Order: order,
+	}, allowDestructive, nil
 }
@@ -1,6 +1,9 @@
 package database_test
 
 import (
+	"errors"
+	"slices"
+	"strings"
 	"testing"
 	"testing/fstest"
 
@@ -142,6 +145,27 @@ func TestParseMigrations(t *testing.T) {
 		}
 	})
 
+	t.Run("parse error carries file", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := fstest.MapFS{
+			"001_init.sql": &fstest.MapFile{
+				Data: []byte("-- +migrate Down\nDROP TABLE users;"),
+			},
+		}
+
+		_, err := database.ParseMigrations(fsys)
+
+		var parseErr *database.ErrMigrationParse
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("expected ErrMigrationParse, got: %v", err)
+		}
+
+		if parseErr.File != "001_init.sql" || parseErr.Repository != "" {
+			t.Errorf("expected file 001_init.sql without repository, got file %q, repository %q", parseErr.File, parseErr.Repository)
+		}
+	})
+
 	t.Run("errors on empty up section", func(t *testing.T) {
 		t.Parallel()
 
@@ -385,3 +409,247 @@ func TestParseMigrations(t *testing.T) {
 		}
 	})
 }
+
+func TestParseMigrationsDestructiveLint(t *testing.T) {
+	t.Parallel()
+
+	t.Run("strict mode rejects destructive migration without marker", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := fstest.MapFS{
+			"001_drop.sql": &fstest.MapFile{
+				Data: []byte("-- +migrate Up\nDROP TABLE users;\n\n-- +migrate Down\nCREATE TABLE users (id INT);"),
+			},
+		}
+
+		_, err := database.ParseMigrations(fsys, database.WithDestructiveLint(true))
+		if !errors.Is(err, database.ErrDestructiveMigration) {
+			t.Fatalf("expected destructive migration error, got: %v", err)
+		}
+	})
+
+	t.Run("strict mode rejects truncate", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := fstest.MapFS{
+			"001_truncate.sql": &fstest.MapFile{
+				Data: []byte("-- +migrate Up\ntruncate users;"),
+			},
+		}
+
+		_, err := database.ParseMigrations(fsys, database.WithDestructiveLint(true))
+		if !errors.Is(err, database.ErrDestructiveMigration) {
+			t.Fatalf("expected destructive migration error, got: %v", err)
+		}
+	})
+
+	t.Run("strict mode accepts destructive migration with marker", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := fstest.MapFS{
+			"001_drop.sql": &fstest.MapFile{
+				Data: []byte("-- +migrate AllowDestructive\n-- +migrate Up\nDROP TABLE users;"),
+			},
+		}
+
+		migrations, err := database.ParseMigrations(fsys, database.WithDestructiveLint(true))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if migrations[0].Up != "DROP TABLE users;" {
+			t.Errorf("unexpected Up content: %q", migrations[0].Up)
+		}
+	})
+
+	t.Run("non-strict mode only warns", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := fstest.MapFS{
+			"001_drop.sql": &fstest.MapFile{
+				Data: []byte("-- +migrate Up\nDROP TABLE users;"),
+			},
+		}
+
+		migrations, err := database.ParseMigrations(fsys, database.WithDestructiveLint(false))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(migrations) != 1 {
+			t.Fatalf("expected 1 migration, got %d", len(migrations))
+		}
+	})
+
+	t.Run("ignores Down section and comments", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := fstest.MapFS{
+			"001_init.sql": &fstest.MapFile{
+				Data: []byte("-- +migrate Up\n-- replaces DROP TABLE from the old schema\nCREATE TABLE users (id INT);\n\n-- +migrate Down\nDROP TABLE users;"),
+			},
+		}
+
+		_, err := database.ParseMigrations(fsys, database.WithDestructiveLint(true))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestParseMigrationsVariables(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"001_init.sql": &fstest.MapFile{
+			Data: []byte("-- +migrate Up\nCREATE TABLE users (id INT) TABLESPACE ${TABLESPACE};\nGRANT SELECT ON users TO ${ROLE};\n\n-- +migrate Down\nDROP TABLE users;"),
+		},
+	}
+
+	t.Run("substitutes variables", func(t *testing.T) {
+		t.Parallel()
+
+		migrations, err := database.ParseMigrations(fsys, database.WithVariables(map[string]string{"TABLESPACE": "fast", "ROLE": "reader"}))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := "CREATE TABLE users (id INT) TABLESPACE fast;\nGRANT SELECT ON users TO reader;"
+		if migrations[0].Up != expected {
+			t.Errorf("expected Up %q, got %q", expected, migrations[0].Up)
+		}
+	})
+
+	t.Run("errors on missing variable", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := database.ParseMigrations(fsys, database.WithVariables(map[string]string{"TABLESPACE": "fast"}))
+		if !errors.Is(err, database.ErrMissingMigrationVariable) {
+			t.Fatalf("expected missing variable error, got: %v", err)
+		}
+
+		if !strings.Contains(err.Error(), "ROLE") {
+			t.Errorf("expected error to name the missing variable, got: %v", err)
+		}
+	})
+
+	t.Run("leaves references unchanged without variables", func(t *testing.T) {
+		t.Parallel()
+
+		migrations, err := database.ParseMigrations(fsys)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !strings.Contains(migrations[0].Up, "${TABLESPACE}") {
+			t.Errorf("expected unchanged Up, got %q", migrations[0].Up)
+		}
+	})
+}
+
+func TestParseMigrationsOrder(t *testing.T) {
+	t.Parallel()
+
+	migrationIDs := func(migrations []database.Migration) []string {
+		ids := make([]string, 0, len(migrations))
+		for _, migration := range migrations {
+			ids = append(ids, migration.ID)
+		}
+		return ids
+	}
+
+	t.Run("without marker sorts lexicographically", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := fstest.MapFS{
+			"2_x.sql":  &fstest.MapFile{Data: []byte("-- +migrate Up\nSECOND")},
+			"10_x.sql": &fstest.MapFile{Data: []byte("-- +migrate Up\nTENTH")},
+		}
+
+		migrations, err := database.ParseMigrations(fsys)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if ids := migrationIDs(migrations); !slices.Equal(ids, []string{"10_x", "2_x"}) {
+			t.Errorf("expected lexicographic order, got %v", ids)
+		}
+	})
+
+	t.Run("marker sorts numerically", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := fstest.MapFS{
+			"2_x.sql":  &fstest.MapFile{Data: []byte("-- +migrate Order: 2\n-- +migrate Up\nSECOND")},
+			"10_x.sql": &fstest.MapFile{Data: []byte("-- +migrate Order: 10\n-- +migrate Up\nTENTH")},
+		}
+
+		migrations, err := database.ParseMigrations(fsys)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if ids := migrationIDs(migrations); !slices.Equal(ids, []string{"2_x", "10_x"}) {
+			t.Errorf("expected order from marker, got %v", ids)
+		}
+
+		if migrations[0].Order != 2 || migrations[1].Order != 10 {
+			t.Errorf("expected orders 2 and 10, got %d and %d", migrations[0].Order, migrations[1].Order)
+		}
+	})
+
+	t.Run("migrations without marker come last", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := fstest.MapFS{
+			"a.sql":    &fstest.MapFile{Data: []byte("-- +migrate Up\nA")},
+			"2_x.sql":  &fstest.MapFile{Data: []byte("-- +migrate Order: 2\n-- +migrate Up\nSECOND")},
+			"b.sql":    &fstest.MapFile{Data: []byte("-- +migrate Up\nB")},
+			"10_x.sql": &fstest.MapFile{Data: []byte("-- +migrate ID: tenth\n-- +migrate Order: 10\n-- +migrate Up\nTENTH")},
+		}
+
+		migrations, err := database.ParseMigrations(fsys)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if ids := migrationIDs(migrations); !slices.Equal(ids, []string{"2_x", "tenth", "a", "b"}) {
+			t.Errorf("expected ordered migrations first, got %v", ids)
+		}
+	})
+
+	invalid := map[string]string{
+		"errors on non-numeric order":  "-- +migrate Order: second\n-- +migrate Up\nA",
+		"errors on zero order":         "-- +migrate Order: 0\n-- +migrate Up\nA",
+		"errors on duplicate marker":   "-- +migrate Order: 1\n-- +migrate Order: 2\n-- +migrate Up\nA",
+		"errors on empty order marker": "-- +migrate Order:\n-- +migrate Up\nA",
+	}
+	for name, data := range invalid {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := database.ParseMigrations(fstest.MapFS{"1_x.sql": &fstest.MapFile{Data: []byte(data)}})
+
+			var parseErr *database.ErrMigrationParse
+			if !errors.As(err, &parseErr) || parseErr.File != "1_x.sql" {
+				t.Fatalf("expected ErrMigrationParse for 1_x.sql, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("errors on order used twice", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := fstest.MapFS{
+			"1_x.sql": &fstest.MapFile{Data: []byte("-- +migrate Order: 1\n-- +migrate Up\nA")},
+			"2_x.sql": &fstest.MapFile{Data: []byte("-- +migrate Order: 1\n-- +migrate Up\nB")},
+		}
+
+		_, err := database.ParseMigrations(fsys)
+
+		var parseErr *database.ErrMigrationParse
+		if !errors.As(err, &parseErr) || parseErr.File != "2_x.sql" || !strings.Contains(err.Error(), "1_x.sql") {
+			t.Fatalf("expected ErrMigrationParse for 2_x.sql naming 1_x.sql, got: %v", err)
+		}
+	})
+}
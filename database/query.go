@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// SetQueryTimeout sets the timeout applied to every statement run by the framework:
+// migrations, the migrations log, RunSQLFiles, BatchInsert and the GetContext, SelectContext and ExecContext wrappers.
+// Each statement gets its own deadline, so a long migration must fit into a single timeout.
+// Zero or a negative value disables the timeout, which is the default.
+func (db *Database) SetQueryTimeout(timeout time.Duration) {
+	db.repo.queryTimeout.Store(int64(timeout))
+}
+
+// GetContext runs a query that is expected to return a single row and scans it into dest,
+// like sqlx.DB.GetContext, limited by the query timeout. It reads from a replica if there are any,
+// see NewWithReplicas.
+func (db *Database) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	conn := db.reader(ctx)
+
+	ctx, cancel := db.repo.withQueryTimeout(ctx)
+	defer cancel()
+
+	if err := conn.GetContext(ctx, dest, query, args...); err != nil {
+		return fmt.Errorf("failed to get row: %w", err)
+	}
+
+	return nil
+}
+
+// SelectContext runs a query and scans all rows into dest, like sqlx.DB.SelectContext,
+// limited by the query timeout. It reads from a replica if there are any, see NewWithReplicas.
+func (db *Database) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	conn := db.reader(ctx)
+
+	ctx, cancel := db.repo.withQueryTimeout(ctx)
+	defer cancel()
+
+	if err := conn.SelectContext(ctx, dest, query, args...); err != nil {
+		return fmt.Errorf("failed to select rows: %w", err)
+	}
+
+	return nil
+}
+
+// ExecContext runs a query without returning rows, like sqlx.DB.ExecContext, limited by the query timeout.
+func (db *Database) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, cancel := db.repo.withQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := db.conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return result, nil
+}
+
+// NamedExecContext runs a query with named parameters bound from arg without returning rows,
+// like sqlx.DB.NamedExecContext, limited by the query timeout. With ExecContext, GetContext and
+// SelectContext it lets a Database be passed to repositories, so that their reads use replicas.
+func (db *Database) NamedExecContext(ctx context.Context, query string, arg any) (sql.Result, error) {
+	ctx, cancel := db.repo.withQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := db.conn.NamedExecContext(ctx, query, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// primaryReadKey marks contexts whose reads go to the primary, see WithPrimaryRead.
+type primaryReadKey struct{}
+
+// NewWithReplicas creates a new Database instance connected to a primary and read replicas.
+// GetContext and SelectContext read from the replicas in round-robin order, while writes,
+// transactions, BatchInsert and migrations always run on the primary. Use WithPrimaryRead
+// for reads that must see the latest writes. Passwords are masked in connection errors.
+func NewWithReplicas(primary string, replicas []string) (*Database, error) {
+	primaryConn, err := sqlx.Connect("postgres", primary)
+	if err != nil {
+		return nil, newConnectError(primary, err)
+	}
+
+	conns := make([]*sqlx.DB, 0, len(replicas))
+	for _, replica := range replicas {
+		conn, err := sqlx.Connect("postgres", replica)
+		if err != nil {
+			// the connection error is what matters to the caller, close errors are ignored
+			for _, opened := range append(conns, primaryConn) {
+				_ = opened.Close()
+			}
+
+			return nil, newConnectError(replica, err)
+		}
+		conns = append(conns, conn)
+	}
+
+	return NewFromDB(primaryConn, conns...), nil
+}
+
+// WithPrimaryRead returns a context that makes GetContext and SelectContext read from the primary
+// instead of a replica, e.g. for a read right after a write that replicas may not have applied yet.
+func WithPrimaryRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadKey{}, true)
+}
+
+// reader returns the connection to read from: the next replica, or the primary if there are no
+// replicas or ctx was marked with WithPrimaryRead.
+func (db *Database) reader(ctx context.Context) *sqlx.DB {
+	if len(db.replicas) == 0 {
+		return db.conn
+	}
+
+	if primary, _ := ctx.Value(primaryReadKey{}).(bool); primary {
+		return db.conn
+	}
+
+	next := db.nextReplica.Add(1) - 1
+
+	return db.replicas[next%uint64(len(db.replicas))]
+}
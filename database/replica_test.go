@@ -0,0 +1,203 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/platforma-dev/platforma/database"
+)
+
+func TestReplicaRouting(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reads alternate between replicas", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := &queryRecorder{}
+		db := database.NewFromDB(recorder.open("primary"), recorder.open("replica-1"), recorder.open("replica-2"))
+		defer db.Close()
+
+		var value int
+		var values []int
+		for range 2 {
+			if err := db.GetContext(context.Background(), &value, "SELECT 1"); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if err := db.SelectContext(context.Background(), &values, "SELECT 1"); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+		}
+
+		expected := []string{"replica-1", "replica-2", "replica-1", "replica-2"}
+		if got := recorder.served(); !slices.Equal(got, expected) {
+			t.Fatalf("expected reads served by %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("writes go to primary", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := &queryRecorder{}
+		db := database.NewFromDB(recorder.open("primary"), recorder.open("replica-1"), recorder.open("replica-2"))
+		defer db.Close()
+
+		if _, err := db.ExecContext(context.Background(), "DELETE FROM items"); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if _, err := db.NamedExecContext(context.Background(), "INSERT INTO items (id) VALUES (:id)", map[string]any{"id": 1}); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		expected := []string{"primary", "primary"}
+		if got := recorder.served(); !slices.Equal(got, expected) {
+			t.Fatalf("expected writes served by %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("primary read", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := &queryRecorder{}
+		db := database.NewFromDB(recorder.open("primary"), recorder.open("replica-1"), recorder.open("replica-2"))
+		defer db.Close()
+
+		ctx := database.WithPrimaryRead(context.Background())
+
+		var value int
+		if err := db.GetContext(ctx, &value, "SELECT 1"); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		var values []int
+		if err := db.SelectContext(ctx, &values, "SELECT 1"); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		expected := []string{"primary", "primary"}
+		if got := recorder.served(); !slices.Equal(got, expected) {
+			t.Fatalf("expected reads served by %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("reads go to primary without replicas", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := &queryRecorder{}
+		db := database.NewFromDB(recorder.open("primary"))
+		defer db.Close()
+
+		var value int
+		if err := db.GetContext(context.Background(), &value, "SELECT 1"); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		if got := recorder.served(); !slices.Equal(got, []string{"primary"}) {
+			t.Fatalf("expected read served by primary, got %v", got)
+		}
+	})
+}
+
+// queryRecorder opens fake connections that record which connection served each query.
+// Queries return a single row with the value 1.
+type queryRecorder struct {
+	mu      sync.Mutex
+	queries []string
+}
+
+func (r *queryRecorder) open(name string) *sqlx.DB {
+	return sqlx.NewDb(sql.OpenDB(&recordingConnector{name: name, recorder: r}), "postgres")
+}
+
+func (r *queryRecorder) record(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.queries = append(r.queries, name)
+}
+
+func (r *queryRecorder) served() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.queries)
+}
+
+type recordingConnector struct {
+	name     string
+	recorder *queryRecorder
+}
+
+func (c *recordingConnector) Connect(context.Context) (driver.Conn, error) {
+	return &recordingConn{connector: c}, nil
+}
+
+func (c *recordingConnector) Driver() driver.Driver {
+	return nil
+}
+
+type recordingConn struct {
+	connector *recordingConnector
+}
+
+func (c *recordingConn) Prepare(string) (driver.Stmt, error) {
+	return &recordingStmt{conn: c}, nil
+}
+
+func (c *recordingConn) Close() error {
+	return nil
+}
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return nil, driver.ErrSkip
+}
+
+type recordingStmt struct {
+	conn *recordingConn
+}
+
+func (s *recordingStmt) Close() error {
+	return nil
+}
+
+func (s *recordingStmt) NumInput() int {
+	return -1
+}
+
+func (s *recordingStmt) Exec([]driver.Value) (driver.Result, error) {
+	s.conn.connector.recorder.record(s.conn.connector.name)
+
+	return driver.RowsAffected(1), nil
+}
+
+func (s *recordingStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.conn.connector.recorder.record(s.conn.connector.name)
+
+	return &singleRow{}, nil
+}
+
+type singleRow struct {
+	done bool
+}
+
+func (r *singleRow) Columns() []string {
+	return []string{"value"}
+}
+
+func (r *singleRow) Close() error {
+	return nil
+}
+
+func (r *singleRow) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+
+	return nil
+}
@@ -3,27 +3,45 @@ package database
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
 
+// migrationSavepoint is the savepoint set before each migration in MigrationRollbackFailed mode.
+const migrationSavepoint = "platforma_migration"
+
 type repository struct {
-	db *sqlx.DB
+	db           *sqlx.DB
+	queryTimeout atomic.Int64
 }
 
 func newRepository(db *sqlx.DB) *repository {
 	return &repository{db: db}
 }
 
+// migrations returns the migrations of the migrations log table itself.
+// They are idempotent so that re-running them on a partially initialized database is a no-op.
 func (r *repository) migrations() []Migration {
 	return []Migration{{
-		ID:   "init",
-		Up:   "CREATE TABLE IF NOT EXISTS platforma_migrations (repository TEXT, id TEXT, timestamp TIMESTAMP)",
-		Down: "DROP TABLE platforma_migrations",
+		ID: "init",
+		Up: `
+			CREATE TABLE IF NOT EXISTS platforma_migrations (
+				repository TEXT NOT NULL,
+				id TEXT NOT NULL,
+				timestamp TIMESTAMP NOT NULL
+			);
+			CREATE INDEX IF NOT EXISTS platforma_migrations_repository_id_idx ON platforma_migrations (repository, id);
+		`,
+		Down: "DROP TABLE IF EXISTS platforma_migrations",
 	}}
 }
 
 func (r *repository) getMigrationLogs(ctx context.Context) ([]migrationLog, error) {
+	ctx, cancel := r.withQueryTimeout(ctx)
+	defer cancel()
+
 	var migrations []migrationLog
 	err := r.db.SelectContext(ctx, &migrations, "SELECT * FROM platforma_migrations")
 	if err != nil {
@@ -34,11 +52,19 @@ func (r *repository) getMigrationLogs(ctx context.Context) ([]migrationLog, erro
 }
 
 func (r *repository) saveMigrationLog(ctx context.Context, log migrationLog) error {
+	return r.saveMigrationLogIn(ctx, r.db, log)
+}
+
+// saveMigrationLogIn saves a migration log using e, e.g. a transaction.
+func (r *repository) saveMigrationLogIn(ctx context.Context, e sqlx.ExtContext, log migrationLog) error {
 	query := `
 		INSERT INTO platforma_migrations (repository, id, timestamp)
 		VALUES (:repository, :id, :timestamp)
 	`
-	_, err := r.db.NamedExecContext(ctx, query, log)
+	ctx, cancel := r.withQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := sqlx.NamedExecContext(ctx, e, query, log)
 	if err != nil {
 		return fmt.Errorf("failed to save migration log: %w", err)
 	}
@@ -46,9 +72,27 @@ func (r *repository) saveMigrationLog(ctx context.Context, log migrationLog) err
 }
 
 func (r *repository) executeQuery(ctx context.Context, query string) error {
-	_, err := r.db.ExecContext(ctx, query)
+	return r.executeQueryIn(ctx, r.db, query)
+}
+
+// executeQueryIn executes query using e, e.g. a transaction.
+func (r *repository) executeQueryIn(ctx context.Context, e sqlx.ExecerContext, query string) error {
+	ctx, cancel := r.withQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := e.ExecContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("failed to execute query: %w", err)
 	}
 	return nil
 }
+
+// withQueryTimeout returns ctx limited by the query timeout, or ctx itself if no timeout is set.
+func (r *repository) withQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := time.Duration(r.queryTimeout.Load())
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
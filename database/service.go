@@ -11,7 +11,9 @@ import (
 )
 
 type service struct {
-	repo *repository
+	repo            *repository
+	rollbackMode    MigrationRollbackMode
+	continueOnError bool
 }
 
 func newService(repo *repository) *service {
@@ -65,7 +67,7 @@ func (s *service) migrateSelf(ctx context.Context) error {
 				if revertErr != nil {
 					log.ErrorContext(ctx, "got error(s) trying to revert migrations", "error", revertErr)
 				}
-				return err
+				return &ErrMigrationApply{Repository: "platforma_migration", ID: migr.ID, Err: err}
 			}
 			log.InfoContext(ctx, "migration applied", "repository", "platforma_migration", "migrationId", migr.ID)
 			migr.repository = "platforma_migration"
@@ -92,6 +94,10 @@ func (s *service) applyMigration(ctx context.Context, migration Migration) error
 }
 
 func (s *service) applyMigrations(ctx context.Context, migrations []Migration, migrationLogs []migrationLog) error {
+	if s.rollbackMode == MigrationRollbackFailed {
+		return s.applyMigrationsWithSavepoints(ctx, migrations, migrationLogs)
+	}
+
 	appliedMigrations := []Migration{}
 	for _, migr := range migrations {
 		if !slices.ContainsFunc(migrationLogs, func(l migrationLog) bool {
@@ -103,7 +109,7 @@ func (s *service) applyMigrations(ctx context.Context, migrations []Migration, m
 				if revertErr != nil {
 					log.ErrorContext(ctx, "got error(s) trying to revert migrations", "error", revertErr)
 				}
-				return err
+				return &ErrMigrationApply{Repository: migr.repository, ID: migr.ID, Err: err}
 			}
 			log.InfoContext(ctx, "migration applied", "repository", migr.repository, "migrationId", migr.ID)
 			appliedMigrations = append(appliedMigrations, migr)
@@ -120,6 +126,79 @@ func (s *service) applyMigrations(ctx context.Context, migrations []Migration, m
 	return nil
 }
 
+// applyRepositoryMigrations applies the migrations of each repository separately, in the order the
+// repositories first appear in migrations. A failing repository's migrations are rolled back like in
+// applyMigrations while the remaining repositories are still migrated, and all errors are joined.
+func (s *service) applyRepositoryMigrations(ctx context.Context, migrations []Migration, migrationLogs []migrationLog) error {
+	var repositories []string
+	byRepository := map[string][]Migration{}
+	for _, migr := range migrations {
+		if _, ok := byRepository[migr.repository]; !ok {
+			repositories = append(repositories, migr.repository)
+		}
+		byRepository[migr.repository] = append(byRepository[migr.repository], migr)
+	}
+
+	var errs error
+	for _, repository := range repositories {
+		if err := s.applyMigrations(ctx, byRepository[repository], migrationLogs); err != nil {
+			log.ErrorContext(ctx, "failed to migrate repository, continuing", "repository", repository, "error", err)
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// applyMigrationsWithSavepoints applies pending migrations in one transaction, setting a savepoint
+// before each of them. When a migration fails, only it is rolled back and the migrations applied
+// before it are committed together with their logs.
+func (s *service) applyMigrationsWithSavepoints(ctx context.Context, migrations []Migration, migrationLogs []migrationLog) error {
+	tx, err := s.repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migrations transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // no-op after commit
+
+	var applyErr error
+	for _, migr := range migrations {
+		if slices.ContainsFunc(migrationLogs, func(l migrationLog) bool {
+			return l.Repository == migr.repository && l.MigrationID == migr.ID
+		}) {
+			log.InfoContext(ctx, "migration skipped", "repository", migr.repository, "migrationId", migr.ID)
+			continue
+		}
+
+		if err := s.repo.executeQueryIn(ctx, tx, "SAVEPOINT "+migrationSavepoint); err != nil {
+			return fmt.Errorf("failed to set migration savepoint: %w", err)
+		}
+
+		if err := s.repo.executeQueryIn(ctx, tx, migr.Up); err != nil {
+			if rollbackErr := s.repo.executeQueryIn(ctx, tx, "ROLLBACK TO SAVEPOINT "+migrationSavepoint); rollbackErr != nil {
+				return errors.Join(&ErrMigrationApply{Repository: migr.repository, ID: migr.ID, Err: err}, rollbackErr)
+			}
+			applyErr = &ErrMigrationApply{Repository: migr.repository, ID: migr.ID, Err: err}
+			break
+		}
+
+		err := s.repo.saveMigrationLogIn(ctx, tx, migrationLog{Repository: migr.repository, MigrationID: migr.ID, Timestamp: time.Now()})
+		if err != nil {
+			return fmt.Errorf("failed to save migration log: %w", err)
+		}
+
+		if err := s.repo.executeQueryIn(ctx, tx, "RELEASE SAVEPOINT "+migrationSavepoint); err != nil {
+			return fmt.Errorf("failed to release migration savepoint: %w", err)
+		}
+		log.InfoContext(ctx, "migration applied", "repository", migr.repository, "migrationId", migr.ID)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migrations: %w", err)
+	}
+
+	return applyErr
+}
+
 func (s *service) revertMigration(ctx context.Context, migration Migration) error {
 	err := s.repo.executeQuery(ctx, migration.Down)
 	if err != nil {
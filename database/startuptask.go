@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// StartupTaskCompleted reports whether a successful run of the named startup task was recorded
+// with MarkStartupTaskCompleted. The markers table is created on first use.
+func (db *Database) StartupTaskCompleted(ctx context.Context, name string) (bool, error) {
+	if err := db.repo.createStartupTasksTable(ctx); err != nil {
+		return false, err
+	}
+
+	return db.repo.startupTaskCompleted(ctx, name)
+}
+
+// MarkStartupTaskCompleted records a successful run of the named startup task, so that
+// StartupTaskCompleted reports it on later boots. Marking a task twice is a no-op.
+func (db *Database) MarkStartupTaskCompleted(ctx context.Context, name string) error {
+	if err := db.repo.createStartupTasksTable(ctx); err != nil {
+		return err
+	}
+
+	return db.repo.saveStartupTask(ctx, name, time.Now())
+}
+
+func (r *repository) createStartupTasksTable(ctx context.Context) error {
+	return r.executeQuery(ctx, `
+		CREATE TABLE IF NOT EXISTS platforma_startup_tasks (
+			name TEXT PRIMARY KEY,
+			completed TIMESTAMP NOT NULL
+		)
+	`)
+}
+
+func (r *repository) startupTaskCompleted(ctx context.Context, name string) (bool, error) {
+	ctx, cancel := r.withQueryTimeout(ctx)
+	defer cancel()
+
+	var completed time.Time
+	err := r.db.GetContext(ctx, &completed, "SELECT completed FROM platforma_startup_tasks WHERE name = $1", name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get startup task: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *repository) saveStartupTask(ctx context.Context, name string, completed time.Time) error {
+	ctx, cancel := r.withQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "INSERT INTO platforma_startup_tasks (name, completed) VALUES ($1, $2) ON CONFLICT (name) DO NOTHING", name, completed)
+	if err != nil {
+		return fmt.Errorf("failed to save startup task: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// RunInTx runs fn in a transaction. The transaction is committed if fn returns nil and rolled back
+// if it returns an error or panics; the error of fn is returned unchanged. Repositories join the
+// transaction through their WithTx method, e.g. auth.Repository.WithTx(tx), so that writes of
+// several repositories commit or roll back together.
+func (db *Database) RunInTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // no-op after commit
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
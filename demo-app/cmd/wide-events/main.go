@@ -16,6 +16,7 @@ func main() {
 		log.NewDefaultSampler(3*time.Second, 200, 0.1),
 		"json",
 		nil,
+		log.WithMaxSteps(50),
 	)
 
 	ev := log.NewEvent("test_event")
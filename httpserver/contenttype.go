@@ -0,0 +1,54 @@
+package httpserver
+
+import (
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// RequireJSONMiddleware is a middleware that rejects POST, PUT and PATCH requests
+// whose body is not of an accepted content type with HTTP 415 Unsupported Media Type.
+// Requests with an empty body and other methods are passed through.
+type RequireJSONMiddleware struct {
+	acceptedTypes []string
+}
+
+// NewRequireJSONMiddleware creates a new instance of RequireJSONMiddleware.
+// It accepts application/json unless other media types are passed.
+func NewRequireJSONMiddleware(acceptedTypes ...string) *RequireJSONMiddleware {
+	if len(acceptedTypes) == 0 {
+		acceptedTypes = []string{"application/json"}
+	}
+
+	normalized := make([]string, 0, len(acceptedTypes))
+	for _, t := range acceptedTypes {
+		normalized = append(normalized, strings.ToLower(t))
+	}
+
+	return &RequireJSONMiddleware{acceptedTypes: normalized}
+}
+
+// Wrap implements the Middleware interface by wrapping the provided handler
+// with content type validation.
+func (m *RequireJSONMiddleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isWriteMethod(r.Method) || r.ContentLength == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// parameters such as charset are ignored
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || !slices.Contains(m.acceptedTypes, mediaType) {
+			http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isWriteMethod(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+}
@@ -0,0 +1,52 @@
+package httpserver_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/platforma-dev/platforma/httpserver"
+)
+
+func TestRequireJSONMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		acceptedTypes []string
+		method        string
+		contentType   string
+		body          string
+		wantStatus    int
+	}{
+		{name: "json request", method: http.MethodPost, contentType: "application/json", body: `{"a":1}`, wantStatus: http.StatusOK},
+		{name: "json request with charset", method: http.MethodPut, contentType: "application/json; charset=utf-8", body: `{"a":1}`, wantStatus: http.StatusOK},
+		{name: "form request", method: http.MethodPost, contentType: "application/x-www-form-urlencoded", body: "a=1", wantStatus: http.StatusUnsupportedMediaType},
+		{name: "missing content type", method: http.MethodPatch, body: `{"a":1}`, wantStatus: http.StatusUnsupportedMediaType},
+		{name: "empty body", method: http.MethodPost, wantStatus: http.StatusOK},
+		{name: "get request", method: http.MethodGet, contentType: "text/plain", body: "a", wantStatus: http.StatusOK},
+		{name: "custom accepted type", acceptedTypes: []string{"application/merge-patch+json"}, method: http.MethodPatch, contentType: "application/merge-patch+json", body: `{"a":1}`, wantStatus: http.StatusOK},
+		{name: "default type not accepted with custom types", acceptedTypes: []string{"application/merge-patch+json"}, method: http.MethodPatch, contentType: "application/json", body: `{"a":1}`, wantStatus: http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := httpserver.NewRequireJSONMiddleware(tt.acceptedTypes...).Wrap(&normalHandler{})
+
+			req := httptest.NewRequest(tt.method, "/test", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
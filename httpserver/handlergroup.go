@@ -2,13 +2,33 @@ package httpserver
 
 import (
 	"net/http"
+	"slices"
 	"strings"
 )
 
 // HandlerGroup represents a group of HTTP handlers that share common middlewares.
+//
+// Middlewares run in the order of the group's middleware chain: Use appends to the end
+// of the chain (innermost), Prepend inserts at the start (outermost). When a group is
+// mounted into another group or server, the parent's whole chain runs before the
+// mounted group's chain, so a middleware prepended on the server (e.g. recovery)
+// is always the outermost one.
+//
+// With returns an inline sub-group that registers its routes in the parent group
+// with additional middlewares, which run after the parent's chain.
 type HandlerGroup struct {
 	mux         *http.ServeMux
 	middlewares []Middleware
+	routes      []route
+	parent      *HandlerGroup // set for inline groups created by With
+}
+
+// route is a pattern registered with Handle or a handler registered with Mount.
+// Routes of mounted handlers are resolved in Routes, so that routes added after mounting are included.
+type route struct {
+	pattern    string
+	handler    http.Handler
+	keepPrefix bool
 }
 
 // NewHandlerGroup creates a new HandlerGroup with an initialized http.ServeMux.
@@ -28,29 +48,163 @@ func (hg *HandlerGroup) UseFunc(middlewareFuncs ...func(http.Handler) http.Handl
 	}
 }
 
+// Prepend inserts middlewares at the start of the HandlerGroup's middleware chain,
+// so they run before all previously added middlewares. Middlewares passed in
+// one call keep their relative order.
+func (hg *HandlerGroup) Prepend(middlewares ...Middleware) {
+	hg.middlewares = append(slices.Clone(middlewares), hg.middlewares...)
+}
+
+// PrependFunc inserts functions as middlewares at the start of the HandlerGroup's middleware chain.
+func (hg *HandlerGroup) PrependFunc(middlewareFuncs ...func(http.Handler) http.Handler) {
+	middlewares := make([]Middleware, 0, len(middlewareFuncs))
+	for _, middlewareFunc := range middlewareFuncs {
+		middlewares = append(middlewares, MiddlewareFunc(middlewareFunc))
+	}
+
+	hg.Prepend(middlewares...)
+}
+
+// With returns an inline sub-group with additional middlewares. Routes registered in the
+// sub-group are added to hg, and only they run the additional middlewares, after hg's chain.
+// Neither hg nor its other routes are affected. Serving the sub-group serves hg.
+func (hg *HandlerGroup) With(middlewares ...Middleware) *HandlerGroup {
+	return &HandlerGroup{mux: hg.mux, middlewares: slices.Clone(middlewares), parent: hg}
+}
+
+// WithFunc is like With, but takes middleware functions.
+func (hg *HandlerGroup) WithFunc(middlewareFuncs ...func(http.Handler) http.Handler) *HandlerGroup {
+	middlewares := make([]Middleware, 0, len(middlewareFuncs))
+	for _, middlewareFunc := range middlewareFuncs {
+		middlewares = append(middlewares, MiddlewareFunc(middlewareFunc))
+	}
+
+	return hg.With(middlewares...)
+}
+
+// inlineHandler runs a handler registered in an inline group through the group's middlewares.
+// Like ServeHTTP, the chain is built per request, so middlewares added to the group later apply too.
+type inlineHandler struct {
+	group   *HandlerGroup
+	handler http.Handler
+}
+
+func (h *inlineHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	wrapHandlerInMiddleware(h.handler, h.group.middlewares).ServeHTTP(w, r)
+}
+
 // Handle registers an http.Handler for the given pattern
 func (hg *HandlerGroup) Handle(pattern string, handler http.Handler) {
+	if hg.parent != nil {
+		hg.parent.Handle(pattern, &inlineHandler{group: hg, handler: handler})
+		return
+	}
+
 	hg.mux.Handle(pattern, handler)
+	hg.routes = append(hg.routes, route{pattern: pattern})
 }
 
 // HandleFunc registers an http.HandlerFunc for the given pattern
 func (hg *HandlerGroup) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
-	hg.mux.Handle(pattern, http.HandlerFunc(handler))
+	hg.Handle(pattern, http.HandlerFunc(handler))
+}
+
+// Routes returns the patterns registered in the group in registration order.
+// Routes of mounted groups are included with the mount prefix, other mounted
+// handlers are listed as the prefix subtree, e.g. "/static/".
+// Inline groups created by With return the routes of their parent.
+func (hg *HandlerGroup) Routes() []string {
+	if hg.parent != nil {
+		return hg.parent.Routes()
+	}
+
+	routes := make([]string, 0, len(hg.routes))
+	for _, r := range hg.routes {
+		if r.handler == nil {
+			routes = append(routes, r.pattern)
+			continue
+		}
+
+		routes = append(routes, mountedRoutes(r.pattern, r.handler, r.keepPrefix)...)
+	}
+
+	return routes
+}
+
+// mountedRoutes returns the routes of handler mounted at prefix.
+func mountedRoutes(prefix string, handler http.Handler, keepPrefix bool) []string {
+	if inline, ok := handler.(*inlineHandler); ok {
+		handler = inline.handler
+	}
+
+	group, ok := handler.(interface{ Routes() []string })
+	if !ok {
+		return []string{strings.TrimSuffix(prefix, "/") + "/"}
+	}
+
+	routes := group.Routes()
+	if keepPrefix || prefix == "/" {
+		return routes
+	}
+
+	for i, route := range routes {
+		method, path, found := strings.Cut(route, " ")
+		if !found {
+			routes[i] = prefix + route
+			continue
+		}
+
+		routes[i] = method + " " + prefix + strings.TrimLeft(path, " ")
+	}
+
+	return routes
+}
+
+// MountOption configures how a handler is mounted by Mount.
+type MountOption func(*mountOptions)
+
+type mountOptions struct {
+	keepPrefix bool
+}
+
+// WithoutPrefixStripping makes the mounted handler receive the original request path,
+// including the mount prefix. Nested groups must then register full paths.
+func WithoutPrefixStripping() MountOption {
+	return func(o *mountOptions) {
+		o.keepPrefix = true
+	}
 }
 
 // Mount mounts handler at both prefix (group root) and prefix+"/" (subtree).
-// The handler receives requests with the path prefix stripped; an empty stripped
+// By default the handler receives requests with the path prefix stripped; an empty stripped
 // path is normalized to "/" so that nested groups can register "GET /" etc.
-func (hg *HandlerGroup) Mount(prefix string, handler http.Handler) {
+// Use WithoutPrefixStripping to pass the original path through.
+func (hg *HandlerGroup) Mount(prefix string, handler http.Handler, opts ...MountOption) {
 	if prefix != "" && !strings.HasPrefix(prefix, "/") {
 		panic("httpserver: mount prefix must be a path starting with /")
 	}
 
+	if hg.parent != nil {
+		hg.parent.Mount(prefix, &inlineHandler{group: hg, handler: handler}, opts...)
+		return
+	}
+
+	var o mountOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	prefix = strings.TrimRight(prefix, "/")
 	if prefix == "" {
 		prefix = "/"
 	}
-	mounted := stripPrefix(prefix, handler)
+
+	mounted := handler
+	if !o.keepPrefix {
+		mounted = stripPrefix(prefix, handler)
+	}
+
+	hg.routes = append(hg.routes, route{pattern: prefix, handler: handler, keepPrefix: o.keepPrefix})
 
 	if prefix == "/" {
 		hg.mux.Handle(prefix, mounted)
@@ -93,6 +247,11 @@ func stripPrefix(prefix string, handler http.Handler) http.Handler {
 // ServeHTTP implements the http.Handler interface, allowing HandlerGroup to
 // be used as an HTTP handler itself.
 func (hg *HandlerGroup) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if hg.parent != nil {
+		hg.parent.ServeHTTP(w, r)
+		return
+	}
+
 	wrappedMux := wrapHandlerInMiddleware(hg.mux, hg.middlewares)
 	wrappedMux.ServeHTTP(w, r)
 }
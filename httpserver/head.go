@@ -0,0 +1,66 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// HeadMiddleware is a middleware that answers HEAD requests by running the handler like for GET
+// and discarding the response body, so that the status and headers match the GET response.
+// Routes registered with a GET pattern already match HEAD requests; the middleware makes sure no body
+// is passed on and sets Content-Length from the discarded body unless the handler set it.
+type HeadMiddleware struct{}
+
+// NewHeadMiddleware creates a new instance of HeadMiddleware.
+func NewHeadMiddleware() *HeadMiddleware {
+	return &HeadMiddleware{}
+}
+
+// Wrap implements the Middleware interface by wrapping the provided handler
+// with HEAD request handling.
+func (m *HeadMiddleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		hw := &headResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(hw, r)
+		hw.finish()
+	})
+}
+
+// headResponseWriter discards the body and delays the status until the handler returns,
+// so that Content-Length can be set from the discarded body. It does not support flushing.
+type headResponseWriter struct {
+	http.ResponseWriter
+	status  int
+	written int
+}
+
+func (w *headResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *headResponseWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	w.written += len(p)
+
+	return len(p), nil
+}
+
+// finish writes the status and headers of the response.
+func (w *headResponseWriter) finish() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	if w.written > 0 && w.Header().Get("Content-Length") == "" {
+		w.Header().Set("Content-Length", strconv.Itoa(w.written))
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+}
@@ -0,0 +1,72 @@
+package httpserver_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/platforma-dev/platforma/httpserver"
+)
+
+func TestHeadMiddleware(t *testing.T) {
+	t.Parallel()
+
+	group := httpserver.NewHandlerGroup()
+	group.Use(httpserver.NewHeadMiddleware())
+	group.HandleFunc("GET /items", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-Total-Count", "2")
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write([]byte(`["a","b"]`))
+	})
+	group.HandleFunc("GET /sized", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Length", "100")
+	})
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		group.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+		return w
+	}
+
+	t.Run("HEAD matches GET without body", func(t *testing.T) {
+		t.Parallel()
+
+		get := serve(http.MethodGet, "/items")
+		head := serve(http.MethodHead, "/items")
+
+		if head.Code != get.Code {
+			t.Errorf("expected status %d, got %d", get.Code, head.Code)
+		}
+
+		if head.Header().Get("X-Total-Count") != get.Header().Get("X-Total-Count") {
+			t.Errorf("expected X-Total-Count %q, got %q", get.Header().Get("X-Total-Count"), head.Header().Get("X-Total-Count"))
+		}
+
+		if head.Body.Len() != 0 {
+			t.Errorf("expected empty body, got %q", head.Body.String())
+		}
+
+		if head.Header().Get("Content-Length") != strconv.Itoa(get.Body.Len()) {
+			t.Errorf("expected Content-Length %d, got %q", get.Body.Len(), head.Header().Get("Content-Length"))
+		}
+	})
+
+	t.Run("keeps Content-Length set by handler", func(t *testing.T) {
+		t.Parallel()
+
+		head := serve(http.MethodHead, "/sized")
+		if head.Code != http.StatusOK || head.Header().Get("Content-Length") != "100" {
+			t.Errorf("expected 200 with Content-Length 100, got %d with %q", head.Code, head.Header().Get("Content-Length"))
+		}
+	})
+
+	t.Run("GET is unchanged", func(t *testing.T) {
+		t.Parallel()
+
+		get := serve(http.MethodGet, "/items")
+		if get.Code != http.StatusPartialContent || get.Body.String() != `["a","b"]` {
+			t.Errorf("expected 206 with body, got %d with %q", get.Code, get.Body.String())
+		}
+	})
+}
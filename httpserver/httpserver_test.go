@@ -5,7 +5,9 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
+	"testing/fstest"
 
 	"github.com/platforma-dev/platforma/httpserver"
 )
@@ -284,6 +286,74 @@ func TestHTTPServer(t *testing.T) {
 		}
 	})
 
+	t.Run("mount group path observed by nested handler", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name     string
+			opts     []httpserver.MountOption
+			expected string
+		}{
+			{name: "stripped", expected: "/items/1"},
+			{name: "not stripped", opts: []httpserver.MountOption{httpserver.WithoutPrefixStripping()}, expected: "/api/items/1"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				nested := &handler{
+					serveHTTP: func(w http.ResponseWriter, r *http.Request) {
+						w.Write([]byte(r.URL.Path))
+					},
+				}
+
+				server := httpserver.New("", 0)
+				server.Mount("/api", nested, tt.opts...)
+
+				r := httptest.NewRequest(http.MethodGet, "/api/items/1", nil)
+				w := httptest.NewRecorder()
+
+				server.ServeHTTP(w, r)
+
+				resp := w.Result()
+				body, _ := io.ReadAll(resp.Body)
+
+				if string(body) != tt.expected {
+					t.Fatalf("expected nested handler to observe path %q, got %q", tt.expected, string(body))
+				}
+			})
+		}
+	})
+
+	t.Run("mount group without prefix stripping", func(t *testing.T) {
+		t.Parallel()
+
+		hg := httpserver.NewHandlerGroup()
+		hg.HandleFunc("GET /api/users", func(w http.ResponseWriter, _ *http.Request) {
+			w.Write([]byte("users"))
+		})
+
+		server := httpserver.New("", 0)
+		server.Mount("/api", hg, httpserver.WithoutPrefixStripping())
+
+		r := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+		w := httptest.NewRecorder()
+
+		server.ServeHTTP(w, r)
+
+		resp := w.Result()
+		body, _ := io.ReadAll(resp.Body)
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status code to be 200, got %d", resp.StatusCode)
+		}
+
+		if string(body) != "users" {
+			t.Fatalf("expected body to be 'users', got %s", string(body))
+		}
+	})
+
 	t.Run("healthcheck", func(t *testing.T) {
 		t.Parallel()
 
@@ -412,6 +482,134 @@ func TestHTTPServer(t *testing.T) {
 			t.Fatalf("expected second middleware to be called second, got %s", middlewareCallLog[1])
 		}
 	})
+
+	t.Run("middleware order with append and prepend", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		record := func(name string) func(http.Handler) http.Handler {
+			return func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					calls = append(calls, name)
+					next.ServeHTTP(w, r)
+				})
+			}
+		}
+
+		hg := httpserver.NewHandlerGroup()
+		hg.UseFunc(record("group-use-1"))
+		hg.PrependFunc(record("group-prepend"))
+		hg.UseFunc(record("group-use-2"))
+		hg.HandleFunc("GET /test", func(w http.ResponseWriter, _ *http.Request) {
+			calls = append(calls, "handler")
+			w.WriteHeader(http.StatusOK)
+		})
+
+		server := httpserver.New("", 0)
+		server.UseFunc(record("server-use"))
+		server.Mount("/hg", hg)
+		server.Prepend(&testMiddleware{wrapFunc: record("server-recover")}, &testMiddleware{wrapFunc: record("server-trace")})
+
+		r := httptest.NewRequest(http.MethodGet, "/hg/test", nil)
+		w := httptest.NewRecorder()
+
+		server.ServeHTTP(w, r)
+
+		expected := []string{"server-recover", "server-trace", "server-use", "group-prepend", "group-use-1", "group-use-2", "handler"}
+		if !slices.Equal(calls, expected) {
+			t.Fatalf("expected call order %v, got %v", expected, calls)
+		}
+	})
+
+	t.Run("chain runs middlewares in order", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		record := func(name string) func(http.Handler) http.Handler {
+			return func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					calls = append(calls, name)
+					next.ServeHTTP(w, r)
+				})
+			}
+		}
+
+		hg := httpserver.NewHandlerGroup()
+		hg.UseFunc(httpserver.Chain(record("first"), record("second")), record("third"))
+		hg.HandleFunc("GET /test", func(w http.ResponseWriter, _ *http.Request) {
+			calls = append(calls, "handler")
+			w.WriteHeader(http.StatusOK)
+		})
+
+		hg.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/test", nil))
+
+		expected := []string{"first", "second", "third", "handler"}
+		if !slices.Equal(calls, expected) {
+			t.Fatalf("expected call order %v, got %v", expected, calls)
+		}
+	})
+
+	t.Run("with adds middlewares to sub-group routes only", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		record := func(name string) func(http.Handler) http.Handler {
+			return func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					calls = append(calls, name)
+					next.ServeHTTP(w, r)
+				})
+			}
+		}
+
+		hg := httpserver.NewHandlerGroup()
+		hg.UseFunc(record("group"))
+		hg.HandleFunc("GET /public", func(w http.ResponseWriter, _ *http.Request) {
+			calls = append(calls, "public")
+			w.WriteHeader(http.StatusOK)
+		})
+
+		admin := hg.WithFunc(record("auth"), record("audit"))
+		admin.HandleFunc("GET /admin", func(w http.ResponseWriter, _ *http.Request) {
+			calls = append(calls, "admin")
+			w.WriteHeader(http.StatusOK)
+		})
+		admin.With(&testMiddleware{wrapFunc: record("nested")}).HandleFunc("GET /admin/nested", func(w http.ResponseWriter, _ *http.Request) {
+			calls = append(calls, "nested-handler")
+			w.WriteHeader(http.StatusOK)
+		})
+
+		server := httpserver.New("", 0)
+		server.Mount("/api", hg)
+
+		tests := []struct {
+			path     string
+			expected []string
+		}{
+			{path: "/api/public", expected: []string{"group", "public"}},
+			{path: "/api/admin", expected: []string{"group", "auth", "audit", "admin"}},
+			{path: "/api/admin/nested", expected: []string{"group", "auth", "audit", "nested", "nested-handler"}},
+			{path: "/api/public", expected: []string{"group", "public"}},
+		}
+
+		for _, tt := range tests {
+			calls = nil
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200 for %s, got %d", tt.path, w.Code)
+			}
+			if !slices.Equal(calls, tt.expected) {
+				t.Fatalf("expected call order %v for %s, got %v", tt.expected, tt.path, calls)
+			}
+		}
+
+		expectedRoutes := []string{"GET /public", "GET /admin", "GET /admin/nested"}
+		if routes := hg.Routes(); !slices.Equal(routes, expectedRoutes) {
+			t.Fatalf("expected routes %v, got %v", expectedRoutes, routes)
+		}
+	})
 }
 
 type handler struct {
@@ -437,3 +635,29 @@ func (m *testMiddleware) Wrap(next http.Handler) http.Handler {
 	}
 	return next
 }
+
+func TestHandlerGroupRoutes(t *testing.T) {
+	t.Parallel()
+
+	noop := func(_ http.ResponseWriter, _ *http.Request) {}
+
+	api := httpserver.NewHandlerGroup()
+	api.HandleFunc("GET /users", noop)
+
+	legacy := httpserver.NewHandlerGroup()
+	legacy.HandleFunc("/legacy/ping", noop)
+
+	server := httpserver.New("8080", 0)
+	server.HandleFunc("GET /health", noop)
+	server.Mount("/api", api)
+	server.Mount("/legacy", legacy, httpserver.WithoutPrefixStripping())
+	server.Mount("/static", http.FileServerFS(fstest.MapFS{}))
+
+	// routes added after mounting are included
+	api.HandleFunc("POST /users", noop)
+
+	want := []string{"GET /health", "GET /api/users", "POST /api/users", "/legacy/ping", "/static/"}
+	if got := server.Routes(); !slices.Equal(got, want) {
+		t.Fatalf("expected routes %v, got %v", want, got)
+	}
+}
@@ -19,6 +19,20 @@ func (f MiddlewareFunc) Wrap(h http.Handler) http.Handler {
 	return f(h)
 }
 
+// Chain composes middleware functions into one. The first middleware is the outermost,
+// so Chain(a, b)(h) runs a, then b, then h.
+func Chain(middlewareFuncs ...func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	middlewareFuncs = slices.Clone(middlewareFuncs)
+
+	return func(handler http.Handler) http.Handler {
+		for _, middlewareFunc := range slices.Backward(middlewareFuncs) {
+			handler = middlewareFunc(handler)
+		}
+
+		return handler
+	}
+}
+
 // wrapHandlerInMiddleware wraps an http.Handler with a chain of middlewares.
 // The middlewares are applied in reverse order of the provided slice,
 // meaning the last middleware in the slice will be the most inner.
@@ -0,0 +1,121 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrStreamingUnsupported is returned by NewSSEWriter when the response writer can't be flushed.
+var ErrStreamingUnsupported = errors.New("response writer does not support streaming")
+
+// SSEEvent is a server-sent event written by SSEWriter.Stream.
+type SSEEvent struct {
+	Event string // Event type, omitted if empty so that clients receive a "message" event
+	Data  string // Event data, may span several lines
+}
+
+// SSEWriter writes server-sent events to a response, flushing each event to the client.
+type SSEWriter struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+// NewSSEWriter starts a server-sent events response: it sets the text/event-stream headers,
+// writes the status and flushes them so that clients see the stream open immediately.
+// Middlewares wrapping the response writer must support flushing, directly or through an
+// Unwrap method like log.WideEventMiddleware; otherwise ErrStreamingUnsupported is returned
+// and nothing is written. Note that the request, and its wide event, lasts as long as the stream.
+func NewSSEWriter(w http.ResponseWriter) (*SSEWriter, error) {
+	rc := http.NewResponseController(w)
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no") // disable response buffering in nginx
+
+	// flushing before writing the status would send an implicit 200, so check support first
+	if !canFlush(w) {
+		for _, key := range []string{"Content-Type", "Cache-Control", "Connection", "X-Accel-Buffering"} {
+			header.Del(key)
+		}
+
+		return nil, ErrStreamingUnsupported
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to flush event stream headers: %w", err)
+	}
+
+	return &SSEWriter{w: w, rc: rc}, nil
+}
+
+// canFlush reports whether w or a response writer it wraps implements http.Flusher.
+func canFlush(w http.ResponseWriter) bool {
+	for {
+		switch t := w.(type) {
+		case http.Flusher:
+			return true
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = t.Unwrap()
+		default:
+			return false
+		}
+	}
+}
+
+// Send writes an event and flushes it to the client. An empty event type is omitted,
+// and multi-line data is sent as several data lines, which clients join with newlines.
+// It returns an error once the client has disconnected.
+func (s *SSEWriter) Send(event, data string) error {
+	var b strings.Builder
+	if event != "" {
+		b.WriteString("event: " + singleLine(event) + "\n")
+	}
+
+	// clients treat CR, LF and CRLF as line breaks, so data can't inject other fields
+	data = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(data)
+	for line := range strings.SplitSeq(data, "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+
+	if _, err := s.w.Write([]byte(b.String())); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+
+	if err := s.rc.Flush(); err != nil {
+		return fmt.Errorf("failed to flush event: %w", err)
+	}
+
+	return nil
+}
+
+// Stream sends events from the channel until it is closed, returning nil, or until ctx is done,
+// e.g. because the client disconnected and the request context was canceled, returning ctx.Err().
+// Errors of Send are returned as well.
+func (s *SSEWriter) Stream(ctx context.Context, events <-chan SSEEvent) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("event stream stopped: %w", ctx.Err())
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+
+			if err := s.Send(event.Event, event.Data); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// singleLine strips line breaks from a field that must fit on one line.
+func singleLine(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
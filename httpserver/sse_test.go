@@ -0,0 +1,192 @@
+package httpserver_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/platforma-dev/platforma/httpserver"
+	"github.com/platforma-dev/platforma/log"
+)
+
+func TestSSEWriter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("sets headers and flushes them", func(t *testing.T) {
+		t.Parallel()
+
+		w := httptest.NewRecorder()
+		if _, err := httpserver.NewSSEWriter(w); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		if got := w.Header().Get("Content-Type"); got != "text/event-stream" {
+			t.Fatalf("expected text/event-stream content type, got %q", got)
+		}
+		if got := w.Header().Get("Cache-Control"); got != "no-cache" {
+			t.Fatalf("expected no-cache, got %q", got)
+		}
+		if w.Code != http.StatusOK || !w.Flushed {
+			t.Fatalf("expected flushed 200 response, got %d, flushed %v", w.Code, w.Flushed)
+		}
+	})
+
+	t.Run("wire format", func(t *testing.T) {
+		t.Parallel()
+
+		w := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+		sse, err := httpserver.NewSSEWriter(w)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		tests := []struct {
+			event string
+			data  string
+			wire  string
+		}{
+			{event: "update", data: `{"id":1}`, wire: "event: update\ndata: {\"id\":1}\n\n"},
+			{event: "", data: "hello", wire: "data: hello\n\n"},
+			{event: "multi", data: "first\nsecond\r\nthird", wire: "event: multi\ndata: first\ndata: second\ndata: third\n\n"},
+			{event: "inject\ndata: x", data: "a\rid: 1", wire: "event: injectdata: x\ndata: a\ndata: id: 1\n\n"},
+			{event: "empty", data: "", wire: "event: empty\ndata: \n\n"},
+		}
+
+		for _, tt := range tests {
+			w.Body.Reset()
+			flushes := w.flushes
+
+			if err := sse.Send(tt.event, tt.data); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			if got := w.Body.String(); got != tt.wire {
+				t.Fatalf("expected %q, got %q", tt.wire, got)
+			}
+			if w.flushes != flushes+1 {
+				t.Fatalf("expected event %q to be flushed", tt.event)
+			}
+		}
+	})
+
+	t.Run("unsupported writer", func(t *testing.T) {
+		t.Parallel()
+
+		w := &noFlushWriter{header: http.Header{}}
+		_, err := httpserver.NewSSEWriter(w)
+		if !errors.Is(err, httpserver.ErrStreamingUnsupported) {
+			t.Fatalf("expected streaming unsupported error, got: %v", err)
+		}
+
+		if w.status != 0 || len(w.header) != 0 {
+			t.Fatalf("expected nothing to be written, got status %d and headers %v", w.status, w.header)
+		}
+	})
+
+	t.Run("stream stops on context cancellation", func(t *testing.T) {
+		t.Parallel()
+
+		w := httptest.NewRecorder()
+		sse, err := httpserver.NewSSEWriter(w)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		events := make(chan httpserver.SSEEvent)
+		done := make(chan error, 1)
+		go func() { done <- sse.Stream(ctx, events) }()
+
+		events <- httpserver.SSEEvent{Event: "tick", Data: "1"}
+		cancel()
+
+		if err := <-done; !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context canceled error, got: %v", err)
+		}
+
+		if got := w.Body.String(); got != "event: tick\ndata: 1\n\n" {
+			t.Fatalf("expected streamed event, got %q", got)
+		}
+	})
+
+	t.Run("stream ends when channel is closed", func(t *testing.T) {
+		t.Parallel()
+
+		w := httptest.NewRecorder()
+		sse, err := httpserver.NewSSEWriter(w)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		events := make(chan httpserver.SSEEvent, 2)
+		events <- httpserver.SSEEvent{Data: "a"}
+		events <- httpserver.SSEEvent{Data: "b"}
+		close(events)
+
+		if err := sse.Stream(context.Background(), events); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		if got := w.Body.String(); got != "data: a\n\ndata: b\n\n" {
+			t.Fatalf("expected streamed events, got %q", got)
+		}
+	})
+
+	t.Run("works behind wide event middleware", func(t *testing.T) {
+		t.Parallel()
+
+		var logs bytes.Buffer
+		logger := log.NewWideEventLogger(&logs, nil, "json", nil)
+		middleware := log.NewWideEventMiddleware(logger, "http.request", sseEventKey{})
+
+		handler := middleware.Wrap(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			sse, err := httpserver.NewSSEWriter(w)
+			if err != nil {
+				t.Errorf("expected no error, got: %v", err)
+				return
+			}
+
+			if err := sse.Send("update", "1"); err != nil {
+				t.Errorf("expected no error, got: %v", err)
+			}
+		}))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events", nil))
+
+		if !w.Flushed || w.Body.String() != "event: update\ndata: 1\n\n" {
+			t.Fatalf("expected flushed event, got flushed %v and %q", w.Flushed, w.Body.String())
+		}
+
+		if !strings.Contains(logs.String(), `"request.status":200`) {
+			t.Fatalf("expected wide event with status 200 after the stream, got %s", logs.String())
+		}
+	})
+}
+
+type sseEventKey struct{}
+
+// flushRecorder counts flushes of a ResponseRecorder.
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushes int
+}
+
+func (r *flushRecorder) Flush() {
+	r.flushes++
+	r.ResponseRecorder.Flush()
+}
+
+// noFlushWriter is a response writer without http.Flusher.
+type noFlushWriter struct {
+	header http.Header
+	status int
+}
+
+func (w *noFlushWriter) Header() http.Header         { return w.header }
+func (w *noFlushWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (w *noFlushWriter) WriteHeader(status int)      { w.status = status }
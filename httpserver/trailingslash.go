@@ -0,0 +1,104 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// TrailingSlashMode defines how TrailingSlashMiddleware normalizes request paths.
+type TrailingSlashMode int
+
+const (
+	// TrailingSlashRedirect redirects requests with a trailing slash to the path without it.
+	// GET and HEAD requests get 301 Moved Permanently, other methods get 308 Permanent Redirect
+	// so that the method and body are preserved.
+	TrailingSlashRedirect TrailingSlashMode = iota
+	// TrailingSlashRewrite strips the trailing slash from the request path before passing it on.
+	TrailingSlashRewrite
+)
+
+// TrailingSlashMiddleware is a middleware that normalizes request paths by removing trailing slashes,
+// so that "/users/" is handled the same way as "/users". The root path is never changed.
+//
+// Redirects are built from the original request URI, so the middleware can be used inside groups
+// mounted with a stripped prefix and still redirect to the full path.
+type TrailingSlashMiddleware struct {
+	mode         TrailingSlashMode
+	skipPrefixes []string
+}
+
+// NewTrailingSlashMiddleware creates a new instance of TrailingSlashMiddleware.
+// Requests whose original path starts with one of skipPrefixes are passed through unchanged.
+// Use it for static file handlers such as http.FileServer that rely on trailing slashes for directories.
+func NewTrailingSlashMiddleware(mode TrailingSlashMode, skipPrefixes ...string) *TrailingSlashMiddleware {
+	return &TrailingSlashMiddleware{mode: mode, skipPrefixes: skipPrefixes}
+}
+
+// Wrap implements the Middleware interface by wrapping the provided handler
+// with trailing slash normalization.
+func (m *TrailingSlashMiddleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" || !strings.HasSuffix(r.URL.Path, "/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		original := originalPath(r)
+		for _, prefix := range m.skipPrefixes {
+			if strings.HasPrefix(original, prefix) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		if m.mode == TrailingSlashRewrite {
+			r2 := r.Clone(r.Context())
+			r2.URL.Path = trimTrailingSlash(r.URL.Path)
+			if r.URL.RawPath != "" {
+				r2.URL.RawPath = trimTrailingSlash(r.URL.RawPath)
+			}
+
+			next.ServeHTTP(w, r2)
+			return
+		}
+
+		target := trimTrailingSlash(original)
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+
+		status := http.StatusPermanentRedirect
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			status = http.StatusMovedPermanently
+		}
+
+		http.Redirect(w, r, target, status)
+	})
+}
+
+// originalPath returns the escaped request path before any prefix was stripped by Mount.
+func originalPath(r *http.Request) string {
+	if r.RequestURI != "" {
+		if u, err := url.ParseRequestURI(r.RequestURI); err == nil {
+			return u.EscapedPath()
+		}
+	}
+
+	return r.URL.EscapedPath()
+}
+
+// trimTrailingSlash removes trailing slashes from path. Leading slashes are collapsed
+// so that the result can't be interpreted as a protocol-relative URL like "//example.com".
+func trimTrailingSlash(path string) string {
+	trimmed := strings.TrimRight(path, "/")
+	if trimmed == "" {
+		return "/"
+	}
+
+	if strings.HasPrefix(trimmed, "//") {
+		trimmed = "/" + strings.TrimLeft(trimmed, "/")
+	}
+
+	return trimmed
+}
@@ -0,0 +1,116 @@
+package httpserver_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/platforma-dev/platforma/httpserver"
+)
+
+func TestTrailingSlashMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		mode         httpserver.TrailingSlashMode
+		skipPrefixes []string
+		method       string
+		target       string
+		wantStatus   int
+		wantLocation string
+		wantPath     string
+	}{
+		{name: "redirect get", mode: httpserver.TrailingSlashRedirect, method: http.MethodGet, target: "/users/", wantStatus: http.StatusMovedPermanently, wantLocation: "/users"},
+		{name: "redirect post keeps method", mode: httpserver.TrailingSlashRedirect, method: http.MethodPost, target: "/users/", wantStatus: http.StatusPermanentRedirect, wantLocation: "/users"},
+		{name: "redirect keeps query", mode: httpserver.TrailingSlashRedirect, method: http.MethodGet, target: "/users/?page=2", wantStatus: http.StatusMovedPermanently, wantLocation: "/users?page=2"},
+		{name: "redirect multiple slashes", mode: httpserver.TrailingSlashRedirect, method: http.MethodGet, target: "/users///", wantStatus: http.StatusMovedPermanently, wantLocation: "/users"},
+		{name: "redirect is not protocol relative", mode: httpserver.TrailingSlashRedirect, method: http.MethodGet, target: "//example.com/", wantStatus: http.StatusMovedPermanently, wantLocation: "/example.com"},
+		{name: "redirect canonical path", mode: httpserver.TrailingSlashRedirect, method: http.MethodGet, target: "/users", wantStatus: http.StatusOK, wantPath: "/users"},
+		{name: "redirect root", mode: httpserver.TrailingSlashRedirect, method: http.MethodGet, target: "/", wantStatus: http.StatusOK, wantPath: "/"},
+		{name: "rewrite", mode: httpserver.TrailingSlashRewrite, method: http.MethodGet, target: "/users/", wantStatus: http.StatusOK, wantPath: "/users"},
+		{name: "rewrite root", mode: httpserver.TrailingSlashRewrite, method: http.MethodGet, target: "/", wantStatus: http.StatusOK, wantPath: "/"},
+		{name: "skipped prefix", mode: httpserver.TrailingSlashRedirect, skipPrefixes: []string{"/static/"}, method: http.MethodGet, target: "/static/css/", wantStatus: http.StatusOK, wantPath: "/static/css/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotPath string
+			handler := httpserver.NewTrailingSlashMiddleware(tt.mode, tt.skipPrefixes...).Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+
+			if location := w.Header().Get("Location"); location != tt.wantLocation {
+				t.Errorf("expected location %q, got %q", tt.wantLocation, location)
+			}
+
+			if gotPath != tt.wantPath {
+				t.Errorf("expected handler path %q, got %q", tt.wantPath, gotPath)
+			}
+		})
+	}
+}
+
+func TestTrailingSlashMiddlewareMountedGroup(t *testing.T) {
+	t.Parallel()
+
+	group := httpserver.NewHandlerGroup()
+	group.Use(httpserver.NewTrailingSlashMiddleware(httpserver.TrailingSlashRedirect))
+	group.HandleFunc("GET /users", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server := httpserver.New("8080", 0)
+	server.Mount("/api", group)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users/", nil)
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusMovedPermanently {
+		t.Fatalf("expected status %d, got %d", http.StatusMovedPermanently, w.Code)
+	}
+
+	if location := w.Header().Get("Location"); location != "/api/users" {
+		t.Errorf("expected location %q, got %q", "/api/users", location)
+	}
+}
+
+func TestTrailingSlashMiddlewareStaticFiles(t *testing.T) {
+	t.Parallel()
+
+	files := fstest.MapFS{
+		"docs/index.html": {Data: []byte("docs index")},
+	}
+
+	server := httpserver.New("8080", 0)
+	server.Use(httpserver.NewTrailingSlashMiddleware(httpserver.TrailingSlashRedirect, "/static/"))
+	server.Mount("/static", http.FileServerFS(files))
+
+	req := httptest.NewRequest(http.MethodGet, "/static/docs/", nil)
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	body, _ := io.ReadAll(w.Body)
+	if string(body) != "docs index" {
+		t.Errorf("expected directory index to be served, got %q", string(body))
+	}
+}
@@ -0,0 +1,159 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrWriterClosed is returned when writing to a closed AsyncWriter.
+var ErrWriterClosed = errors.New("writer is closed")
+
+// AsyncWriter writes to the underlying writer from a background goroutine through a bounded buffer,
+// so that a slow writer (e.g. a network sink) does not block logging goroutines.
+// When the buffer is full, records are dropped and counted instead of blocking.
+type AsyncWriter struct {
+	w       io.Writer
+	timeout time.Duration
+	dropped atomic.Uint64
+
+	// mu guards the records channel lifecycle so that it is never closed during a send.
+	mu      sync.RWMutex
+	records chan asyncRecord
+	closed  bool
+
+	done     chan struct{}
+	writeErr error
+}
+
+// asyncRecord is either a log record or a sync request that is acknowledged once all earlier records are written.
+type asyncRecord struct {
+	data   []byte
+	synced chan error
+}
+
+var _ Syncer = (*AsyncWriter)(nil)
+
+// NewAsyncWriter creates an AsyncWriter that buffers up to bufferSize records.
+// Write waits at most timeout for free buffer space before dropping the record;
+// with a zero timeout records are dropped as soon as the buffer is full.
+// Call Close to flush buffered records and stop the background goroutine.
+func NewAsyncWriter(w io.Writer, bufferSize int, timeout time.Duration) *AsyncWriter {
+	aw := &AsyncWriter{
+		w:       w,
+		timeout: timeout,
+		records: make(chan asyncRecord, bufferSize),
+		done:    make(chan struct{}),
+	}
+
+	go aw.run()
+
+	return aw
+}
+
+// Write queues a copy of p to be written to the underlying writer.
+// It never reports a dropped record as an error, so that logging does not fail; use Dropped instead.
+func (aw *AsyncWriter) Write(p []byte) (int, error) {
+	aw.mu.RLock()
+	defer aw.mu.RUnlock()
+
+	if aw.closed {
+		return 0, ErrWriterClosed
+	}
+
+	// slog handlers reuse their buffers after Write returns
+	record := make([]byte, len(p))
+	copy(record, p)
+
+	select {
+	case aw.records <- asyncRecord{data: record}:
+		return len(p), nil
+	default:
+	}
+
+	if aw.timeout > 0 {
+		timer := time.NewTimer(aw.timeout)
+		defer timer.Stop()
+
+		select {
+		case aw.records <- asyncRecord{data: record}:
+			return len(p), nil
+		case <-timer.C:
+		}
+	}
+
+	aw.dropped.Add(1)
+
+	return len(p), nil
+}
+
+// Sync blocks until all records queued before the call are written and syncs the underlying
+// writer if it implements Syncer. Unlike Write, Sync waits for free buffer space.
+func (aw *AsyncWriter) Sync() error {
+	aw.mu.RLock()
+	if aw.closed {
+		aw.mu.RUnlock()
+		return ErrWriterClosed
+	}
+
+	synced := make(chan error, 1)
+	aw.records <- asyncRecord{synced: synced}
+	aw.mu.RUnlock()
+
+	return <-synced
+}
+
+// Dropped returns the number of records dropped because the buffer was full.
+func (aw *AsyncWriter) Dropped() uint64 {
+	return aw.dropped.Load()
+}
+
+// Close stops accepting records, waits until buffered records are written and
+// returns the first error reported by the underlying writer.
+func (aw *AsyncWriter) Close() error {
+	aw.mu.Lock()
+	if !aw.closed {
+		aw.closed = true
+		close(aw.records)
+	}
+	aw.mu.Unlock()
+
+	<-aw.done
+
+	if aw.writeErr != nil {
+		return fmt.Errorf("failed to write log record: %w", aw.writeErr)
+	}
+
+	return nil
+}
+
+func (aw *AsyncWriter) run() {
+	defer close(aw.done)
+
+	for record := range aw.records {
+		if record.synced != nil {
+			record.synced <- aw.syncUnderlying()
+			continue
+		}
+
+		if _, err := aw.w.Write(record.data); err != nil && aw.writeErr == nil {
+			aw.writeErr = err
+		}
+	}
+}
+
+func (aw *AsyncWriter) syncUnderlying() error {
+	syncer, ok := aw.w.(Syncer)
+	if !ok {
+		return nil
+	}
+
+	if err := syncer.Sync(); err != nil {
+		return fmt.Errorf("failed to sync log writer: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package log_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	platformalog "github.com/platforma-dev/platforma/log"
+)
+
+func TestAsyncWriter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("slow writer does not block and drops are counted", func(t *testing.T) {
+		t.Parallel()
+
+		slow := &slowWriter{delay: 200 * time.Millisecond}
+		w := platformalog.NewAsyncWriter(slow, 1, 10*time.Millisecond)
+		logger := platformalog.NewWideEventLogger(w, nil, "json", nil)
+
+		const events = 5
+		start := time.Now()
+		for range events {
+			logger.WriteEvent(context.Background(), platformalog.NewEvent("job"))
+		}
+		elapsed := time.Since(start)
+
+		// each write waits at most for the 10ms deadline
+		if elapsed > 150*time.Millisecond {
+			t.Fatalf("expected writes not to block on slow writer, took %s", elapsed)
+		}
+
+		if w.Dropped() == 0 {
+			t.Fatal("expected dropped records to be counted")
+		}
+
+		if err := w.Close(); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		if written := uint64(slow.count()); written+w.Dropped() != events {
+			t.Fatalf("expected written (%d) + dropped (%d) to be %d", written, w.Dropped(), events)
+		}
+	})
+
+	t.Run("close flushes buffered records", func(t *testing.T) {
+		t.Parallel()
+
+		slow := &slowWriter{delay: time.Millisecond}
+		w := platformalog.NewAsyncWriter(slow, 10, 0)
+
+		for range 3 {
+			w.Write([]byte("record\n"))
+		}
+
+		if err := w.Close(); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		if slow.count() != 3 {
+			t.Fatalf("expected 3 records to be written, got %d", slow.count())
+		}
+
+		if _, err := w.Write([]byte("late\n")); !errors.Is(err, platformalog.ErrWriterClosed) {
+			t.Fatalf("expected writer closed error, got: %v", err)
+		}
+	})
+}
+
+type slowWriter struct {
+	delay time.Duration
+
+	mu      sync.Mutex
+	records int
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.records++
+
+	return len(p), nil
+}
+
+func (w *slowWriter) count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.records
+}
@@ -0,0 +1,122 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+const (
+	colorReset  = "\x1b[0m"
+	colorRed    = "\x1b[31m"
+	colorYellow = "\x1b[33m"
+	colorGreen  = "\x1b[32m"
+	colorGray   = "\x1b[90m"
+)
+
+// WithColor forces colorized level output of NewColorHandler on or off instead of detecting a terminal.
+func WithColor(enabled bool) Option {
+	return func(o *options) {
+		o.color = &enabled
+	}
+}
+
+// NewColorHandler creates a text handler for local development that colorizes the level token:
+// red for errors, yellow for warnings, green for info and gray for debug.
+// Colors are enabled when w is a terminal, see WithColor to override the detection.
+// WithReplaceAttr is applied like in New.
+func NewColorHandler(w io.Writer, level slog.Level, opts ...Option) slog.Handler {
+	o := newOptions(opts)
+
+	enabled := isTerminal(w)
+	if o.color != nil {
+		enabled = *o.color
+	}
+
+	if enabled {
+		w = &colorWriter{w: w}
+	}
+
+	return slog.NewTextHandler(w, &slog.HandlerOptions{Level: level, ReplaceAttr: o.replaceAttr})
+}
+
+// isTerminal reports whether w is a character device such as a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorWriter colorizes the level token of text records. The text handler quotes values
+// with control characters, so colors are added to the formatted record instead of the level attribute.
+type colorWriter struct {
+	w io.Writer
+}
+
+const levelToken = slog.LevelKey + "="
+
+func (cw *colorWriter) Write(p []byte) (int, error) {
+	start := bytes.Index(p, []byte(levelToken))
+	if start < 0 || (start > 0 && p[start-1] != ' ') {
+		return cw.write(p)
+	}
+
+	valueStart := start + len(levelToken)
+	valueEnd := valueStart + bytes.IndexAny(p[valueStart:], " \n")
+	if valueEnd < valueStart {
+		valueEnd = len(p)
+	}
+
+	color := levelColor(p[valueStart:valueEnd])
+	if color == "" {
+		return cw.write(p)
+	}
+
+	colored := make([]byte, 0, len(p)+len(color)+len(colorReset))
+	colored = append(colored, p[:valueStart]...)
+	colored = append(colored, color...)
+	colored = append(colored, p[valueStart:valueEnd]...)
+	colored = append(colored, colorReset...)
+	colored = append(colored, p[valueEnd:]...)
+
+	if _, err := cw.write(colored); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func (cw *colorWriter) write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	if err != nil {
+		return n, fmt.Errorf("write log record: %w", err)
+	}
+
+	return n, nil
+}
+
+// levelColor returns the color of a formatted level such as "WARN" or "ERROR+2".
+func levelColor(level []byte) string {
+	switch {
+	case bytes.HasPrefix(level, []byte("ERROR")):
+		return colorRed
+	case bytes.HasPrefix(level, []byte("WARN")):
+		return colorYellow
+	case bytes.HasPrefix(level, []byte("INFO")):
+		return colorGreen
+	case bytes.HasPrefix(level, []byte("DEBUG")):
+		return colorGray
+	default:
+		return ""
+	}
+}
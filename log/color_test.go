@@ -0,0 +1,55 @@
+package log_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	platformalog "github.com/platforma-dev/platforma/log"
+)
+
+func TestColorHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		opts      []platformalog.Option
+		level     slog.Level
+		wantColor string
+	}{
+		{name: "not a terminal", level: slog.LevelError},
+		{name: "disabled", opts: []platformalog.Option{platformalog.WithColor(false)}, level: slog.LevelError},
+		{name: "error", opts: []platformalog.Option{platformalog.WithColor(true)}, level: slog.LevelError, wantColor: "\x1b[31m"},
+		{name: "warn", opts: []platformalog.Option{platformalog.WithColor(true)}, level: slog.LevelWarn, wantColor: "\x1b[33m"},
+		{name: "info", opts: []platformalog.Option{platformalog.WithColor(true)}, level: slog.LevelInfo, wantColor: "\x1b[32m"},
+		{name: "custom level", opts: []platformalog.Option{platformalog.WithColor(true)}, level: platformalog.LevelInfoForced, wantColor: "\x1b[31m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			logger := slog.New(platformalog.NewColorHandler(&buf, slog.LevelDebug, tt.opts...))
+			logger.Log(t.Context(), tt.level, "hello", "key", "level=value")
+
+			out := buf.String()
+			if tt.wantColor == "" {
+				if strings.Contains(out, "\x1b[") {
+					t.Fatalf("expected no color codes, got %q", out)
+				}
+				return
+			}
+
+			want := "level=" + tt.wantColor + tt.level.String() + "\x1b[0m "
+			if !strings.Contains(out, want) {
+				t.Fatalf("expected %q in output, got %q", want, out)
+			}
+
+			if !strings.Contains(out, `key="level=value"`) {
+				t.Fatalf("expected other attributes unchanged, got %q", out)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// DebugTraceKey is the context key that forces debug logging for the current trace.
+const DebugTraceKey contextKey = "debugTrace"
+
+// WithDebugTrace returns a context whose records are kept at every level by DebugTraceHandler
+// and TraceSamplingHandler, e.g. for a request of a user whose issue is being debugged.
+func WithDebugTrace(ctx context.Context) context.Context {
+	return context.WithValue(ctx, DebugTraceKey, true)
+}
+
+// DebugTraceFromContext reports whether debug logging is forced for the trace in ctx.
+func DebugTraceFromContext(ctx context.Context) bool {
+	debug, _ := ctx.Value(DebugTraceKey).(bool)
+
+	return debug
+}
+
+// DebugTraces is a set of trace IDs whose records are logged at every level, see WithDebugTraces.
+// Trace IDs can be added and removed while loggers use the set.
+type DebugTraces struct {
+	mu  sync.RWMutex
+	ids map[string]struct{}
+}
+
+// NewDebugTraces creates a DebugTraces set with the given trace IDs.
+func NewDebugTraces(traceIDs ...string) *DebugTraces {
+	t := &DebugTraces{ids: make(map[string]struct{}, len(traceIDs))}
+	for _, id := range traceIDs {
+		t.ids[id] = struct{}{}
+	}
+
+	return t
+}
+
+// Add adds a trace ID to the set.
+func (t *DebugTraces) Add(traceID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.ids[traceID] = struct{}{}
+}
+
+// Remove removes a trace ID from the set.
+func (t *DebugTraces) Remove(traceID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.ids, traceID)
+}
+
+// Contains reports whether the trace ID is in the set.
+func (t *DebugTraces) Contains(traceID string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	_, ok := t.ids[traceID]
+
+	return ok
+}
+
+// DebugTraceHandler wraps a slog.Handler and drops records below a level, except for traces
+// marked with WithDebugTrace or whose ID in TraceIDKey is in a DebugTraces set. Their records are
+// passed with a WithDebugTrace context, so that TraceSamplingHandler keeps them too.
+// The wrapped handler must be enabled for debug records.
+type DebugTraceHandler struct {
+	handler slog.Handler
+	level   slog.Leveler
+	traces  *DebugTraces
+}
+
+var _ slog.Handler = (*DebugTraceHandler)(nil)
+
+// NewDebugTraceHandler creates a DebugTraceHandler that keeps records at or above level and
+// records of debugged traces at every level. traces may be nil to only honor WithDebugTrace.
+func NewDebugTraceHandler(h slog.Handler, level slog.Leveler, traces *DebugTraces) *DebugTraceHandler {
+	return &DebugTraceHandler{handler: h, level: level, traces: traces}
+}
+
+// WithDebugTraces makes loggers created by New log records of the traces in traces and of
+// contexts marked with WithDebugTrace at every level, see DebugTraceHandler.
+func WithDebugTraces(traces *DebugTraces) Option {
+	return func(o *options) {
+		o.debugTraces = traces
+	}
+}
+
+// Enabled reports whether a record at the given level is kept for the trace in ctx.
+func (h *DebugTraceHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if level < h.level.Level() && !h.debug(ctx) {
+		return false
+	}
+
+	return h.handler.Enabled(h.context(ctx), level)
+}
+
+// Handle passes the record to the wrapped handler if it is at or above the level or its trace is debugged.
+func (h *DebugTraceHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < h.level.Level() && !h.debug(ctx) {
+		return nil
+	}
+
+	if err := h.handler.Handle(h.context(ctx), r); err != nil {
+		return fmt.Errorf("failed to handle log record: %w", err)
+	}
+
+	return nil
+}
+
+// WithAttrs returns a DebugTraceHandler wrapping the handler with the given attributes.
+func (h *DebugTraceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &DebugTraceHandler{handler: h.handler.WithAttrs(attrs), level: h.level, traces: h.traces}
+}
+
+// WithGroup returns a DebugTraceHandler wrapping the handler with the given group.
+func (h *DebugTraceHandler) WithGroup(name string) slog.Handler {
+	return &DebugTraceHandler{handler: h.handler.WithGroup(name), level: h.level, traces: h.traces}
+}
+
+func (h *DebugTraceHandler) debug(ctx context.Context) bool {
+	if DebugTraceFromContext(ctx) {
+		return true
+	}
+
+	traceID, ok := ctx.Value(TraceIDKey).(string)
+
+	return ok && h.traces != nil && h.traces.Contains(traceID)
+}
+
+// context marks ctx with WithDebugTrace if its trace is debugged and it isn't marked yet.
+func (h *DebugTraceHandler) context(ctx context.Context) context.Context {
+	if DebugTraceFromContext(ctx) || !h.debug(ctx) {
+		return ctx
+	}
+
+	return WithDebugTrace(ctx)
+}
+
+// wrapDebugTraceHandler wraps h in a DebugTraceHandler when WithDebugTraces is set.
+func wrapDebugTraceHandler(h slog.Handler, level slog.Leveler, o options) slog.Handler {
+	if o.debugTraces == nil {
+		return h
+	}
+
+	return NewDebugTraceHandler(h, level, o.debugTraces)
+}
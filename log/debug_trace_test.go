@@ -0,0 +1,75 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	platformalog "github.com/platforma-dev/platforma/log"
+)
+
+func TestDebugTraces(t *testing.T) {
+	t.Parallel()
+
+	traces := platformalog.NewDebugTraces("trace-debugged")
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		opts      []platformalog.Option
+		wantDebug bool
+	}{
+		{name: "debugged trace keeps debug", ctx: context.WithValue(context.Background(), platformalog.TraceIDKey, "trace-debugged"), wantDebug: true},
+		{name: "other trace drops debug", ctx: context.WithValue(context.Background(), platformalog.TraceIDKey, "trace-other"), wantDebug: false},
+		{name: "no trace drops debug", ctx: context.Background(), wantDebug: false},
+		{name: "context flag keeps debug", ctx: platformalog.WithDebugTrace(context.Background()), wantDebug: true},
+		{
+			name:      "debugged trace bypasses trace sampling",
+			ctx:       platformalog.WithTraceSampled(context.WithValue(context.Background(), platformalog.TraceIDKey, "trace-debugged"), false),
+			opts:      []platformalog.Option{platformalog.WithTraceSampling(platformalog.LevelError)},
+			wantDebug: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			opts := append([]platformalog.Option{platformalog.WithDebugTraces(traces)}, tt.opts...)
+			logger := platformalog.New(&buf, "json", platformalog.LevelInfo, nil, opts...)
+
+			logger.DebugContext(tt.ctx, "debug record")
+
+			if got := strings.Contains(buf.String(), "debug record"); got != tt.wantDebug {
+				t.Fatalf("expected debug record written: %v, got output %s", tt.wantDebug, buf.String())
+			}
+		})
+	}
+
+	t.Run("traces can be changed at runtime", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		debugTraces := platformalog.NewDebugTraces()
+		logger := platformalog.New(&buf, "json", platformalog.LevelInfo, nil, platformalog.WithDebugTraces(debugTraces))
+		ctx := context.WithValue(context.Background(), platformalog.TraceIDKey, "trace-1")
+
+		logger.DebugContext(ctx, "before add")
+		debugTraces.Add("trace-1")
+		logger.DebugContext(ctx, "after add")
+		debugTraces.Remove("trace-1")
+		logger.DebugContext(ctx, "after remove")
+		logger.InfoContext(ctx, "info record")
+
+		output := buf.String()
+		if strings.Contains(output, "before add") || !strings.Contains(output, "after add") || strings.Contains(output, "after remove") {
+			t.Fatalf("expected only debug record of debugged trace, got output %s", output)
+		}
+
+		if !strings.Contains(output, "info record") {
+			t.Fatalf("expected info record, got output %s", output)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Environment variables read by NewWideEventLoggerFromEnv.
+const (
+	EnvLogFormat        = "LOG_FORMAT"
+	EnvLogLevel         = "LOG_LEVEL"
+	EnvLogSampleRate    = "LOG_SAMPLE_RATE"
+	EnvLogSlowThreshold = "LOG_SLOW_THRESHOLD"
+)
+
+const (
+	defaultEnvLogFormat   = "text"
+	defaultSlowThreshold  = time.Second
+	defaultKeepHTTPStatus = 500
+)
+
+// ErrInvalidEnv is returned when a logging environment variable has an invalid value.
+var ErrInvalidEnv = errors.New("invalid logging environment variable")
+
+// WithLevel sets the minimum level of records written by a wide-event logger. Defaults to LevelDebug.
+func WithLevel(level slog.Leveler) Option {
+	return func(o *options) {
+		o.level = level
+	}
+}
+
+// NewWideEventLoggerFromEnv creates a wide-event logger configured from environment variables:
+//   - LOG_FORMAT: "json" or "text" (default "text")
+//   - LOG_LEVEL: minimum level, e.g. "debug", "info", "warn", "error" (default "debug")
+//   - LOG_SAMPLE_RATE: random keep rate of the DefaultSampler, from 0 to 1 (default 1)
+//   - LOG_SLOW_THRESHOLD: duration above which events are always kept, e.g. "500ms" (default 1s)
+//
+// A DefaultSampler that keeps events with HTTP status 500 and above is used if LOG_SAMPLE_RATE or
+// LOG_SLOW_THRESHOLD is set, otherwise all events are kept. opts are applied after the environment,
+// so they take precedence.
+func NewWideEventLoggerFromEnv(w io.Writer, contextKeys map[string]any, opts ...Option) (*WideEventLogger, error) {
+	format := defaultEnvLogFormat
+	if value, ok := os.LookupEnv(EnvLogFormat); ok {
+		if value != "json" && value != "text" {
+			return nil, fmt.Errorf("%w: %s=%q, expected json or text", ErrInvalidEnv, EnvLogFormat, value)
+		}
+		format = value
+	}
+
+	var envOpts []Option
+	if value, ok := os.LookupEnv(EnvLogLevel); ok {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(value)); err != nil {
+			return nil, fmt.Errorf("%w: %s=%q: %w", ErrInvalidEnv, EnvLogLevel, value, err)
+		}
+		envOpts = append(envOpts, WithLevel(level))
+	}
+
+	sampler, err := samplerFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWideEventLogger(w, sampler, format, contextKeys, append(envOpts, opts...)...), nil
+}
+
+// samplerFromEnv returns a DefaultSampler if sampling is configured in the environment,
+// or a sampler that keeps all events otherwise.
+func samplerFromEnv() (Sampler, error) {
+	rateValue, rateSet := os.LookupEnv(EnvLogSampleRate)
+	thresholdValue, thresholdSet := os.LookupEnv(EnvLogSlowThreshold)
+	if !rateSet && !thresholdSet {
+		return SamplerFunc(func(_ context.Context, _ *Event) bool { return true }), nil
+	}
+
+	rate := 1.0
+	if rateSet {
+		parsed, err := strconv.ParseFloat(rateValue, 64)
+		if err != nil || parsed < 0 || parsed > 1 {
+			return nil, fmt.Errorf("%w: %s=%q, expected a number from 0 to 1", ErrInvalidEnv, EnvLogSampleRate, rateValue)
+		}
+		rate = parsed
+	}
+
+	threshold := defaultSlowThreshold
+	if thresholdSet {
+		parsed, err := time.ParseDuration(thresholdValue)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s=%q: %w", ErrInvalidEnv, EnvLogSlowThreshold, thresholdValue, err)
+		}
+		threshold = parsed
+	}
+
+	return NewDefaultSampler(threshold, defaultKeepHTTPStatus, rate), nil
+}
@@ -0,0 +1,105 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	platformalog "github.com/platforma-dev/platforma/log"
+)
+
+//nolint:paralleltest // t.Setenv can't be used in parallel tests
+func TestNewWideEventLoggerFromEnv(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := platformalog.NewWideEventLoggerFromEnv(&buf, nil)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		event := platformalog.NewEvent("job")
+		event.SetLevel(platformalog.LevelDebug)
+		logger.WriteEvent(context.Background(), event)
+
+		if !strings.Contains(buf.String(), "name=job") {
+			t.Fatalf("expected text record with debug event, got %q", buf.String())
+		}
+	})
+
+	t.Run("format and level", func(t *testing.T) {
+		t.Setenv(platformalog.EnvLogFormat, "json")
+		t.Setenv(platformalog.EnvLogLevel, "warn")
+
+		var buf bytes.Buffer
+		logger, err := platformalog.NewWideEventLoggerFromEnv(&buf, nil)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		logger.WriteEvent(context.Background(), platformalog.NewEvent("info"))
+		if buf.Len() != 0 {
+			t.Fatalf("expected info event to be filtered, got %q", buf.String())
+		}
+
+		event := platformalog.NewEvent("warn")
+		event.SetLevel(platformalog.LevelWarn)
+		logger.WriteEvent(context.Background(), event)
+
+		record := decodeRecord(t, buf.Bytes())
+		if record["name"] != "warn" {
+			t.Fatalf("expected warn event, got %v", record)
+		}
+	})
+
+	t.Run("sampler", func(t *testing.T) {
+		t.Setenv(platformalog.EnvLogFormat, "json")
+		t.Setenv(platformalog.EnvLogSampleRate, "0")
+		t.Setenv(platformalog.EnvLogSlowThreshold, "1h")
+
+		var buf bytes.Buffer
+		logger, err := platformalog.NewWideEventLoggerFromEnv(&buf, nil)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		logger.WriteEvent(context.Background(), platformalog.NewEvent("fast"))
+		if buf.Len() != 0 {
+			t.Fatalf("expected fast event to be dropped, got %q", buf.String())
+		}
+
+		event := platformalog.NewEvent("failed")
+		event.AddError(errors.New("boom"))
+		logger.WriteEvent(context.Background(), event)
+
+		record := decodeRecord(t, buf.Bytes())
+		if record["samplingReason"] != platformalog.SamplingReasonError {
+			t.Fatalf("expected event kept by error rule, got %v", record)
+		}
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			key   string
+			value string
+		}{
+			{name: "format", key: platformalog.EnvLogFormat, value: "xml"},
+			{name: "level", key: platformalog.EnvLogLevel, value: "loud"},
+			{name: "sample rate", key: platformalog.EnvLogSampleRate, value: "2"},
+			{name: "slow threshold", key: platformalog.EnvLogSlowThreshold, value: "soon"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Setenv(tt.key, tt.value)
+
+				_, err := platformalog.NewWideEventLoggerFromEnv(&bytes.Buffer{}, nil)
+				if !errors.Is(err, platformalog.ErrInvalidEnv) {
+					t.Fatalf("expected invalid env error, got: %v", err)
+				}
+			})
+		}
+	})
+}
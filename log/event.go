@@ -1,8 +1,11 @@
 package log
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 	"maps"
+	"runtime"
 	"slices"
 	"sync"
 	"time"
@@ -19,16 +22,68 @@ type Event struct {
 	attrs     map[string]any
 	steps     []stepRecord
 	errors    []errorRecord
+	// pc is the program counter of the code that created the event, see WithSource.
+	pc uintptr
+	// now is the clock of the logger that created the event, see WithClock. Nil means time.Now.
+	now func() time.Time
+
+	// checkpointer writes partial snapshots of the event, see Checkpoint.
+	checkpointer func(ctx context.Context, e *Event)
+	// pool is set for events from WideEventLogger.StartPooled, which are returned to it after being written.
+	pool *sync.Pool
 }
 
 // NewEvent creates a new wide event.
 func NewEvent(name string) *Event {
+	return newEvent(name, callerPC(3))
+}
+
+func newEvent(name string, pc uintptr) *Event {
 	return &Event{
 		name:      name,
 		timestamp: time.Now(),
 		level:     LevelDebug,
 		attrs:     map[string]any{},
+		pc:        pc,
+	}
+}
+
+// callerPC returns the program counter of the caller skip frames up, counting
+// runtime.Callers as 0 and callerPC as 1.
+func callerPC(skip int) uintptr {
+	var pcs [1]uintptr
+	runtime.Callers(skip, pcs[:])
+
+	return pcs[0]
+}
+
+// clock returns the current time of the event's clock.
+func (e *Event) clock() time.Time {
+	if e.now == nil {
+		return time.Now()
 	}
+
+	return e.now()
+}
+
+// setClock makes the event use now, restarting it at the current time of now. A nil now is ignored.
+func (e *Event) setClock(now func() time.Time) {
+	if now == nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.now = now
+	e.timestamp = now()
+}
+
+// source returns the location the event was created at.
+func (e *Event) source() *slog.Source {
+	frame, _ := runtime.CallersFrames([]uintptr{e.pc}).Next()
+
+	return &slog.Source{Function: frame.Function, File: frame.File, Line: frame.Line}
 }
 
 // SetLevel sets event level if it is higher than the current one.
@@ -53,6 +108,22 @@ func (e *Event) AddAttrs(attrs map[string]any) {
 	maps.Copy(e.attrs, attrs)
 }
 
+// AddGroup nests attrs under the name key, e.g. AddGroup("request", ...) serializes as
+// `"request": {...}` in JSON. Repeated calls with the same name merge into the group,
+// replacing a non-group attribute of that name. Like other attributes, groups named
+// after builtin or reserved keys are not emitted.
+func (e *Event) AddGroup(name string, attrs map[string]any) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	existing, _ := e.attrs[name].(map[string]any)
+	group := make(map[string]any, len(existing)+len(attrs))
+	maps.Copy(group, existing)
+	maps.Copy(group, attrs)
+
+	e.attrs[name] = group
+}
+
 // AddStep appends an event step and potentially escalates level.
 func (e *Event) AddStep(level Level, name string) {
 	e.mu.Lock()
@@ -61,7 +132,7 @@ func (e *Event) AddStep(level Level, name string) {
 	e.setLevelNoLock(level)
 
 	e.steps = append(e.steps, stepRecord{
-		Timestamp: time.Now(),
+		Timestamp: e.clock(),
 		Level:     level,
 		Name:      name,
 	})
@@ -79,17 +150,95 @@ func (e *Event) AddError(err error) {
 	e.setLevelNoLock(LevelError)
 
 	e.errors = append(e.errors, errorRecord{
-		Timestamp: time.Now(),
+		Timestamp: e.clock(),
 		Error:     err.Error(),
 	})
 }
 
+// Errorf formats an error like fmt.Errorf, records it on the event and returns it,
+// so that handlers can `return ev.Errorf(...)` with the returned error matching the event.
+func (e *Event) Errorf(format string, args ...any) error {
+	err := fmt.Errorf(format, args...) //nolint:err113 // format is provided by the caller
+
+	e.AddError(err)
+
+	return err
+}
+
+// Reset clears attributes, steps, errors, level and duration of the event and restarts its clock,
+// keeping its name, so that the event can be reused for another operation without allocating.
+// Reset must not be called while the event may still be used elsewhere, e.g. by a goroutine
+// started by the handler or a logger that has not written it yet.
+func (e *Event) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	name, pc, pool, now := e.name, e.pc, e.pool, e.now
+	e.reset()
+	e.name, e.pc, e.pool, e.now = name, pc, pool, now
+	e.timestamp = e.clock()
+}
+
+// reset zeroes the event, keeping the storage of attributes, steps and errors for reuse.
+func (e *Event) reset() {
+	if e.attrs == nil {
+		e.attrs = map[string]any{}
+	}
+	clear(e.attrs)
+
+	e.name = ""
+	e.timestamp = time.Time{}
+	e.level = LevelDebug
+	e.duration = 0
+	e.steps = e.steps[:0]
+	e.errors = e.errors[:0]
+	e.pc = 0
+	e.now = nil
+	e.checkpointer = nil
+	e.pool = nil
+}
+
+// release returns an event from WideEventLogger.StartPooled to its pool.
+func (e *Event) release() {
+	e.mu.Lock()
+	pool := e.pool
+	if pool != nil {
+		e.reset()
+	}
+	e.mu.Unlock()
+
+	if pool != nil {
+		pool.Put(e)
+	}
+}
+
 // Finish stores current event duration.
 func (e *Event) Finish() {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	e.duration = time.Since(e.timestamp)
+	e.duration = e.clock().Sub(e.timestamp)
+}
+
+// Checkpoint emits a snapshot of the event marked with partial: true without finishing it,
+// so that progress of long-running operations is visible before they complete.
+// It is a no-op for events that are not bound to a logger, e.g. events created
+// outside of WideEventMiddleware.
+func (e *Event) Checkpoint(ctx context.Context) {
+	e.mu.Lock()
+	checkpointer := e.checkpointer
+	e.mu.Unlock()
+
+	if checkpointer != nil {
+		checkpointer(ctx, e)
+	}
+}
+
+func (e *Event) setCheckpointer(checkpointer func(ctx context.Context, e *Event)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.checkpointer = checkpointer
 }
 
 // HasErrors returns true if the event has errors.
@@ -124,40 +273,72 @@ func (e *Event) Name() string {
 	return e.name
 }
 
-// Attr returns an event attribute by key.
+// Attr returns an event attribute by key, e.g. for samplers and middlewares that depend on
+// attributes set earlier. Groups added with AddGroup are returned as a copy, so that changing
+// the returned map doesn't modify the event.
 func (e *Event) Attr(key string) (any, bool) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
 	value, ok := e.attrs[key]
+	if group, isGroup := value.(map[string]any); isGroup {
+		return maps.Clone(group), true
+	}
 
 	return value, ok
 }
 
 // ToAttrs converts event to slog attributes.
 func (e *Event) ToAttrs() []slog.Attr {
-	return e.toAttrs(nil)
+	return e.toAttrs(nil, options{}, false)
 }
 
-func (e *Event) toAttrs(additionalReservedAttrKeys []string) []slog.Attr {
+// toAttrs converts event to slog attributes. Partial snapshots report the duration elapsed so far.
+func (e *Event) toAttrs(additionalReservedAttrKeys []string, opts options, partial bool) []slog.Attr {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	steps := make([]map[string]any, 0, len(e.steps))
-	for _, step := range e.steps {
-		steps = append(steps, map[string]any{
-			"timestamp": step.Timestamp,
+	duration := e.duration
+	if partial {
+		duration = e.clock().Sub(e.timestamp)
+	}
+
+	emittedSteps := e.steps
+	if opts.maxSteps > 0 && len(emittedSteps) > opts.maxSteps {
+		emittedSteps = emittedSteps[:opts.maxSteps]
+	}
+	stepsDropped := len(e.steps) - len(emittedSteps)
+
+	steps := make([]map[string]any, 0, len(emittedSteps))
+	previous := e.timestamp
+	for _, step := range emittedSteps {
+		delta := step.Timestamp.Sub(previous)
+		previous = step.Timestamp
+
+		stepAttrs := map[string]any{
+			"timestamp": opts.timestamp(step.Timestamp),
 			"level":     step.Level.String(),
 			"name":      step.Name,
-		})
+			"deltaMs":   delta.Milliseconds(),
+		}
+		if opts.slowStepThreshold > 0 && delta >= opts.slowStepThreshold {
+			stepAttrs["slow"] = true
+		}
+
+		steps = append(steps, stepAttrs)
 	}
 
 	eventErrors := make([]map[string]any, 0, len(e.errors))
 	for _, eventError := range e.errors {
-		eventErrors = append(eventErrors, map[string]any{
-			"timestamp": eventError.Timestamp,
+		errorAttrs := map[string]any{
+			"timestamp": opts.timestamp(eventError.Timestamp),
 			"error":     eventError.Error,
-		})
+		}
+		if opts.errorStacks && len(eventError.Stack) > 0 {
+			errorAttrs["stack"] = formatStack(eventError.Stack)
+		}
+
+		eventErrors = append(eventErrors, errorAttrs)
 	}
 
 	builtinAttrKeys := wideEventBuiltinAttrKeys()
@@ -171,16 +352,39 @@ func (e *Event) toAttrs(additionalReservedAttrKeys []string) []slog.Attr {
 	}
 
 	attrs := make([]slog.Attr, 0, len(e.attrs)+len(builtinAttrKeys))
+	if opts.addSource {
+		reservedAttrKeys = append(reservedAttrKeys, slog.SourceKey)
+	}
+
+	durationAttr := slog.Duration("duration", duration)
+	if opts.durationMs {
+		durationAttr = slog.Int64("durationMs", duration.Milliseconds())
+		reservedAttrKeys = append(reservedAttrKeys, "durationMs")
+	}
+
 	attrs = append(attrs,
 		slog.String("name", e.name),
-		slog.Time("timestamp", e.timestamp),
-		slog.Duration("duration", e.duration),
+		slog.Time("timestamp", opts.timestamp(e.timestamp)),
+		durationAttr,
+		slog.String("schemaVersion", opts.eventSchemaVersion()),
 	)
 
+	if opts.addSource && e.pc != 0 {
+		attrs = append(attrs, slog.Any(slog.SourceKey, e.source()))
+	}
+
+	if partial {
+		attrs = append(attrs, slog.Bool("partial", true))
+	}
+
 	if len(steps) > 0 {
 		attrs = append(attrs, slog.Any("steps", steps))
 	}
 
+	if stepsDropped > 0 {
+		attrs = append(attrs, slog.Int("stepsDropped", stepsDropped))
+	}
+
 	if len(eventErrors) > 0 {
 		attrs = append(attrs, slog.Any("errors", eventErrors))
 	}
@@ -214,6 +418,8 @@ type stepRecord struct {
 type errorRecord struct {
 	Timestamp time.Time
 	Error     string
+	// Stack holds the program counters captured by AddErrorWithStack.
+	Stack []uintptr
 }
 
 func wideEventBuiltinAttrKeys() []string {
@@ -221,7 +427,12 @@ func wideEventBuiltinAttrKeys() []string {
 		"name",
 		"timestamp",
 		"duration",
+		"schemaVersion",
+		"partial",
 		"steps",
+		"stepsDropped",
 		"errors",
+		"sampled",
+		"samplingReason",
 	}
 }
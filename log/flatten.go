@@ -0,0 +1,104 @@
+package log
+
+import (
+	"cmp"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// WithFlattenAttrs makes a wide-event logger flatten nested maps, steps and errors into
+// dotted keys, e.g. `request: {method: GET}` becomes `request.method: GET` and the first
+// step name becomes `steps.0.name`. Keys that are already flat win over flattened ones
+// that collide with them; among flattened keys the first in sorted order wins.
+func WithFlattenAttrs() Option {
+	return func(o *options) {
+		o.flatten = true
+	}
+}
+
+// flattenAttrs expands nested values of attrs into dotted top-level attributes.
+func flattenAttrs(attrs []slog.Attr) []slog.Attr {
+	flat := make([]slog.Attr, 0, len(attrs))
+	seen := make(map[string]struct{}, len(attrs))
+
+	// flat attributes claim their keys first so that collisions resolve the same way every time
+	var nested []slog.Attr
+	for _, a := range attrs {
+		if isNested(a.Value) {
+			nested = append(nested, a)
+			continue
+		}
+		flat = append(flat, a)
+		seen[a.Key] = struct{}{}
+	}
+
+	for _, a := range nested {
+		flat = appendFlattened(flat, seen, a.Key, a.Value)
+	}
+
+	return flat
+}
+
+func appendFlattened(flat []slog.Attr, seen map[string]struct{}, prefix string, value slog.Value) []slog.Attr {
+	value = value.Resolve()
+
+	switch {
+	case value.Kind() == slog.KindGroup:
+		children := slices.Clone(value.Group())
+		slices.SortStableFunc(children, func(a, b slog.Attr) int {
+			return cmp.Compare(a.Key, b.Key)
+		})
+		for _, child := range children {
+			flat = appendFlattened(flat, seen, prefix+"."+child.Key, child.Value)
+		}
+
+		return flat
+	case value.Kind() == slog.KindAny:
+		switch v := value.Any().(type) {
+		case map[string]any:
+			for _, key := range slices.Sorted(maps.Keys(v)) {
+				flat = appendFlattened(flat, seen, prefix+"."+key, slog.AnyValue(v[key]))
+			}
+
+			return flat
+		case []map[string]any:
+			for i, item := range v {
+				flat = appendFlattened(flat, seen, prefix+"."+strconv.Itoa(i), slog.AnyValue(item))
+			}
+
+			return flat
+		case []any:
+			for i, item := range v {
+				flat = appendFlattened(flat, seen, prefix+"."+strconv.Itoa(i), slog.AnyValue(item))
+			}
+
+			return flat
+		}
+	}
+
+	if _, ok := seen[prefix]; ok {
+		return flat
+	}
+	seen[prefix] = struct{}{}
+
+	return append(flat, slog.Attr{Key: prefix, Value: value})
+}
+
+func isNested(value slog.Value) bool {
+	value = value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		return true
+	}
+	if value.Kind() != slog.KindAny {
+		return false
+	}
+
+	switch value.Any().(type) {
+	case map[string]any, []map[string]any, []any:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,91 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	platformalog "github.com/platforma-dev/platforma/log"
+)
+
+func TestWithFlattenAttrs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nested attrs become dotted keys", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil, platformalog.WithFlattenAttrs())
+
+		ev := platformalog.NewEvent("http")
+		ev.AddAttrs(map[string]any{
+			"request": map[string]any{
+				"method":  "GET",
+				"headers": map[string]any{"accept": "json"},
+			},
+		})
+		ev.AddStep(platformalog.LevelInfo, "handled")
+		ev.AddError(errors.New("boom"))
+		logger.WriteEvent(context.Background(), ev)
+
+		record := decodeRecord(t, buf.Bytes())
+		if _, ok := record["request"]; ok {
+			t.Fatalf("expected request to be flattened, got %v", record)
+		}
+
+		if record["request.method"] != "GET" {
+			t.Fatalf("expected request.method to be %q, got %v", "GET", record["request.method"])
+		}
+
+		if record["request.headers.accept"] != "json" {
+			t.Fatalf("expected request.headers.accept to be %q, got %v", "json", record["request.headers.accept"])
+		}
+
+		if record["steps.0.name"] != "handled" {
+			t.Fatalf("expected steps.0.name to be %q, got %v", "handled", record["steps.0.name"])
+		}
+
+		if record["errors.0.error"] != "boom" {
+			t.Fatalf("expected errors.0.error to be %q, got %v", "boom", record["errors.0.error"])
+		}
+	})
+
+	t.Run("flat key wins on collision", func(t *testing.T) {
+		t.Parallel()
+
+		for range 10 {
+			var buf bytes.Buffer
+			logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil, platformalog.WithFlattenAttrs())
+
+			ev := platformalog.NewEvent("http")
+			ev.AddAttrs(map[string]any{
+				"request.method": "POST",
+				"request":        map[string]any{"method": "GET"},
+			})
+			logger.WriteEvent(context.Background(), ev)
+
+			record := decodeRecord(t, buf.Bytes())
+			if record["request.method"] != "POST" {
+				t.Fatalf("expected flat request.method to win, got %v", record["request.method"])
+			}
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil)
+
+		ev := platformalog.NewEvent("http")
+		ev.AddAttrs(map[string]any{"request": map[string]any{"method": "GET"}})
+		logger.WriteEvent(context.Background(), ev)
+
+		record := decodeRecord(t, buf.Bytes())
+		request, ok := record["request"].(map[string]any)
+		if !ok || request["method"] != "GET" {
+			t.Fatalf("expected nested request, got %v", record)
+		}
+	})
+}
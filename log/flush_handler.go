@@ -0,0 +1,79 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// Syncer is implemented by writers that buffer records, such as AsyncWriter and *os.File.
+// Sync flushes buffered records to their destination.
+type Syncer interface {
+	Sync() error
+}
+
+// FlushHandler wraps a slog.Handler and synchronously flushes a Syncer after handling
+// records at or above a level, so that e.g. an error logged right before a crash is not lost.
+type FlushHandler struct {
+	handler slog.Handler
+	syncer  Syncer
+	level   slog.Leveler
+}
+
+var _ slog.Handler = (*FlushHandler)(nil)
+
+// NewFlushHandler creates a FlushHandler that calls s.Sync after h handles a record at or above level.
+func NewFlushHandler(h slog.Handler, s Syncer, level slog.Leveler) *FlushHandler {
+	return &FlushHandler{handler: h, syncer: s, level: level}
+}
+
+// WithFlushOnLevel makes loggers flush s after records at or above level, see FlushHandler.
+// It is typically used with the AsyncWriter the logger writes to.
+func WithFlushOnLevel(s Syncer, level slog.Leveler) Option {
+	return func(o *options) {
+		o.flushSyncer = s
+		o.flushLevel = level
+	}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (h *FlushHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle passes the record to the wrapped handler and flushes the syncer for records at or above the flush level.
+func (h *FlushHandler) Handle(ctx context.Context, r slog.Record) error {
+	err := h.handler.Handle(ctx, r)
+	if err != nil {
+		return fmt.Errorf("failed to handle log record: %w", err)
+	}
+
+	if r.Level < h.level.Level() {
+		return nil
+	}
+
+	if err := h.syncer.Sync(); err != nil {
+		return fmt.Errorf("failed to flush log record: %w", err)
+	}
+
+	return nil
+}
+
+// WithAttrs returns a FlushHandler wrapping the handler with the given attributes.
+func (h *FlushHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &FlushHandler{handler: h.handler.WithAttrs(attrs), syncer: h.syncer, level: h.level}
+}
+
+// WithGroup returns a FlushHandler wrapping the handler with the given group.
+func (h *FlushHandler) WithGroup(name string) slog.Handler {
+	return &FlushHandler{handler: h.handler.WithGroup(name), syncer: h.syncer, level: h.level}
+}
+
+// wrapFlushHandler wraps h in a FlushHandler when WithFlushOnLevel is set.
+func wrapFlushHandler(h slog.Handler, o options) slog.Handler {
+	if o.flushSyncer == nil || o.flushLevel == nil {
+		return h
+	}
+
+	return NewFlushHandler(h, o.flushSyncer, o.flushLevel)
+}
@@ -0,0 +1,105 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	platformalog "github.com/platforma-dev/platforma/log"
+)
+
+func TestFlushHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("error flushes immediately while info does not", func(t *testing.T) {
+		t.Parallel()
+
+		w := &syncCountingWriter{}
+		handler := platformalog.NewFlushHandler(slog.NewJSONHandler(w, nil), w, platformalog.LevelError)
+		logger := slog.New(handler)
+
+		logger.Info("started")
+		if w.syncCount() != 0 {
+			t.Fatalf("expected no flush after info, got %d", w.syncCount())
+		}
+
+		logger.Error("failed")
+		if w.syncCount() != 1 {
+			t.Fatalf("expected 1 flush after error, got %d", w.syncCount())
+		}
+
+		// attributes and groups keep flushing
+		logger.With("key", "value").WithGroup("group").Error("failed again")
+		if w.syncCount() != 2 {
+			t.Fatalf("expected 2 flushes, got %d", w.syncCount())
+		}
+	})
+
+	t.Run("option flushes loggers created by the package", func(t *testing.T) {
+		t.Parallel()
+
+		w := &syncCountingWriter{}
+		logger := platformalog.New(w, "json", platformalog.LevelInfo, nil, platformalog.WithFlushOnLevel(w, platformalog.LevelError))
+		logger.InfoContext(context.Background(), "started")
+		logger.ErrorContext(context.Background(), "failed")
+
+		wideLogger := platformalog.NewWideEventLogger(w, nil, "json", nil, platformalog.WithFlushOnLevel(w, platformalog.LevelError))
+		ev := platformalog.NewEvent("job")
+		wideLogger.WriteEvent(context.Background(), ev)
+		ev = platformalog.NewEvent("job")
+		ev.SetLevel(platformalog.LevelError)
+		wideLogger.WriteEvent(context.Background(), ev)
+
+		if w.syncCount() != 2 {
+			t.Fatalf("expected 2 flushes, got %d", w.syncCount())
+		}
+	})
+
+	t.Run("error is written before async writer flush returns", func(t *testing.T) {
+		t.Parallel()
+
+		slow := &slowWriter{delay: 20 * time.Millisecond}
+		w := platformalog.NewAsyncWriter(slow, 10, 0)
+		defer w.Close()
+
+		logger := platformalog.New(w, "json", platformalog.LevelInfo, nil, platformalog.WithFlushOnLevel(w, platformalog.LevelError))
+		logger.Info("started")
+		logger.Error("failed")
+
+		if slow.count() != 2 {
+			t.Fatalf("expected 2 records to be written after error, got %d", slow.count())
+		}
+	})
+}
+
+type syncCountingWriter struct {
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	syncs int
+}
+
+func (w *syncCountingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buf.Write(p)
+}
+
+func (w *syncCountingWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.syncs++
+
+	return nil
+}
+
+func (w *syncCountingWriter) syncCount() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.syncs
+}
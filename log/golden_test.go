@@ -0,0 +1,103 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	platformalog "github.com/platforma-dev/platforma/log"
+)
+
+// envUpdateGolden rewrites golden files with the current output when set to 1.
+const envUpdateGolden = "UPDATE_GOLDEN"
+
+func TestWideEventGolden(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil)
+	handler := platformalog.NewWideEventMiddleware(logger, "", nil, platformalog.WithCapturedHeaders("User-Agent", "Authorization")).
+		Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			event := platformalog.EventFromContext(r.Context())
+			event.AddAttrs(map[string]any{"user.id": "u1"})
+			event.AddGroup("order", map[string]any{"id": "o1", "items": 2})
+			event.AddStep(platformalog.LevelInfo, "loaded user")
+			event.AddStep(platformalog.LevelWarn, "payment retried")
+			event.AddError(errors.New("payment declined"))
+			w.WriteHeader(http.StatusPaymentRequired)
+		}))
+
+	req := httptest.NewRequest(http.MethodPost, "/orders?draft=true", nil)
+	req.Header.Set("User-Agent", "golden-test")
+	req.Header.Set("Authorization", "Bearer secret")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assertGolden(t, "http_event.golden.json", buf.Bytes())
+}
+
+// assertGolden compares a JSON wide-event record with testdata/name after normalizing volatile fields.
+// Run the tests with UPDATE_GOLDEN=1 to rewrite the golden file after an intended format change.
+func assertGolden(t *testing.T, name string, record []byte) {
+	t.Helper()
+
+	got := normalizeRecord(t, record)
+	path := filepath.Join("testdata", name)
+
+	if os.Getenv(envUpdateGolden) == "1" {
+		if err := os.WriteFile(path, got, 0o600); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Fatalf("record does not match %s, run with %s=1 to update:\ngot:\n%s\nwant:\n%s", path, envUpdateGolden, got, want)
+	}
+}
+
+// normalizeRecord replaces timestamps and durations with placeholders and indents the record with sorted keys.
+func normalizeRecord(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	record := decodeRecord(t, data)
+	normalizeVolatile(record)
+
+	for _, key := range []string{"steps", "errors"} {
+		items, _ := record[key].([]any)
+		for _, item := range items {
+			if fields, ok := item.(map[string]any); ok {
+				normalizeVolatile(fields)
+			}
+		}
+	}
+
+	normalized, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to encode record: %v", err)
+	}
+
+	return append(normalized, '\n')
+}
+
+func normalizeVolatile(fields map[string]any) {
+	for _, key := range []string{"timestamp", "time"} {
+		if _, ok := fields[key]; ok {
+			fields[key] = "TIMESTAMP"
+		}
+	}
+
+	for _, key := range []string{"duration", "durationMs", "deltaMs"} {
+		if _, ok := fields[key]; ok {
+			fields[key] = 0
+		}
+	}
+}
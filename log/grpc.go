@@ -0,0 +1,76 @@
+package log
+
+import "fmt"
+
+// GRPCCodeAttr is the event attribute read by WithGRPCCodeLevels.
+const GRPCCodeAttr = "grpcCode"
+
+// WithGRPCCodeLevels makes wide-event loggers raise the level of events with a `grpcCode` attribute
+// according to the gRPC status code, since gRPC services don't report HTTP statuses:
+//   - Unknown, Unimplemented, Internal, Unavailable and DataLoss: LevelError
+//   - DeadlineExceeded, PermissionDenied, ResourceExhausted, FailedPrecondition, Aborted and OutOfRange: LevelWarn
+//   - other codes: LevelInfo
+//
+// The attribute may hold a codes.Code, any other fmt.Stringer or string with the code name, or an integer code.
+// Like Event.SetLevel, the level is only raised, so events with errors stay at LevelError.
+func WithGRPCCodeLevels() Option {
+	return func(o *options) {
+		o.grpcCodeLevels = true
+	}
+}
+
+// applyGRPCCodeLevel raises the event level according to its gRPC status code attribute.
+func applyGRPCCodeLevel(e *Event) {
+	value, ok := e.Attr(GRPCCodeAttr)
+	if !ok {
+		return
+	}
+
+	if name, ok := grpcCodeName(value); ok {
+		e.SetLevel(grpcCodeLevel(name))
+	}
+}
+
+// grpcCodeName returns the name of a gRPC status code given by name or number.
+func grpcCodeName(value any) (string, bool) {
+	var code int
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case fmt.Stringer:
+		return v.String(), true
+	case int:
+		code = v
+	case int32:
+		code = int(v)
+	case int64:
+		code = int(v)
+	case uint32:
+		code = int(v)
+	default:
+		return "", false
+	}
+
+	// names as returned by codes.Code.String, indexed by code
+	names := [...]string{
+		"OK", "Canceled", "Unknown", "InvalidArgument", "DeadlineExceeded", "NotFound",
+		"AlreadyExists", "PermissionDenied", "ResourceExhausted", "FailedPrecondition",
+		"Aborted", "OutOfRange", "Unimplemented", "Internal", "Unavailable", "DataLoss", "Unauthenticated",
+	}
+	if code < 0 || code >= len(names) {
+		return "", false
+	}
+
+	return names[code], true
+}
+
+func grpcCodeLevel(name string) Level {
+	switch name {
+	case "Unknown", "Unimplemented", "Internal", "Unavailable", "DataLoss":
+		return LevelError
+	case "DeadlineExceeded", "PermissionDenied", "ResourceExhausted", "FailedPrecondition", "Aborted", "OutOfRange":
+		return LevelWarn
+	default:
+		return LevelInfo
+	}
+}
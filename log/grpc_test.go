@@ -0,0 +1,118 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	platformalog "github.com/platforma-dev/platforma/log"
+)
+
+// grpcCode mimics codes.Code from google.golang.org/grpc/codes.
+type grpcCode uint32
+
+func (c grpcCode) String() string {
+	if c == 14 {
+		return "Unavailable"
+	}
+
+	return "OK"
+}
+
+func TestWithGRPCCodeLevels(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		code  any
+		level string
+	}{
+		{name: "ok", code: "OK", level: "INFO"},
+		{name: "not found", code: "NotFound", level: "INFO"},
+		{name: "unauthenticated", code: 16, level: "INFO"},
+		{name: "deadline exceeded", code: "DeadlineExceeded", level: "WARN"},
+		{name: "resource exhausted", code: int32(8), level: "WARN"},
+		{name: "internal", code: "Internal", level: "ERROR"},
+		{name: "internal code", code: uint32(13), level: "ERROR"},
+		{name: "unavailable stringer", code: grpcCode(14), level: "ERROR"},
+		{name: "unknown value", code: 42, level: "DEBUG"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil, platformalog.WithGRPCCodeLevels())
+
+			event := platformalog.NewEvent("grpc.request")
+			event.AddAttrs(map[string]any{platformalog.GRPCCodeAttr: tt.code})
+			logger.WriteEvent(context.Background(), event)
+
+			record := decodeRecord(t, buf.Bytes())
+			if record["level"] != tt.level {
+				t.Fatalf("expected level %s, got %v", tt.level, record["level"])
+			}
+		})
+	}
+
+	t.Run("level is only raised", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil, platformalog.WithGRPCCodeLevels())
+
+		event := platformalog.NewEvent("grpc.request")
+		event.AddError(errors.New("boom"))
+		event.AddAttrs(map[string]any{platformalog.GRPCCodeAttr: "OK"})
+		logger.WriteEvent(context.Background(), event)
+
+		record := decodeRecord(t, buf.Bytes())
+		if record["level"] != "ERROR" {
+			t.Fatalf("expected level ERROR, got %v", record["level"])
+		}
+	})
+
+	t.Run("default sampler keeps error events", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		sampler := platformalog.NewDefaultSampler(time.Hour, 500, 0)
+		logger := platformalog.NewWideEventLogger(&buf, sampler, "json", nil,
+			platformalog.WithGRPCCodeLevels(), platformalog.WithSamplingDecision())
+
+		for _, code := range []string{"NotFound", "Internal"} {
+			event := platformalog.NewEvent("grpc.request")
+			event.AddAttrs(map[string]any{platformalog.GRPCCodeAttr: code})
+			logger.WriteEvent(context.Background(), event)
+		}
+
+		record := decodeRecord(t, buf.Bytes())
+		if record[platformalog.GRPCCodeAttr] != "Internal" {
+			t.Fatalf("expected only the Internal event to be kept, got %s", buf.String())
+		}
+
+		sampling, _ := record["sampling"].(map[string]any)
+		if sampling["reason"] != platformalog.SamplingReasonError || sampling["rule"] != "level" {
+			t.Fatalf("expected event kept by the level rule, got %v", record["sampling"])
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil)
+
+		event := platformalog.NewEvent("grpc.request")
+		event.AddAttrs(map[string]any{platformalog.GRPCCodeAttr: "Internal"})
+		logger.WriteEvent(context.Background(), event)
+
+		record := decodeRecord(t, buf.Bytes())
+		if record["level"] != "DEBUG" {
+			t.Fatalf("expected level DEBUG, got %v", record["level"])
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package log
+
+import "time"
+
+const latencyBucketAttrKey = "latencyBucket"
+
+// Latency buckets assigned by LatencyBuckets.
+const (
+	LatencyBucketFast   = "fast"
+	LatencyBucketNormal = "normal"
+	LatencyBucketSlow   = "slow"
+)
+
+// LatencyBuckets classifies event durations for SLO dashboards, see WithLatencyBuckets.
+type LatencyBuckets struct {
+	Normal time.Duration // Durations from Normal on are "normal", shorter ones are "fast"
+	Slow   time.Duration // Durations from Slow on are "slow"
+}
+
+// Bucket returns the bucket of an event duration: LatencyBucketFast below Normal,
+// LatencyBucketNormal from Normal and below Slow, and LatencyBucketSlow from Slow on.
+func (b LatencyBuckets) Bucket(duration time.Duration) string {
+	switch {
+	case duration >= b.Slow:
+		return LatencyBucketSlow
+	case duration >= b.Normal:
+		return LatencyBucketNormal
+	default:
+		return LatencyBucketFast
+	}
+}
+
+// WithLatencyBuckets makes wide-event loggers add a `latencyBucket` attribute to written events,
+// computed from the event duration by buckets. Records of the simple logging methods and
+// checkpoints don't get it. WideEventMiddleware events are tagged by the logger they are written to.
+func WithLatencyBuckets(buckets LatencyBuckets) Option {
+	return func(o *options) {
+		o.latencyBuckets = &buckets
+	}
+}
@@ -0,0 +1,96 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	platformalog "github.com/platforma-dev/platforma/log"
+)
+
+func TestLatencyBuckets(t *testing.T) {
+	t.Parallel()
+
+	buckets := platformalog.LatencyBuckets{Normal: 100 * time.Millisecond, Slow: time.Second}
+
+	tests := []struct {
+		duration time.Duration
+		bucket   string
+	}{
+		{duration: 0, bucket: platformalog.LatencyBucketFast},
+		{duration: 100*time.Millisecond - 1, bucket: platformalog.LatencyBucketFast},
+		{duration: 100 * time.Millisecond, bucket: platformalog.LatencyBucketNormal},
+		{duration: time.Second - 1, bucket: platformalog.LatencyBucketNormal},
+		{duration: time.Second, bucket: platformalog.LatencyBucketSlow},
+		{duration: time.Minute, bucket: platformalog.LatencyBucketSlow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.duration.String(), func(t *testing.T) {
+			t.Parallel()
+
+			if bucket := buckets.Bucket(tt.duration); bucket != tt.bucket {
+				t.Fatalf("expected bucket %s for %s, got %s", tt.bucket, tt.duration, bucket)
+			}
+		})
+	}
+}
+
+func TestWithLatencyBuckets(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		buckets platformalog.LatencyBuckets
+		bucket  string
+	}{
+		{name: "fast", buckets: platformalog.LatencyBuckets{Normal: time.Hour, Slow: 2 * time.Hour}, bucket: platformalog.LatencyBucketFast},
+		{name: "normal", buckets: platformalog.LatencyBuckets{Normal: 0, Slow: time.Hour}, bucket: platformalog.LatencyBucketNormal},
+		{name: "slow", buckets: platformalog.LatencyBuckets{}, bucket: platformalog.LatencyBucketSlow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil, platformalog.WithLatencyBuckets(tt.buckets))
+
+			event := platformalog.NewEvent("http.request")
+			event.AddAttrs(map[string]any{"latencyBucket": "spoofed"})
+			logger.WriteEvent(context.Background(), event)
+
+			record := decodeRecord(t, buf.Bytes())
+			if record["latencyBucket"] != tt.bucket {
+				t.Fatalf("expected latencyBucket %s, got %v", tt.bucket, record["latencyBucket"])
+			}
+		})
+	}
+
+	t.Run("simple logs are not tagged", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil, platformalog.WithLatencyBuckets(platformalog.LatencyBuckets{}))
+		logger.Info("hello")
+
+		record := decodeRecord(t, buf.Bytes())
+		if _, ok := record["latencyBucket"]; ok {
+			t.Fatalf("expected no latencyBucket, got %v", record)
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil)
+		logger.WriteEvent(context.Background(), platformalog.NewEvent("http.request"))
+
+		record := decodeRecord(t, buf.Bytes())
+		if _, ok := record["latencyBucket"]; ok {
+			t.Fatalf("expected no latencyBucket, got %v", record)
+		}
+	})
+}
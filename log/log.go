@@ -7,6 +7,7 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"slices"
 )
 
 type logger interface {
@@ -82,12 +83,62 @@ func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
 }
 
 // New creates a new slog.Logger with the specified type (json/text), log level, and additional context keys to include.
-func New(w io.Writer, loggerType string, level Level, contextKeys map[string]any) *slog.Logger {
+// Bare string context keys are deprecated: they are still honored, but New logs a warning for each of them.
+// Use keys of an unexported type instead, see WithContextKey.
+func New(w io.Writer, loggerType string, level Level, contextKeys map[string]any, opts ...Option) *slog.Logger {
+	o := newOptions(opts)
+	handlerOpts := &slog.HandlerOptions{Level: level, ReplaceAttr: o.replaceAttr}
+	if o.debugTraces != nil {
+		// DebugTraceHandler applies level instead, letting debugged traces through
+		handlerOpts.Level = min(level, LevelDebug)
+	}
+	if o.utc {
+		handlerOpts.ReplaceAttr = ChainReplaceAttr(utcTimeAttr, o.replaceAttr)
+	}
+	keys, deprecated := mergeContextKeys(contextKeys, o.contextKeys)
+
+	var handler slog.Handler
 	if loggerType == "json" {
-		return slog.New(&contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}), contextKeys})
+		handler = slog.NewJSONHandler(w, handlerOpts)
+	} else {
+		handler = slog.NewTextHandler(w, handlerOpts)
+	}
+	if o.processAttrs {
+		handler = handler.WithAttrs(processAttrs())
+	}
+
+	l := slog.New(&contextHandler{wrapSeverityHandler(wrapDebugTraceHandler(wrapTraceSamplingHandler(wrapFlushHandler(handler, o), o), level, o), o), keys})
+
+	warnDeprecatedContextKeys(l, deprecated)
+
+	return l
+}
+
+// mergeContextKeys combines context keys from the map argument and WithContextKey options.
+// Bare string keys can collide with context values of unrelated packages. They are still looked up,
+// but their names are returned as deprecated so that a warning can be logged.
+func mergeContextKeys(contextKeys, optionKeys map[string]any) (map[string]any, []string) {
+	keys := make(map[string]any, len(contextKeys)+len(optionKeys))
+	var deprecated []string
+
+	for _, source := range []map[string]any{contextKeys, optionKeys} {
+		for name, key := range source {
+			if _, ok := key.(string); ok {
+				deprecated = append(deprecated, name)
+			}
+			keys[name] = key
+		}
 	}
 
-	return slog.New(&contextHandler{slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}), contextKeys})
+	slices.Sort(deprecated)
+
+	return keys, deprecated
+}
+
+func warnDeprecatedContextKeys(l logger, deprecated []string) {
+	for _, name := range deprecated {
+		l.Warn("context key of type string is deprecated and will be ignored in a future release, use a key of an unexported type instead", "contextKey", name)
+	}
 }
 
 // Debug logs a message at Debug level.
@@ -129,3 +180,15 @@ func Error(msg string, args ...any) {
 func ErrorContext(ctx context.Context, msg string, args ...any) {
 	Logger.ErrorContext(ctx, msg, args...)
 }
+
+// ErrorReturn logs a message at Error level with context and err as the "error" attribute, and returns err
+// unchanged, so that error paths can log and return in one statement:
+//
+//	if err != nil {
+//		return log.ErrorReturn(ctx, "failed to load user", err, "userId", id)
+//	}
+func ErrorReturn(ctx context.Context, msg string, err error, args ...any) error {
+	Logger.ErrorContext(ctx, msg, append([]any{"error", err}, args...)...)
+
+	return err
+}
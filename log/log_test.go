@@ -0,0 +1,36 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	platformalog "github.com/platforma-dev/platforma/log"
+)
+
+//nolint:paralleltest // replaces the package-level Logger
+func TestErrorReturn(t *testing.T) {
+	previous := platformalog.Logger
+	t.Cleanup(func() { platformalog.SetDefault(previous) })
+
+	var buf bytes.Buffer
+	platformalog.SetDefault(platformalog.New(&buf, "json", platformalog.LevelInfo, nil))
+
+	ctx := context.WithValue(context.Background(), platformalog.TraceIDKey, "trace-1")
+	errLoad := errors.New("connection refused")
+
+	err := platformalog.ErrorReturn(ctx, "failed to load user", errLoad, "userId", "u1")
+	if err != errLoad { //nolint:errorlint // the same error value must be returned
+		t.Fatalf("expected the same error to be returned, got: %v", err)
+	}
+
+	record := decodeRecord(t, buf.Bytes())
+	if record["level"] != "ERROR" || record["msg"] != "failed to load user" {
+		t.Fatalf("expected error record with message, got %v", record)
+	}
+
+	if record["error"] != "connection refused" || record["userId"] != "u1" || record["traceId"] != "trace-1" {
+		t.Fatalf("expected error, args and context attributes, got %v", record)
+	}
+}
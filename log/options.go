@@ -0,0 +1,250 @@
+package log
+
+import (
+	"log/slog"
+	"maps"
+	"os"
+	"time"
+)
+
+// Option configures loggers and middlewares created by this package.
+type Option func(*options)
+
+type options struct {
+	slowStepThreshold  time.Duration
+	maxSteps           int
+	samplingDecision   bool
+	replaceAttr        func(groups []string, a slog.Attr) slog.Attr
+	idGenerator        IDGenerator
+	fieldNames         map[string]string
+	contextKeys        map[string]any
+	flatten            bool
+	flushSyncer        Syncer
+	flushLevel         slog.Leveler
+	traceSamplingLevel slog.Leveler
+	capturedHeaders    []string
+	durationMs         bool
+	level              slog.Leveler
+	addSource          bool
+	color              *bool
+	grpcCodeLevels     bool
+	processAttrs       bool
+	latencyBuckets     *LatencyBuckets
+	utc                bool
+	errorStacks        bool
+	severity           func(level slog.Level) int
+	now                func() time.Time
+	debugTraces        *DebugTraces
+	schemaVersion      string
+}
+
+func newOptions(opts []Option) options {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithClock makes wide-event loggers use now instead of time.Now for the timestamps, steps, errors
+// and durations of events they create with StartPooled or WideEventMiddleware, e.g. to assert exact
+// durations in tests with a fake clock. Events created with NewEvent keep using time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// WithSlowStepThreshold marks wide-event steps that took at least threshold
+// since the previous step (or event start) with `slow: true`.
+func WithSlowStepThreshold(threshold time.Duration) Option {
+	return func(o *options) {
+		o.slowStepThreshold = threshold
+	}
+}
+
+// WithMaxSteps limits the number of steps emitted per wide event to the first maxSteps.
+// The number of omitted steps is reported as `stepsDropped`. Zero means no limit.
+func WithMaxSteps(maxSteps int) Option {
+	return func(o *options) {
+		o.maxSteps = maxSteps
+	}
+}
+
+// WithSamplingDecision adds a `sampling` object to emitted wide events with the reason
+// the sampler kept the event, the matched rule field and the effective random keep rate.
+func WithSamplingDecision() Option {
+	return func(o *options) {
+		o.samplingDecision = true
+	}
+}
+
+// WithReplaceAttr sets a slog ReplaceAttr function applied to every attribute.
+// Wide-event loggers apply it after their built-in time and empty message stripping.
+// Use ChainReplaceAttr to combine several functions.
+func WithReplaceAttr(fn func(groups []string, a slog.Attr) slog.Attr) Option {
+	return func(o *options) {
+		o.replaceAttr = fn
+	}
+}
+
+// ChainReplaceAttr combines ReplaceAttr functions into one that calls them in order,
+// passing the result of each to the next. Nil functions are skipped, and the chain
+// stops once an attribute is dropped by returning an empty slog.Attr.
+func ChainReplaceAttr(fns ...func(groups []string, a slog.Attr) slog.Attr) func(groups []string, a slog.Attr) slog.Attr {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		for _, fn := range fns {
+			if fn == nil {
+				continue
+			}
+
+			a = fn(groups, a)
+			if a.Equal(slog.Attr{}) {
+				return a
+			}
+		}
+
+		return a
+	}
+}
+
+// WithIDGenerator sets the generator used by TraceIDMiddleware for new trace IDs.
+func WithIDGenerator(generator IDGenerator) Option {
+	return func(o *options) {
+		o.idGenerator = generator
+	}
+}
+
+// WithFieldNames renames top-level fields of records written by a wide-event logger,
+// e.g. {"name": "message", "duration": "durationMs"}. Fields without an override keep
+// their default names. Renaming happens after WithReplaceAttr, which sees default names.
+func WithFieldNames(fieldNames map[string]string) Option {
+	return func(o *options) {
+		o.fieldNames = maps.Clone(fieldNames)
+	}
+}
+
+// renameAttr returns a ReplaceAttr function that renames top-level attributes, or nil when there is nothing to rename.
+func renameAttr(fieldNames map[string]string) func(groups []string, a slog.Attr) slog.Attr {
+	if len(fieldNames) == 0 {
+		return nil
+	}
+
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) > 0 {
+			return a
+		}
+
+		if name, ok := fieldNames[a.Key]; ok && name != "" {
+			a.Key = name
+		}
+
+		return a
+	}
+}
+
+// WithContextKey adds a context value to every record under outputName, like the contextKeys map
+// passed to New and NewWideEventLogger. key should be a value of an unexported type, as recommended
+// for context keys, so that it cannot collide with keys of other packages. Bare string keys still work
+// but are deprecated: New logs a warning for each of them.
+func WithContextKey(outputName string, key any) Option {
+	return func(o *options) {
+		if o.contextKeys == nil {
+			o.contextKeys = map[string]any{}
+		}
+		o.contextKeys[outputName] = key
+	}
+}
+
+// WithCapturedHeaders makes WideEventMiddleware add the listed request headers to the event
+// as `request.header.<name>` attributes, with lowercase names. Missing headers are skipped,
+// and values of sensitive headers such as Authorization or Cookie are redacted.
+func WithCapturedHeaders(headers ...string) Option {
+	return func(o *options) {
+		o.capturedHeaders = append(o.capturedHeaders, headers...)
+	}
+}
+
+// WithDurationMs makes wide-event loggers emit the event duration as `durationMs`,
+// an integer number of milliseconds, instead of `duration` as a Go duration.
+func WithDurationMs() Option {
+	return func(o *options) {
+		o.durationMs = true
+	}
+}
+
+// WithSource makes wide-event loggers add a `source` object with the function, file and line
+// where the event was created by NewEvent, or where a simple log method such as Info was called.
+// Events created by WideEventMiddleware report the middleware.
+func WithSource() Option {
+	return func(o *options) {
+		o.addSource = true
+	}
+}
+
+// WithProcessAttrs makes New add `host` and `pid` attributes with the hostname and process ID
+// to every record, e.g. to tell replicas apart. Both are read once when the logger is created,
+// and `host` is omitted if the hostname can't be determined.
+func WithProcessAttrs() Option {
+	return func(o *options) {
+		o.processAttrs = true
+	}
+}
+
+// processAttrs returns the attributes added by WithProcessAttrs.
+func processAttrs() []slog.Attr {
+	attrs := []slog.Attr{slog.Int("pid", os.Getpid())}
+	if host, err := os.Hostname(); err == nil {
+		attrs = append(attrs, slog.String("host", host))
+	}
+
+	return attrs
+}
+
+// WithUTC makes New and wide-event loggers emit timestamps in UTC instead of the local time zone:
+// the record time of New, and the event, step and error timestamps of wide events.
+func WithUTC() Option {
+	return func(o *options) {
+		o.utc = true
+	}
+}
+
+// utcTimeAttr converts the record time to UTC.
+func utcTimeAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 && a.Key == slog.TimeKey && a.Value.Kind() == slog.KindTime {
+		a.Value = slog.TimeValue(a.Value.Time().UTC())
+	}
+
+	return a
+}
+
+// SchemaVersion is the default version of the wide-event format, emitted as schemaVersion on every event.
+// It changes when fields are renamed or removed, so that consumers can tell formats apart.
+const SchemaVersion = "1"
+
+// WithSchemaVersion makes wide-event loggers emit version instead of SchemaVersion as the
+// schemaVersion field of every event, e.g. for an application-specific event format.
+func WithSchemaVersion(version string) Option {
+	return func(o *options) {
+		o.schemaVersion = version
+	}
+}
+
+// eventSchemaVersion returns the version set with WithSchemaVersion, or SchemaVersion.
+func (o options) eventSchemaVersion() string {
+	if o.schemaVersion == "" {
+		return SchemaVersion
+	}
+
+	return o.schemaVersion
+}
+
+// timestamp returns t in UTC if WithUTC is set.
+func (o options) timestamp(t time.Time) time.Time {
+	if o.utc {
+		return t.UTC()
+	}
+
+	return t
+}
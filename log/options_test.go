@@ -0,0 +1,387 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	platformalog "github.com/platforma-dev/platforma/log"
+)
+
+func TestChainReplaceAttr(t *testing.T) {
+	t.Parallel()
+
+	t.Run("runs replacers in order", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		first := func(_ []string, a slog.Attr) slog.Attr {
+			calls = append(calls, "first:"+a.Value.String())
+			if a.Key == "password" {
+				return slog.String(a.Key, "***")
+			}
+			return a
+		}
+		second := func(_ []string, a slog.Attr) slog.Attr {
+			calls = append(calls, "second:"+a.Value.String())
+			return slog.String(a.Key, strings.ToUpper(a.Value.String()))
+		}
+
+		replaced := platformalog.ChainReplaceAttr(first, nil, second)(nil, slog.String("password", "secret"))
+
+		if replaced.Value.String() != "***" {
+			t.Fatalf("expected redacted value, got %q", replaced.Value.String())
+		}
+
+		expected := []string{"first:secret", "second:***"}
+		if strings.Join(calls, ",") != strings.Join(expected, ",") {
+			t.Fatalf("expected calls %v, got %v", expected, calls)
+		}
+	})
+
+	t.Run("stops after attribute is dropped", func(t *testing.T) {
+		t.Parallel()
+
+		drop := func(_ []string, _ slog.Attr) slog.Attr { return slog.Attr{} }
+		called := false
+		next := func(_ []string, a slog.Attr) slog.Attr {
+			called = true
+			return a
+		}
+
+		replaced := platformalog.ChainReplaceAttr(drop, next)(nil, slog.String("key", "value"))
+		if !replaced.Equal(slog.Attr{}) {
+			t.Fatalf("expected attribute to be dropped, got %v", replaced)
+		}
+
+		if called {
+			t.Fatal("expected replacers after drop not to be called")
+		}
+	})
+
+	t.Run("New applies chained replacers", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		redact := func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == "token" {
+				return slog.String(a.Key, "***")
+			}
+			return a
+		}
+		dropTime := func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		}
+
+		logger := platformalog.New(&buf, "json", platformalog.LevelInfo, nil,
+			platformalog.WithReplaceAttr(platformalog.ChainReplaceAttr(redact, dropTime)))
+		logger.InfoContext(context.Background(), "login", "token", "abc")
+
+		record := decodeRecord(t, buf.Bytes())
+		if record["token"] != "***" {
+			t.Fatalf("expected token to be redacted, got %v", record["token"])
+		}
+
+		if _, ok := record[slog.TimeKey]; ok {
+			t.Fatalf("expected time to be dropped, got %v", record)
+		}
+	})
+
+	t.Run("wide event logger keeps built-in stripping", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil,
+			platformalog.WithReplaceAttr(func(_ []string, a slog.Attr) slog.Attr {
+				if a.Key == "token" {
+					return slog.String(a.Key, "***")
+				}
+				return a
+			}))
+
+		ev := platformalog.NewEvent("job")
+		ev.AddAttrs(map[string]any{"token": "abc"})
+		logger.WriteEvent(context.Background(), ev)
+
+		record := decodeRecord(t, buf.Bytes())
+		if record["token"] != "***" {
+			t.Fatalf("expected token to be redacted, got %v", record["token"])
+		}
+
+		if _, ok := record[slog.TimeKey]; ok {
+			t.Fatalf("expected time to be stripped, got %v", record)
+		}
+
+		if _, ok := record[slog.MessageKey]; ok {
+			t.Fatalf("expected empty message to be stripped, got %v", record)
+		}
+	})
+}
+
+type tenantKey struct{}
+
+func TestWithContextKey(t *testing.T) {
+	t.Parallel()
+
+	t.Run("typed key enriches records", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.New(&buf, "json", platformalog.LevelInfo, nil, platformalog.WithContextKey("tenantId", tenantKey{}))
+
+		ctx := context.WithValue(context.Background(), tenantKey{}, "tenant-1")
+		logger.InfoContext(ctx, "hello")
+
+		if !strings.Contains(buf.String(), `"tenantId":"tenant-1"`) {
+			t.Fatalf("expected tenantId in output, got %s", buf.String())
+		}
+	})
+
+	t.Run("typed key enriches wide events", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil, platformalog.WithContextKey("tenantId", tenantKey{}))
+
+		ctx := context.WithValue(context.Background(), tenantKey{}, "tenant-1")
+		logger.WriteEvent(ctx, platformalog.NewEvent("job"))
+
+		if !strings.Contains(buf.String(), `"tenantId":"tenant-1"`) {
+			t.Fatalf("expected tenantId in output, got %s", buf.String())
+		}
+	})
+
+	t.Run("bare string keys are deprecated", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.New(&buf, "json", platformalog.LevelInfo, map[string]any{"tenantId": "tenant"})
+
+		if !strings.Contains(buf.String(), `"contextKey":"tenantId"`) || !strings.Contains(buf.String(), "deprecated") {
+			t.Fatalf("expected deprecation warning about string context key, got %s", buf.String())
+		}
+		buf.Reset()
+
+		//nolint:staticcheck // verifies that bare string keys keep working
+		ctx := context.WithValue(context.Background(), "tenant", "tenant-1")
+		logger.InfoContext(ctx, "hello")
+
+		if !strings.Contains(buf.String(), `"tenantId":"tenant-1"`) {
+			t.Fatalf("expected string key to be looked up, got %s", buf.String())
+		}
+	})
+}
+
+func TestWithProcessAttrs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("adds host and pid to every record", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.New(&buf, "json", platformalog.LevelInfo, nil, platformalog.WithProcessAttrs())
+		logger.Info("first")
+		logger.Info("second")
+
+		host, err := os.Hostname()
+		if err != nil {
+			t.Fatalf("expected hostname, got error: %v", err)
+		}
+
+		for line := range strings.Lines(buf.String()) {
+			record := decodeRecord(t, []byte(line))
+			if record["host"] != host {
+				t.Fatalf("expected host %q, got %v", host, record["host"])
+			}
+			if record["pid"] != float64(os.Getpid()) {
+				t.Fatalf("expected pid %d, got %v", os.Getpid(), record["pid"])
+			}
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.New(&buf, "json", platformalog.LevelInfo, nil)
+		logger.Info("hello")
+
+		record := decodeRecord(t, buf.Bytes())
+		if _, ok := record["host"]; ok {
+			t.Fatalf("expected no host attribute, got %v", record)
+		}
+		if _, ok := record["pid"]; ok {
+			t.Fatalf("expected no pid attribute, got %v", record)
+		}
+	})
+}
+
+func TestWithUTC(t *testing.T) {
+	t.Parallel()
+
+	t.Run("record time of New", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.New(&buf, "json", platformalog.LevelInfo, nil, platformalog.WithUTC())
+		logger.Info("hello")
+
+		record := decodeRecord(t, buf.Bytes())
+		if timestamp, _ := record[slog.TimeKey].(string); !strings.HasSuffix(timestamp, "Z") {
+			t.Fatalf("expected UTC time, got %v", record[slog.TimeKey])
+		}
+	})
+
+	t.Run("wide event timestamps", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil, platformalog.WithUTC())
+
+		event := platformalog.NewEvent("job")
+		event.AddStep(platformalog.LevelInfo, "started")
+		event.AddError(errors.New("boom"))
+		logger.WriteEvent(context.Background(), event)
+
+		record := decodeRecord(t, buf.Bytes())
+		if timestamp, _ := record["timestamp"].(string); !strings.HasSuffix(timestamp, "Z") {
+			t.Fatalf("expected UTC event timestamp, got %v", record["timestamp"])
+		}
+
+		for _, step := range recordSteps(t, record) {
+			if timestamp, _ := step["timestamp"].(string); !strings.HasSuffix(timestamp, "Z") {
+				t.Fatalf("expected UTC step timestamp, got %v", step["timestamp"])
+			}
+		}
+
+		errs, _ := record["errors"].([]any)
+		for _, e := range errs {
+			eventError, _ := e.(map[string]any)
+			if timestamp, _ := eventError["timestamp"].(string); !strings.HasSuffix(timestamp, "Z") {
+				t.Fatalf("expected UTC error timestamp, got %v", eventError["timestamp"])
+			}
+		}
+	})
+}
+
+func TestWithClock(t *testing.T) {
+	t.Parallel()
+
+	t.Run("pooled event duration and steps", func(t *testing.T) {
+		t.Parallel()
+
+		clock := &fakeClock{now: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil, platformalog.WithClock(clock.Now), platformalog.WithDurationMs())
+
+		event := logger.StartPooled("job")
+		clock.Advance(250 * time.Millisecond)
+		event.AddStep(platformalog.LevelInfo, "loaded")
+		clock.Advance(1250 * time.Millisecond)
+		logger.WriteEvent(context.Background(), event)
+
+		record := decodeRecord(t, buf.Bytes())
+		if record["durationMs"] != float64(1500) {
+			t.Fatalf("expected durationMs 1500, got %v", record["durationMs"])
+		}
+
+		if record["timestamp"] != "2025-01-02T03:04:05Z" {
+			t.Fatalf("expected event timestamp from the clock, got %v", record["timestamp"])
+		}
+
+		steps, _ := record["steps"].([]any)
+		step, _ := steps[0].(map[string]any)
+		if step["deltaMs"] != float64(250) {
+			t.Fatalf("expected step deltaMs 250, got %v", step["deltaMs"])
+		}
+	})
+
+	t.Run("middleware event duration", func(t *testing.T) {
+		t.Parallel()
+
+		clock := &fakeClock{now: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil, platformalog.WithClock(clock.Now), platformalog.WithDurationMs())
+		handler := platformalog.NewHTTPMiddleware(logger).Wrap(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+			clock.Advance(42 * time.Millisecond)
+		}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		record := decodeRecord(t, buf.Bytes())
+		if record["durationMs"] != float64(42) {
+			t.Fatalf("expected durationMs 42, got %v", record["durationMs"])
+		}
+	})
+}
+
+func TestWithSchemaVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts []platformalog.Option
+		want string
+	}{
+		{name: "default version", want: platformalog.SchemaVersion},
+		{name: "configured version", opts: []platformalog.Option{platformalog.WithSchemaVersion("2024-06")}, want: "2024-06"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil, tt.opts...)
+
+			event := platformalog.NewEvent("job")
+			event.AddAttrs(map[string]any{"schemaVersion": "spoofed"})
+			logger.WriteEvent(context.Background(), event)
+			logger.Info("simple log")
+
+			lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+			if len(lines) != 2 {
+				t.Fatalf("expected 2 records, got %d", len(lines))
+			}
+
+			for _, line := range lines {
+				if record := decodeRecord(t, line); record["schemaVersion"] != tt.want {
+					t.Fatalf("expected schemaVersion %q, got %v", tt.want, record["schemaVersion"])
+				}
+			}
+		})
+	}
+}
+
+// fakeClock is a manually advanced clock for WithClock.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
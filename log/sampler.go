@@ -19,7 +19,46 @@ func (f SamplerFunc) ShouldSample(ctx context.Context, e *Event) bool {
 	return f(ctx, e)
 }
 
+// Sampling reasons reported in SamplingDecision.
+const (
+	SamplingReasonError   = "error"
+	SamplingReasonSlow    = "slow"
+	SamplingReasonStatus  = "status"
+	SamplingReasonRandom  = "random"
+	SamplingReasonDropped = "dropped"
+	SamplingReasonSampler = "sampler"
+)
+
+// SamplingDecision describes why a sampler kept or dropped an event.
+type SamplingDecision struct {
+	Keep bool
+	// Reason is one of the SamplingReason constants.
+	Reason string
+	// Rule is the event field that matched a forced-keep rule, e.g. "request.status".
+	Rule string
+	// Rate is the effective random keep rate when the decision was made by chance.
+	Rate float64
+	// Forced is true when a rule kept the event regardless of the random keep rate.
+	Forced bool
+}
+
+// DecisionSampler is a Sampler that can explain its decisions.
+type DecisionSampler interface {
+	Sampler
+	Decide(ctx context.Context, e *Event) SamplingDecision
+}
+
+// decide returns the decision of s, falling back to ShouldSample for samplers that cannot explain themselves.
+func decide(ctx context.Context, s Sampler, e *Event) SamplingDecision {
+	if ds, ok := s.(DecisionSampler); ok {
+		return ds.Decide(ctx, e)
+	}
+
+	return SamplingDecision{Keep: s.ShouldSample(ctx, e), Reason: SamplingReasonSampler}
+}
+
 // DefaultSampler samples by error, duration, status code, and random keep rate.
+// Events with errors or at LevelError or above are always kept.
 type DefaultSampler struct {
 	slowThreshold         time.Duration
 	keepHTTPStatusAtLeast int
@@ -36,13 +75,23 @@ func NewDefaultSampler(slowThreshold time.Duration, keepHTTPStatusAtLeast int, r
 }
 
 // ShouldSample decides if event should be logged.
-func (s *DefaultSampler) ShouldSample(_ context.Context, e *Event) bool {
+func (s *DefaultSampler) ShouldSample(ctx context.Context, e *Event) bool {
+	return s.Decide(ctx, e).Keep
+}
+
+// Decide decides if event should be logged and reports which rule made the decision.
+func (s *DefaultSampler) Decide(_ context.Context, e *Event) SamplingDecision {
 	if e.HasErrors() {
-		return true
+		return SamplingDecision{Keep: true, Reason: SamplingReasonError, Rule: "errors", Forced: true}
+	}
+
+	// the level may be raised without errors, e.g. by WithGRPCCodeLevels
+	if e.Level() >= LevelError {
+		return SamplingDecision{Keep: true, Reason: SamplingReasonError, Rule: "level", Forced: true}
 	}
 
 	if e.Duration() >= s.slowThreshold {
-		return true
+		return SamplingDecision{Keep: true, Reason: SamplingReasonSlow, Rule: "duration", Forced: true}
 	}
 
 	if e.Name() == "http.request" {
@@ -54,14 +103,80 @@ func (s *DefaultSampler) ShouldSample(_ context.Context, e *Event) bool {
 		}
 
 		if httpStatus >= s.keepHTTPStatusAtLeast {
-			return true
+			return SamplingDecision{Keep: true, Reason: SamplingReasonStatus, Rule: "request.status", Forced: true}
 		}
 	}
 
 	//nolint:gosec // Non-cryptographic sampling is sufficient for log event retention.
 	if rand.Float64() < s.randomKeepRate {
-		return true
+		return SamplingDecision{Keep: true, Reason: SamplingReasonRandom, Rate: s.randomKeepRate}
+	}
+
+	return SamplingDecision{Keep: false, Reason: SamplingReasonDropped, Rate: s.randomKeepRate}
+}
+
+// attrs converts the decision to the optional `sampling` wide-event attribute.
+func (d SamplingDecision) attrs() map[string]any {
+	attrs := map[string]any{
+		"reason": d.Reason,
+		"forced": d.Forced,
+	}
+	if d.Rule != "" {
+		attrs["rule"] = d.Rule
+	}
+	if d.Reason == SamplingReasonRandom || d.Reason == SamplingReasonDropped {
+		attrs["rate"] = d.Rate
+	}
+
+	return attrs
+}
+
+// AnySampler returns a sampler that keeps an event if any of samplers keeps it. Samplers are asked in order
+// until one keeps the event, and its decision is reported. If all drop the event, the first decision is reported.
+// Without samplers events are dropped.
+func AnySampler(samplers ...Sampler) DecisionSampler {
+	return combinedSampler{samplers: samplers, keep: true}
+}
+
+// AllSampler returns a sampler that keeps an event only if all samplers keep it. Samplers are asked in order
+// until one drops the event, and its decision is reported. If all keep the event, the first decision is reported.
+// Without samplers events are kept.
+func AllSampler(samplers ...Sampler) DecisionSampler {
+	return combinedSampler{samplers: samplers, keep: false}
+}
+
+// combinedSampler stops at the first decision whose Keep equals keep.
+type combinedSampler struct {
+	samplers []Sampler
+	keep     bool
+}
+
+// ShouldSample decides if event should be logged.
+func (s combinedSampler) ShouldSample(ctx context.Context, e *Event) bool {
+	return s.Decide(ctx, e).Keep
+}
+
+// Decide decides if event should be logged and reports the decision of the sampler that settled it.
+func (s combinedSampler) Decide(ctx context.Context, e *Event) SamplingDecision {
+	if len(s.samplers) == 0 {
+		if s.keep {
+			return SamplingDecision{Keep: false, Reason: SamplingReasonDropped}
+		}
+
+		return SamplingDecision{Keep: true, Reason: SamplingReasonSampler}
+	}
+
+	var first SamplingDecision
+	for i, sampler := range s.samplers {
+		decision := decide(ctx, sampler, e)
+		if decision.Keep == s.keep {
+			return decision
+		}
+
+		if i == 0 {
+			first = decision
+		}
 	}
 
-	return false
+	return first
 }
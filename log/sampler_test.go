@@ -0,0 +1,89 @@
+package log_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	platformalog "github.com/platforma-dev/platforma/log"
+)
+
+func TestAnySampler(t *testing.T) {
+	t.Parallel()
+
+	keep := platformalog.SamplerFunc(func(_ context.Context, _ *platformalog.Event) bool { return true })
+	drop := platformalog.SamplerFunc(func(_ context.Context, _ *platformalog.Event) bool { return false })
+	errorsOnly := platformalog.NewDefaultSampler(time.Hour, 500, 0)
+
+	t.Run("keeps when one keeps", func(t *testing.T) {
+		t.Parallel()
+
+		event := platformalog.NewEvent("job")
+		event.AddError(errors.New("boom"))
+
+		decision := platformalog.AnySampler(drop, errorsOnly, keep).Decide(context.Background(), event)
+		if !decision.Keep || decision.Reason != platformalog.SamplingReasonError {
+			t.Fatalf("expected event kept by error rule, got %+v", decision)
+		}
+	})
+
+	t.Run("drops when all drop", func(t *testing.T) {
+		t.Parallel()
+
+		sampler := platformalog.AnySampler(errorsOnly, drop)
+		decision := sampler.Decide(context.Background(), platformalog.NewEvent("job"))
+		if decision.Keep || decision.Reason != platformalog.SamplingReasonDropped {
+			t.Fatalf("expected event dropped with the first decision, got %+v", decision)
+		}
+
+		if sampler.ShouldSample(context.Background(), platformalog.NewEvent("job")) {
+			t.Fatal("expected ShouldSample to drop event")
+		}
+	})
+
+	t.Run("without samplers", func(t *testing.T) {
+		t.Parallel()
+
+		if platformalog.AnySampler().ShouldSample(context.Background(), platformalog.NewEvent("job")) {
+			t.Fatal("expected event to be dropped")
+		}
+	})
+}
+
+func TestAllSampler(t *testing.T) {
+	t.Parallel()
+
+	keep := platformalog.SamplerFunc(func(_ context.Context, _ *platformalog.Event) bool { return true })
+	drop := platformalog.SamplerFunc(func(_ context.Context, _ *platformalog.Event) bool { return false })
+	errorsOnly := platformalog.NewDefaultSampler(time.Hour, 500, 0)
+
+	t.Run("drops when one drops", func(t *testing.T) {
+		t.Parallel()
+
+		decision := platformalog.AllSampler(keep, errorsOnly, drop).Decide(context.Background(), platformalog.NewEvent("job"))
+		if decision.Keep || decision.Reason != platformalog.SamplingReasonDropped {
+			t.Fatalf("expected event dropped by the default sampler, got %+v", decision)
+		}
+	})
+
+	t.Run("keeps when all keep", func(t *testing.T) {
+		t.Parallel()
+
+		event := platformalog.NewEvent("job")
+		event.AddError(errors.New("boom"))
+
+		decision := platformalog.AllSampler(errorsOnly, keep).Decide(context.Background(), event)
+		if !decision.Keep || decision.Reason != platformalog.SamplingReasonError {
+			t.Fatalf("expected event kept with the first decision, got %+v", decision)
+		}
+	})
+
+	t.Run("without samplers", func(t *testing.T) {
+		t.Parallel()
+
+		if !platformalog.AllSampler().ShouldSample(context.Background(), platformalog.NewEvent("job")) {
+			t.Fatal("expected event to be kept")
+		}
+	})
+}
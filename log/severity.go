@@ -0,0 +1,76 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// SeverityKey is the key of the numeric severity added by WithSeverity.
+const SeverityKey = "severity"
+
+// WithSeverity makes New add a numeric `severity` attribute computed from the level of every record,
+// for platforms that expect one next to the level string. A nil mapping uses SyslogSeverity.
+func WithSeverity(mapping func(level slog.Level) int) Option {
+	if mapping == nil {
+		mapping = SyslogSeverity
+	}
+
+	return func(o *options) {
+		o.severity = mapping
+	}
+}
+
+// SyslogSeverity maps a level to its syslog severity: 7 for debug, 6 for info (including LevelInfoForced),
+// 4 for warn and 3 for error and above. Levels between the named ones map to the lower one, e.g. INFO+2 to 6.
+func SyslogSeverity(level slog.Level) int {
+	switch {
+	case level == LevelInfoForced:
+		return 6
+	case level >= LevelError:
+		return 3
+	case level >= LevelWarn:
+		return 4
+	case level >= LevelInfo:
+		return 6
+	default:
+		return 7
+	}
+}
+
+// severityHandler adds the numeric severity of each record before passing it to the wrapped handler.
+// Like other record attributes, the severity is nested in groups opened with WithGroup.
+type severityHandler struct {
+	handler slog.Handler
+	mapping func(level slog.Level) int
+}
+
+func wrapSeverityHandler(h slog.Handler, o options) slog.Handler {
+	if o.severity == nil {
+		return h
+	}
+
+	return &severityHandler{handler: h, mapping: o.severity}
+}
+
+func (h *severityHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *severityHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.AddAttrs(slog.Int(SeverityKey, h.mapping(r.Level)))
+
+	if err := h.handler.Handle(ctx, r); err != nil {
+		return fmt.Errorf("failed to handle log record: %w", err)
+	}
+
+	return nil
+}
+
+func (h *severityHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &severityHandler{handler: h.handler.WithAttrs(attrs), mapping: h.mapping}
+}
+
+func (h *severityHandler) WithGroup(name string) slog.Handler {
+	return &severityHandler{handler: h.handler.WithGroup(name), mapping: h.mapping}
+}
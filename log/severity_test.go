@@ -0,0 +1,90 @@
+package log_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	platformalog "github.com/platforma-dev/platforma/log"
+)
+
+func TestWithSeverity(t *testing.T) {
+	t.Parallel()
+
+	t.Run("adds syslog severity matching the level", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.New(&buf, "json", platformalog.LevelDebug, nil, platformalog.WithSeverity(nil)).With("component", "test")
+		logger.Debug("debug")
+		logger.Info("info")
+		logger.Warn("warn")
+		logger.Error("error")
+
+		want := map[string]float64{"DEBUG": 7, "INFO": 6, "WARN": 4, "ERROR": 3}
+		lines := 0
+		for line := range strings.Lines(buf.String()) {
+			lines++
+			record := decodeRecord(t, []byte(line))
+			level, _ := record["level"].(string)
+			if record["severity"] != want[level] {
+				t.Fatalf("expected severity %v for %s, got %v", want[level], level, record["severity"])
+			}
+		}
+
+		if lines != len(want) {
+			t.Fatalf("expected %d records, got %d", len(want), lines)
+		}
+	})
+
+	t.Run("custom mapping", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.New(&buf, "json", platformalog.LevelInfo, nil, platformalog.WithSeverity(func(level slog.Level) int {
+			return int(level) + 100
+		}))
+		logger.Warn("warn")
+
+		record := decodeRecord(t, buf.Bytes())
+		if record["severity"] != float64(104) {
+			t.Fatalf("expected custom severity 104, got %v", record["severity"])
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		platformalog.New(&buf, "json", platformalog.LevelInfo, nil).Info("info")
+
+		record := decodeRecord(t, buf.Bytes())
+		if _, ok := record["severity"]; ok {
+			t.Fatalf("expected no severity attribute, got %v", record)
+		}
+	})
+}
+
+func TestSyslogSeverity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		level slog.Level
+		want  int
+	}{
+		{level: platformalog.LevelDebug, want: 7},
+		{level: platformalog.LevelInfo, want: 6},
+		{level: platformalog.LevelInfo + 2, want: 6},
+		{level: platformalog.LevelWarn, want: 4},
+		{level: platformalog.LevelError, want: 3},
+		{level: platformalog.LevelError + 4, want: 3},
+		{level: platformalog.LevelInfoForced, want: 6},
+	}
+
+	for _, tt := range tests {
+		if got := platformalog.SyslogSeverity(tt.level); got != tt.want {
+			t.Errorf("expected severity %d for %s, got %d", tt.want, tt.level, got)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package log
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+)
+
+// EventSnapshot is a read-only copy of a finished event returned by Event.FinishWithoutEmit.
+// Callers can inspect it to decide whether and where to write it, e.g. with WideEventLogger.Emit.
+type EventSnapshot struct {
+	event *Event
+}
+
+// EventStep is a step of an event snapshot, see Event.AddStep.
+type EventStep struct {
+	Timestamp time.Time
+	Level     Level
+	Name      string
+}
+
+// FinishWithoutEmit stores the current event duration like Finish and returns a snapshot of the event
+// without writing it. Later changes of the event don't affect the snapshot.
+func (e *Event) FinishWithoutEmit() EventSnapshot {
+	e.Finish()
+
+	return EventSnapshot{event: e.clone()}
+}
+
+// clone returns a copy of the event that shares no mutable state with it.
+// The copy is not bound to a logger or pool.
+func (e *Event) clone() *Event {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	attrs := make(map[string]any, len(e.attrs))
+	for key, value := range e.attrs {
+		if group, ok := value.(map[string]any); ok {
+			value = maps.Clone(group)
+		}
+		attrs[key] = value
+	}
+
+	return &Event{
+		name:      e.name,
+		timestamp: e.timestamp,
+		level:     e.level,
+		duration:  e.duration,
+		attrs:     attrs,
+		steps:     slices.Clone(e.steps),
+		errors:    slices.Clone(e.errors),
+		pc:        e.pc,
+	}
+}
+
+// Name returns the event name.
+func (s EventSnapshot) Name() string {
+	return s.event.name
+}
+
+// Timestamp returns the time the event was created at.
+func (s EventSnapshot) Timestamp() time.Time {
+	return s.event.timestamp
+}
+
+// Duration returns the event duration.
+func (s EventSnapshot) Duration() time.Duration {
+	return s.event.duration
+}
+
+// Level returns the event level, including escalations by steps and errors.
+func (s EventSnapshot) Level() Level {
+	return s.event.level
+}
+
+// Status returns the HTTP status recorded by WideEventMiddleware, if any.
+func (s EventSnapshot) Status() (int, bool) {
+	status, ok := s.event.attrs["request.status"].(int)
+
+	return status, ok
+}
+
+// Attr returns an event attribute by key, see Event.Attr.
+func (s EventSnapshot) Attr(key string) (any, bool) {
+	return s.event.Attr(key)
+}
+
+// Attrs returns a copy of the event attributes.
+func (s EventSnapshot) Attrs() map[string]any {
+	return s.event.clone().attrs
+}
+
+// Steps returns the event steps in the order they were added.
+func (s EventSnapshot) Steps() []EventStep {
+	steps := make([]EventStep, 0, len(s.event.steps))
+	for _, step := range s.event.steps {
+		steps = append(steps, EventStep(step))
+	}
+
+	return steps
+}
+
+// Errors returns the messages of the event errors in the order they were added.
+func (s EventSnapshot) Errors() []string {
+	errs := make([]string, 0, len(s.event.errors))
+	for _, eventError := range s.event.errors {
+		errs = append(errs, eventError.Error)
+	}
+
+	return errs
+}
+
+// Emit writes a snapshot from Event.FinishWithoutEmit like WriteEvent, including sampling.
+// The snapshot is not modified, so it can be emitted to several loggers.
+func (l *WideEventLogger) Emit(ctx context.Context, s EventSnapshot) {
+	if s.event == nil {
+		return
+	}
+
+	e := s.event.clone()
+	if l.opts.grpcCodeLevels {
+		applyGRPCCodeLevel(e)
+	}
+	l.write(ctx, e, "", true)
+}
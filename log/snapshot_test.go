@@ -0,0 +1,93 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	platformalog "github.com/platforma-dev/platforma/log"
+)
+
+func TestEventFinishWithoutEmit(t *testing.T) {
+	t.Parallel()
+
+	t.Run("snapshot is not written", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil)
+
+		event := logger.StartPooled("http.request")
+		event.AddAttrs(map[string]any{"request.status": 503, "tenant": "acme"})
+		event.AddGroup("user", map[string]any{"id": "u1"})
+		event.AddStep(platformalog.LevelWarn, "retry")
+		event.AddError(errors.New("upstream unavailable"))
+
+		snapshot := event.FinishWithoutEmit()
+		if buf.Len() != 0 {
+			t.Fatalf("expected nothing to be written, got %q", buf.String())
+		}
+
+		if snapshot.Name() != "http.request" || snapshot.Level() != platformalog.LevelError || snapshot.Duration() <= 0 {
+			t.Fatalf("expected finished error event, got name %q, level %v, duration %v", snapshot.Name(), snapshot.Level(), snapshot.Duration())
+		}
+
+		if status, ok := snapshot.Status(); !ok || status != 503 {
+			t.Fatalf("expected status 503, got %d", status)
+		}
+
+		if steps := snapshot.Steps(); len(steps) != 1 || steps[0].Name != "retry" || steps[0].Level != platformalog.LevelWarn {
+			t.Fatalf("expected retry step, got %+v", steps)
+		}
+
+		if !slices.Equal(snapshot.Errors(), []string{"upstream unavailable"}) {
+			t.Fatalf("expected upstream error, got %v", snapshot.Errors())
+		}
+
+		event.AddAttrs(map[string]any{"tenant": "changed"})
+		event.AddGroup("user", map[string]any{"id": "u2"})
+		if tenant, _ := snapshot.Attr("tenant"); tenant != "acme" {
+			t.Fatalf("expected snapshot to keep tenant, got %v", tenant)
+		}
+
+		if user, _ := snapshot.Attrs()["user"].(map[string]any); user["id"] != "u1" {
+			t.Fatalf("expected snapshot to keep user group, got %v", user)
+		}
+	})
+
+	t.Run("emit writes snapshot later", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil)
+
+		event := platformalog.NewEvent("job")
+		event.AddAttrs(map[string]any{"job.id": "42"})
+		snapshot := event.FinishWithoutEmit()
+
+		if buf.Len() != 0 {
+			t.Fatalf("expected nothing to be written before Emit, got %q", buf.String())
+		}
+
+		logger.Emit(context.Background(), snapshot)
+
+		record := decodeRecord(t, buf.Bytes())
+		if record["name"] != "job" || record["job.id"] != "42" || record["sampled"] != true {
+			t.Fatalf("expected emitted job event, got %v", record)
+		}
+	})
+
+	t.Run("emit of zero snapshot is a no-op", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil)
+		logger.Emit(context.Background(), platformalog.EventSnapshot{})
+
+		if buf.Len() != 0 {
+			t.Fatalf("expected nothing to be written, got %q", buf.String())
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package log
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// maxStackDepth is the maximum number of frames captured by Event.AddErrorWithStack.
+const maxStackDepth = 32
+
+// WithErrorStacks makes wide-event loggers add a `stack` list to errors recorded with Event.AddErrorWithStack.
+// Stacks are captured regardless, but left out of the output by default to keep records small.
+func WithErrorStacks() Option {
+	return func(o *options) {
+		o.errorStacks = true
+	}
+}
+
+// AddErrorWithStack appends an error like AddError and captures the stack trace of the caller,
+// which is written as `stack` when the logger was created with WithErrorStacks.
+func (e *Event) AddErrorWithStack(err error) {
+	if err == nil {
+		return
+	}
+
+	// skip runtime.Callers and AddErrorWithStack
+	pcs := make([]uintptr, maxStackDepth)
+	pcs = pcs[:runtime.Callers(2, pcs)]
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.setLevelNoLock(LevelError)
+
+	e.errors = append(e.errors, errorRecord{
+		Timestamp: e.clock(),
+		Error:     err.Error(),
+		Stack:     pcs,
+	})
+}
+
+// formatStack returns the frames of pcs as "function file:line", leaving out runtime internals.
+func formatStack(pcs []uintptr) []string {
+	stack := make([]string, 0, len(pcs))
+
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			stack = append(stack, fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line))
+		}
+
+		if !more {
+			return stack
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	platformalog "github.com/platforma-dev/platforma/log"
+)
+
+func TestAddErrorWithStack(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stack points at the caller", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil, platformalog.WithErrorStacks())
+
+		event := platformalog.NewEvent("job")
+		failJob(event)
+		logger.WriteEvent(context.Background(), event)
+
+		eventErrors := recordErrors(t, decodeRecord(t, buf.Bytes()))
+		if len(eventErrors) != 1 || eventErrors[0]["error"] != "boom" {
+			t.Fatalf("expected boom error, got %v", eventErrors)
+		}
+
+		stack, _ := eventErrors[0]["stack"].([]any)
+		if len(stack) < 2 {
+			t.Fatalf("expected stack with caller frames, got %v", eventErrors[0]["stack"])
+		}
+
+		top, _ := stack[0].(string)
+		if !strings.Contains(top, "log_test.failJob") || !strings.Contains(top, "stack_test.go:") {
+			t.Fatalf("expected top frame in failJob, got %q", top)
+		}
+
+		caller, _ := stack[1].(string)
+		if !strings.Contains(caller, "TestAddErrorWithStack") {
+			t.Fatalf("expected second frame in the test, got %q", caller)
+		}
+
+		if event.Level() != platformalog.LevelError {
+			t.Fatalf("expected error level, got %s", event.Level())
+		}
+	})
+
+	t.Run("stack is omitted by default", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil)
+
+		event := platformalog.NewEvent("job")
+		failJob(event)
+		logger.WriteEvent(context.Background(), event)
+
+		eventErrors := recordErrors(t, decodeRecord(t, buf.Bytes()))
+		if _, ok := eventErrors[0]["stack"]; ok {
+			t.Fatalf("expected no stack, got %v", eventErrors[0])
+		}
+	})
+
+	t.Run("nil error is ignored", func(t *testing.T) {
+		t.Parallel()
+
+		event := platformalog.NewEvent("job")
+		event.AddErrorWithStack(nil)
+
+		if event.HasErrors() {
+			t.Fatal("expected no errors")
+		}
+	})
+}
+
+func failJob(event *platformalog.Event) {
+	event.AddErrorWithStack(errors.New("boom"))
+}
+
+func recordErrors(t *testing.T, record map[string]any) []map[string]any {
+	t.Helper()
+
+	rawErrors, ok := record["errors"].([]any)
+	if !ok {
+		t.Fatalf("expected errors list, got %v", record["errors"])
+	}
+
+	eventErrors := make([]map[string]any, 0, len(rawErrors))
+	for _, rawError := range rawErrors {
+		eventError, ok := rawError.(map[string]any)
+		if !ok {
+			t.Fatalf("expected error object, got %v", rawError)
+		}
+		eventErrors = append(eventErrors, eventError)
+	}
+
+	return eventErrors
+}
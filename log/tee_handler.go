@@ -0,0 +1,75 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+// TeeHandler forwards every record to several handlers, e.g. text to stdout and JSON to a file.
+// Each handler only receives records it is enabled for.
+type TeeHandler struct {
+	handlers []slog.Handler
+}
+
+var _ slog.Handler = (*TeeHandler)(nil)
+
+// NewTeeHandler creates a TeeHandler that forwards records to handlers.
+func NewTeeHandler(handlers ...slog.Handler) *TeeHandler {
+	return &TeeHandler{handlers: slices.Clone(handlers)}
+}
+
+// Enabled reports whether any of the handlers is enabled for the level.
+func (h *TeeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Handle passes the record to every handler enabled for its level. All handlers are called
+// even if some of them fail, and their errors are joined.
+func (h *TeeHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, handler := range h.handlers {
+		if !handler.Enabled(ctx, r.Level) {
+			continue
+		}
+
+		// handlers may retain the record, so each gets its own copy of the attributes
+		if err := handler.Handle(ctx, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("failed to handle log record: %w", err)
+	}
+
+	return nil
+}
+
+// WithAttrs returns a TeeHandler forwarding to the handlers with the given attributes.
+func (h *TeeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, 0, len(h.handlers))
+	for _, handler := range h.handlers {
+		handlers = append(handlers, handler.WithAttrs(attrs))
+	}
+
+	return &TeeHandler{handlers: handlers}
+}
+
+// WithGroup returns a TeeHandler forwarding to the handlers with the given group.
+func (h *TeeHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, 0, len(h.handlers))
+	for _, handler := range h.handlers {
+		handlers = append(handlers, handler.WithGroup(name))
+	}
+
+	return &TeeHandler{handlers: handlers}
+}
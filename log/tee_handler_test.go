@@ -0,0 +1,92 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	platformalog "github.com/platforma-dev/platforma/log"
+)
+
+func TestTeeHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("forwards records to all handlers", func(t *testing.T) {
+		t.Parallel()
+
+		var text, json bytes.Buffer
+		logger := slog.New(platformalog.NewTeeHandler(
+			slog.NewTextHandler(&text, nil),
+			slog.NewJSONHandler(&json, nil),
+		))
+
+		logger.With("service", "api").WithGroup("request").Info("served", "status", 200)
+
+		if !strings.Contains(text.String(), "msg=served") || !strings.Contains(text.String(), "service=api request.status=200") {
+			t.Fatalf("expected text record, got %q", text.String())
+		}
+
+		record := decodeRecord(t, json.Bytes())
+		request, _ := record["request"].(map[string]any)
+		if record["msg"] != "served" || record["service"] != "api" || request["status"] != float64(200) {
+			t.Fatalf("expected JSON record, got %v", record)
+		}
+	})
+
+	t.Run("respects each handler level", func(t *testing.T) {
+		t.Parallel()
+
+		var debug, warn bytes.Buffer
+		handler := platformalog.NewTeeHandler(
+			slog.NewJSONHandler(&debug, &slog.HandlerOptions{Level: slog.LevelDebug}),
+			slog.NewJSONHandler(&warn, &slog.HandlerOptions{Level: slog.LevelWarn}),
+		)
+		logger := slog.New(handler)
+
+		logger.Debug("details")
+		if debug.Len() == 0 || warn.Len() != 0 {
+			t.Fatalf("expected debug record only in debug handler, got %q and %q", debug.String(), warn.String())
+		}
+
+		if handler.Enabled(context.Background(), slog.LevelDebug-1) {
+			t.Fatal("expected handler to be disabled below all levels")
+		}
+	})
+
+	t.Run("joins handler errors", func(t *testing.T) {
+		t.Parallel()
+
+		errFirst := errors.New("first failed")
+		errSecond := errors.New("second failed")
+
+		var buf bytes.Buffer
+		handler := platformalog.NewTeeHandler(
+			failingHandler{err: errFirst},
+			slog.NewJSONHandler(&buf, nil),
+			failingHandler{err: errSecond},
+		)
+
+		err := handler.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "hello", 0))
+		if !errors.Is(err, errFirst) || !errors.Is(err, errSecond) {
+			t.Fatalf("expected both handler errors, got: %v", err)
+		}
+
+		if buf.Len() == 0 {
+			t.Fatal("expected record to reach the working handler")
+		}
+	})
+}
+
+// failingHandler is a slog.Handler that fails to handle every record.
+type failingHandler struct {
+	err error
+}
+
+func (h failingHandler) Enabled(context.Context, slog.Level) bool  { return true }
+func (h failingHandler) Handle(context.Context, slog.Record) error { return h.err }
+func (h failingHandler) WithAttrs([]slog.Attr) slog.Handler        { return h }
+func (h failingHandler) WithGroup(string) slog.Handler             { return h }
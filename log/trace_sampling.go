@@ -0,0 +1,92 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// TraceSampledKey is the context key for the sampling decision of the current trace.
+const TraceSampledKey contextKey = "traceSampled"
+
+// WithTraceSampled returns a context that carries the sampling decision of the current trace,
+// which TraceSamplingHandler uses to keep or drop regular log records.
+func WithTraceSampled(ctx context.Context, sampled bool) context.Context {
+	return context.WithValue(ctx, TraceSampledKey, sampled)
+}
+
+// TraceSampledFromContext returns the sampling decision of the current trace and whether it was made.
+func TraceSampledFromContext(ctx context.Context) (sampled bool, ok bool) {
+	sampled, ok = ctx.Value(TraceSampledKey).(bool)
+
+	return sampled, ok
+}
+
+// TraceSamplingHandler wraps a slog.Handler and drops records below a level when the
+// trace in the record context was not sampled, so that regular logs follow the sampling
+// decision of the trace. Records without a decision in context or marked with WithDebugTrace are passed through.
+type TraceSamplingHandler struct {
+	handler  slog.Handler
+	minLevel slog.Leveler
+}
+
+var _ slog.Handler = (*TraceSamplingHandler)(nil)
+
+// NewTraceSamplingHandler creates a TraceSamplingHandler that keeps records of unsampled traces at or above minLevel.
+func NewTraceSamplingHandler(h slog.Handler, minLevel slog.Leveler) *TraceSamplingHandler {
+	return &TraceSamplingHandler{handler: h, minLevel: minLevel}
+}
+
+// WithTraceSampling makes loggers created by New drop records below minLevel for unsampled traces, see TraceSamplingHandler.
+func WithTraceSampling(minLevel slog.Leveler) Option {
+	return func(o *options) {
+		o.traceSamplingLevel = minLevel
+	}
+}
+
+// Enabled reports whether a record at the given level is kept for the trace in ctx.
+func (h *TraceSamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if !h.keep(ctx, level) {
+		return false
+	}
+
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle passes the record to the wrapped handler unless its trace was not sampled.
+func (h *TraceSamplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.keep(ctx, r.Level) {
+		return nil
+	}
+
+	if err := h.handler.Handle(ctx, r); err != nil {
+		return fmt.Errorf("failed to handle log record: %w", err)
+	}
+
+	return nil
+}
+
+// WithAttrs returns a TraceSamplingHandler wrapping the handler with the given attributes.
+func (h *TraceSamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &TraceSamplingHandler{handler: h.handler.WithAttrs(attrs), minLevel: h.minLevel}
+}
+
+// WithGroup returns a TraceSamplingHandler wrapping the handler with the given group.
+func (h *TraceSamplingHandler) WithGroup(name string) slog.Handler {
+	return &TraceSamplingHandler{handler: h.handler.WithGroup(name), minLevel: h.minLevel}
+}
+
+func (h *TraceSamplingHandler) keep(ctx context.Context, level slog.Level) bool {
+	sampled, ok := TraceSampledFromContext(ctx)
+
+	return !ok || sampled || level >= h.minLevel.Level() || DebugTraceFromContext(ctx)
+}
+
+// wrapTraceSamplingHandler wraps h in a TraceSamplingHandler when WithTraceSampling is set.
+func wrapTraceSamplingHandler(h slog.Handler, o options) slog.Handler {
+	if o.traceSamplingLevel == nil {
+		return h
+	}
+
+	return NewTraceSamplingHandler(h, o.traceSamplingLevel)
+}
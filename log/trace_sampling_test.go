@@ -0,0 +1,45 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	platformalog "github.com/platforma-dev/platforma/log"
+)
+
+func TestTraceSamplingHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		wantInfo  bool
+		wantError bool
+	}{
+		{name: "sampled trace passes", ctx: platformalog.WithTraceSampled(context.Background(), true), wantInfo: true, wantError: true},
+		{name: "unsampled trace drops below error", ctx: platformalog.WithTraceSampled(context.Background(), false), wantInfo: false, wantError: true},
+		{name: "no decision passes", ctx: context.Background(), wantInfo: true, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			logger := platformalog.New(&buf, "json", platformalog.LevelDebug, nil, platformalog.WithTraceSampling(platformalog.LevelError))
+
+			logger.InfoContext(tt.ctx, "info record")
+			logger.ErrorContext(tt.ctx, "error record")
+
+			if got := strings.Contains(buf.String(), "info record"); got != tt.wantInfo {
+				t.Fatalf("expected info record written: %v, got output %s", tt.wantInfo, buf.String())
+			}
+
+			if got := strings.Contains(buf.String(), "error record"); got != tt.wantError {
+				t.Fatalf("expected error record written: %v, got output %s", tt.wantError, buf.String())
+			}
+		})
+	}
+}
@@ -7,16 +7,31 @@ import (
 	"github.com/google/uuid"
 )
 
+// IDGenerator generates trace IDs, e.g. UUIDs, ULIDs or W3C trace IDs.
+type IDGenerator interface {
+	NewID() string
+}
+
+// IDGeneratorFunc is a function adapter for IDGenerator.
+type IDGeneratorFunc func() string
+
+// NewID implements IDGenerator.
+func (f IDGeneratorFunc) NewID() string {
+	return f()
+}
+
 // TraceIDMiddleware adds a trace ID to request context and response headers.
 type TraceIDMiddleware struct {
-	contextKey any
-	header     string
+	contextKey  any
+	header      string
+	idGenerator IDGenerator
 }
 
 // NewTraceIDMiddleware returns a new TraceID middleware.
 // If key is nil, TraceIDKey is used.
 // If header is empty, "Platforma-Trace-Id" is used.
-func NewTraceIDMiddleware(contextKey any, header string) *TraceIDMiddleware {
+// Trace IDs are UUIDv4 unless another generator is set with WithIDGenerator.
+func NewTraceIDMiddleware(contextKey any, header string, opts ...Option) *TraceIDMiddleware {
 	if contextKey == nil {
 		contextKey = TraceIDKey
 	}
@@ -25,13 +40,18 @@ func NewTraceIDMiddleware(contextKey any, header string) *TraceIDMiddleware {
 		header = "Platforma-Trace-Id"
 	}
 
-	return &TraceIDMiddleware{contextKey: contextKey, header: header}
+	idGenerator := newOptions(opts).idGenerator
+	if idGenerator == nil {
+		idGenerator = IDGeneratorFunc(uuid.NewString)
+	}
+
+	return &TraceIDMiddleware{contextKey: contextKey, header: header, idGenerator: idGenerator}
 }
 
 // Wrap adds trace ID to requests.
 func (m *TraceIDMiddleware) Wrap(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		traceID := uuid.NewString()
+		traceID := m.idGenerator.NewID()
 		ctx := context.WithValue(r.Context(), m.contextKey, traceID)
 		r = r.WithContext(ctx)
 
@@ -1,6 +1,7 @@
 package log_test
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -36,4 +37,35 @@ func TestTraceIDMiddleware(t *testing.T) {
 			t.Fatalf("trace id from context expected, got: %s", resp.Header)
 		}
 	})
+	t.Run("custom id generator", func(t *testing.T) {
+		t.Parallel()
+
+		var n int
+		generator := platformalog.IDGeneratorFunc(func() string {
+			n++
+			return fmt.Sprintf("trace-%d", n)
+		})
+
+		m := platformalog.NewTraceIDMiddleware(nil, "X-Request-Id", platformalog.WithIDGenerator(generator))
+		wrappedHandler := m.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			i, ok := r.Context().Value(platformalog.TraceIDKey).(string)
+			if ok {
+				w.Header().Add("TraceIdFromContext", i)
+			}
+		}))
+
+		for _, expected := range []string{"trace-1", "trace-2"} {
+			w := httptest.NewRecorder()
+			wrappedHandler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			resp := w.Result()
+
+			if resp.Header.Get("X-Request-Id") != expected {
+				t.Fatalf("expected trace id header %q, got: %q", expected, resp.Header.Get("X-Request-Id"))
+			}
+
+			if resp.Header.Get("TraceIdFromContext") != expected {
+				t.Fatalf("expected trace id %q in context, got: %q", expected, resp.Header.Get("TraceIdFromContext"))
+			}
+		}
+	})
 }
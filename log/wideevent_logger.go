@@ -5,6 +5,7 @@ import (
 	"io"
 	"log/slog"
 	"slices"
+	"sync"
 	"time"
 )
 
@@ -13,66 +14,102 @@ type WideEventLogger struct {
 	sampler          Sampler
 	logger           *slog.Logger
 	reservedAttrKeys []string
+	opts             options
+	pool             sync.Pool // events for StartPooled
 }
 
 const (
 	simpleLogEventName = "log.record"
+	samplingAttrKey    = "sampling"
 )
 
 var _ logger = (*WideEventLogger)(nil)
 
 // NewWideEventLogger creates a wide-event logger.
-func NewWideEventLogger(w io.Writer, s Sampler, loggerType string, contextKeys map[string]any) *WideEventLogger {
+func NewWideEventLogger(w io.Writer, s Sampler, loggerType string, contextKeys map[string]any, opts ...Option) *WideEventLogger {
 	// If no sampler provided, use a keep-all sampler to prevent nil panics
 	if s == nil {
 		s = SamplerFunc(func(_ context.Context, _ *Event) bool { return true })
 	}
 
-	opts := &slog.HandlerOptions{
-		Level: LevelDebug,
-		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
-			if a.Key == slog.TimeKey {
-				return slog.Attr{}
-			}
-			if a.Key == slog.MessageKey && a.Value.Kind() == slog.KindString && a.Value.String() == "" {
-				return slog.Attr{}
-			}
-			return a
-		},
+	o := newOptions(opts)
+
+	var level slog.Leveler = LevelDebug
+	if o.level != nil {
+		level = o.level
+	}
+
+	handlerOpts := &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: ChainReplaceAttr(stripWideEventAttr, o.replaceAttr, renameAttr(o.fieldNames)),
 	}
 
 	var handler slog.Handler
 	if loggerType == "json" {
-		handler = slog.NewJSONHandler(w, opts)
+		handler = slog.NewJSONHandler(w, handlerOpts)
 	} else {
-		handler = slog.NewTextHandler(w, opts)
+		handler = slog.NewTextHandler(w, handlerOpts)
 	}
 
-	return &WideEventLogger{
+	keys, deprecated := mergeContextKeys(contextKeys, o.contextKeys)
+	reservedAttrKeys := wideEventReservedAttrKeys(keys)
+	if o.samplingDecision {
+		reservedAttrKeys = appendUnique(reservedAttrKeys, samplingAttrKey)
+	}
+	if o.latencyBuckets != nil {
+		reservedAttrKeys = appendUnique(reservedAttrKeys, latencyBucketAttrKey)
+	}
+
+	l := &WideEventLogger{
 		sampler:          s,
-		logger:           slog.New(&contextHandler{handler, contextKeys}),
-		reservedAttrKeys: wideEventReservedAttrKeys(contextKeys),
+		logger:           slog.New(&contextHandler{wrapFlushHandler(handler, o), keys}),
+		reservedAttrKeys: reservedAttrKeys,
+		opts:             o,
 	}
+	l.pool.New = func() any { return &Event{level: LevelDebug, attrs: map[string]any{}} }
+	warnDeprecatedContextKeys(l, deprecated)
+
+	return l
+}
+
+// StartPooled creates a wide event like NewEvent, reusing an event from the logger's pool to
+// avoid allocations on hot paths. WriteEvent resets the event and returns it to the pool,
+// so it must not be used in any way after WriteEvent, including by goroutines or deferred
+// functions of the handler: it may already belong to another operation, whose data would be corrupted.
+// Pooled events that are not written are garbage collected as usual.
+func (l *WideEventLogger) StartPooled(name string) *Event {
+	e, _ := l.pool.Get().(*Event)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.name = name
+	e.now = l.opts.now
+	e.timestamp = e.clock()
+	e.pc = callerPC(3)
+	e.pool = &l.pool
+
+	return e
 }
 
 // Debug logs a message at Debug level.
 func (l *WideEventLogger) Debug(msg string, args ...any) {
-	l.DebugContext(context.Background(), msg, args...)
+	l.writeSimpleLog(context.Background(), LevelDebug, msg, args...)
 }
 
 // Info logs a message at Info level.
 func (l *WideEventLogger) Info(msg string, args ...any) {
-	l.InfoContext(context.Background(), msg, args...)
+	l.writeSimpleLog(context.Background(), LevelInfo, msg, args...)
 }
 
 // Warn logs a message at Warn level.
 func (l *WideEventLogger) Warn(msg string, args ...any) {
-	l.WarnContext(context.Background(), msg, args...)
+	l.writeSimpleLog(context.Background(), LevelWarn, msg, args...)
 }
 
 // Error logs a message at Error level.
 func (l *WideEventLogger) Error(msg string, args ...any) {
-	l.ErrorContext(context.Background(), msg, args...)
+	l.writeSimpleLog(context.Background(), LevelError, msg, args...)
 }
 
 // DebugContext logs a message at Debug level with context.
@@ -96,23 +133,72 @@ func (l *WideEventLogger) ErrorContext(ctx context.Context, msg string, args ...
 }
 
 // WriteEvent finalizes event duration and conditionally writes it.
+// Written events include `sampled: true` and the `samplingReason` of the sampler.
+// Events from StartPooled are returned to the pool afterwards.
 func (l *WideEventLogger) WriteEvent(ctx context.Context, e *Event) {
 	e.Finish()
+	if l.opts.grpcCodeLevels {
+		applyGRPCCodeLevel(e)
+	}
+	l.write(ctx, e, "", true)
+	e.release()
+}
 
-	if l.sampler.ShouldSample(ctx, e) {
-		l.logger.LogAttrs(ctx, e.Level(), "", e.toAttrs(l.reservedAttrKeys)...)
+// WriteCheckpoint writes a partial snapshot of the event without finishing it.
+// Checkpoints bypass the sampler because the outcome of the event is not known yet.
+func (l *WideEventLogger) WriteCheckpoint(ctx context.Context, e *Event) {
+	attrs := e.toAttrs(l.reservedAttrKeys, l.opts, true)
+	if l.opts.flatten {
+		attrs = flattenAttrs(attrs)
 	}
+
+	l.logger.LogAttrs(ctx, e.Level(), "", attrs...)
 }
 
 func (l *WideEventLogger) writeSimpleLog(ctx context.Context, level Level, msg string, args ...any) {
-	event := NewEvent(simpleLogEventName)
+	// skip writeSimpleLog and the logging method to report the caller of the logger
+	event := newEvent(simpleLogEventName, callerPC(4))
 	event.SetLevel(level)
 	event.AddAttrs(simpleLogEventAttrs(args...))
 	event.Finish()
+	l.write(ctx, event, msg, false)
+}
 
-	if l.sampler.ShouldSample(ctx, event) {
-		l.logger.LogAttrs(ctx, event.Level(), msg, event.toAttrs(l.reservedAttrKeys)...)
+// write emits a finished event if the sampler keeps it.
+// Attributes are only built for kept events.
+func (l *WideEventLogger) write(ctx context.Context, e *Event, msg string, withSamplingReason bool) {
+	decision := decide(ctx, l.sampler, e)
+	if !decision.Keep {
+		return
+	}
+
+	attrs := e.toAttrs(l.reservedAttrKeys, l.opts, false)
+	if withSamplingReason {
+		attrs = append(attrs, slog.Bool("sampled", true), slog.String("samplingReason", decision.Reason))
+	}
+	if l.opts.samplingDecision {
+		attrs = append(attrs, slog.Any(samplingAttrKey, decision.attrs()))
+	}
+	// simple log records have no meaningful duration, like sampling they only apply to wide events
+	if withSamplingReason && l.opts.latencyBuckets != nil {
+		attrs = append(attrs, slog.String(latencyBucketAttrKey, l.opts.latencyBuckets.Bucket(e.Duration())))
+	}
+	if l.opts.flatten {
+		attrs = flattenAttrs(attrs)
+	}
+
+	l.logger.LogAttrs(ctx, e.Level(), msg, attrs...)
+}
+
+// stripWideEventAttr drops the record time, which duplicates the event timestamp, and empty messages.
+func stripWideEventAttr(_ []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.TimeKey {
+		return slog.Attr{}
+	}
+	if a.Key == slog.MessageKey && a.Value.Kind() == slog.KindString && a.Value.String() == "" {
+		return slog.Attr{}
 	}
+	return a
 }
 
 func simpleLogEventAttrs(args ...any) map[string]any {
@@ -0,0 +1,870 @@
+package log_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	platformalog "github.com/platforma-dev/platforma/log"
+)
+
+func TestWideEventLogger(t *testing.T) {
+	t.Parallel()
+
+	t.Run("steps include delta and slow flag", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil, platformalog.WithSlowStepThreshold(50*time.Millisecond))
+
+		ev := platformalog.NewEvent("test")
+		ev.AddStep(platformalog.LevelInfo, "fast step")
+		time.Sleep(60 * time.Millisecond)
+		ev.AddStep(platformalog.LevelInfo, "slow step")
+
+		logger.WriteEvent(context.Background(), ev)
+
+		record := decodeRecord(t, buf.Bytes())
+		steps := recordSteps(t, record)
+		if len(steps) != 2 {
+			t.Fatalf("expected 2 steps, got %d", len(steps))
+		}
+
+		for _, step := range steps {
+			if _, ok := step["deltaMs"]; !ok {
+				t.Fatalf("expected deltaMs in step %v", step)
+			}
+		}
+
+		if _, ok := steps[0]["slow"]; ok {
+			t.Fatalf("expected fast step not to be marked slow, got %v", steps[0])
+		}
+
+		if steps[1]["slow"] != true {
+			t.Fatalf("expected slow step to be marked slow, got %v", steps[1])
+		}
+
+		if delta, _ := steps[1]["deltaMs"].(float64); delta < 60 {
+			t.Fatalf("expected slow step deltaMs >= 60, got %v", steps[1]["deltaMs"])
+		}
+	})
+
+	t.Run("slow flag disabled by default", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil)
+
+		ev := platformalog.NewEvent("test")
+		time.Sleep(10 * time.Millisecond)
+		ev.AddStep(platformalog.LevelInfo, "step")
+
+		logger.WriteEvent(context.Background(), ev)
+
+		steps := recordSteps(t, decodeRecord(t, buf.Bytes()))
+		if _, ok := steps[0]["slow"]; ok {
+			t.Fatalf("expected no slow flag without threshold, got %v", steps[0])
+		}
+	})
+
+	t.Run("checkpoints emit partial records", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil)
+		m := platformalog.NewWideEventMiddleware(logger, "", nil)
+
+		handler := m.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ev := platformalog.EventFromContext(r.Context())
+			ev.AddStep(platformalog.LevelInfo, "first chunk")
+			ev.Checkpoint(r.Context())
+			ev.AddStep(platformalog.LevelInfo, "second chunk")
+			ev.Checkpoint(r.Context())
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stream", nil))
+
+		lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+		if len(lines) != 3 {
+			t.Fatalf("expected 3 records, got %d: %s", len(lines), buf.String())
+		}
+
+		for i, wantPartial := range []bool{true, true, false} {
+			record := decodeRecord(t, lines[i])
+			partial, _ := record["partial"].(bool)
+			if partial != wantPartial {
+				t.Fatalf("expected record %d partial to be %v, got %v", i, wantPartial, record["partial"])
+			}
+
+			if steps := recordSteps(t, record); len(steps) != min(i+1, 2) {
+				t.Fatalf("expected record %d to have %d steps, got %d", i, min(i+1, 2), len(steps))
+			}
+		}
+
+		final := decodeRecord(t, lines[2])
+		if final["request.status"] != float64(http.StatusOK) {
+			t.Fatalf("expected final record to have request status, got %v", final["request.status"])
+		}
+	})
+
+	t.Run("checkpoint on unbound event is a no-op", func(t *testing.T) {
+		t.Parallel()
+
+		ev := platformalog.NewEvent("test")
+		ev.Checkpoint(context.Background())
+	})
+
+	t.Run("sampling decision includes matched rule", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		sampler := platformalog.NewDefaultSampler(time.Hour, http.StatusInternalServerError, 0)
+		logger := platformalog.NewWideEventLogger(&buf, sampler, "json", nil, platformalog.WithSamplingDecision())
+		m := platformalog.NewWideEventMiddleware(logger, "", nil)
+
+		handler := m.Wrap(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		record := decodeRecord(t, buf.Bytes())
+		sampling, ok := record["sampling"].(map[string]any)
+		if !ok {
+			t.Fatalf("expected sampling object in record, got %v", record)
+		}
+
+		if sampling["reason"] != platformalog.SamplingReasonStatus {
+			t.Fatalf("expected reason %q, got %v", platformalog.SamplingReasonStatus, sampling["reason"])
+		}
+
+		if sampling["rule"] != "request.status" {
+			t.Fatalf("expected rule %q, got %v", "request.status", sampling["rule"])
+		}
+
+		if sampling["forced"] != true {
+			t.Fatalf("expected forced to be true, got %v", sampling["forced"])
+		}
+
+		if _, ok := sampling["rate"]; ok {
+			t.Fatalf("expected no rate for rule match, got %v", sampling["rate"])
+		}
+	})
+
+	t.Run("sampling decision reports random keep rate", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		sampler := platformalog.NewDefaultSampler(time.Hour, http.StatusInternalServerError, 1)
+		logger := platformalog.NewWideEventLogger(&buf, sampler, "json", nil, platformalog.WithSamplingDecision())
+
+		logger.WriteEvent(context.Background(), platformalog.NewEvent("job"))
+
+		sampling, ok := decodeRecord(t, buf.Bytes())["sampling"].(map[string]any)
+		if !ok {
+			t.Fatalf("expected sampling object in record, got %s", buf.String())
+		}
+
+		if sampling["reason"] != platformalog.SamplingReasonRandom || sampling["rate"] != float64(1) || sampling["forced"] != false {
+			t.Fatalf("expected random decision with rate 1, got %v", sampling)
+		}
+	})
+
+	t.Run("sampling decision disabled by default", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil)
+
+		ev := platformalog.NewEvent("job")
+		ev.AddAttrs(map[string]any{"sampling": "custom"})
+		logger.WriteEvent(context.Background(), ev)
+
+		record := decodeRecord(t, buf.Bytes())
+		if record["sampling"] != "custom" {
+			t.Fatalf("expected custom sampling attr to be kept, got %v", record["sampling"])
+		}
+	})
+
+	t.Run("written events include sampling reason", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		sampler := platformalog.NewDefaultSampler(time.Hour, http.StatusInternalServerError, 0)
+		logger := platformalog.NewWideEventLogger(&buf, sampler, "json", nil)
+
+		ev := platformalog.NewEvent("job")
+		ev.AddError(errors.New("some error"))
+		logger.WriteEvent(context.Background(), ev)
+
+		record := decodeRecord(t, buf.Bytes())
+		if record["sampled"] != true {
+			t.Fatalf("expected sampled to be true, got %v", record["sampled"])
+		}
+
+		if record["samplingReason"] != platformalog.SamplingReasonError {
+			t.Fatalf("expected samplingReason %q, got %v", platformalog.SamplingReasonError, record["samplingReason"])
+		}
+	})
+
+	t.Run("dropped events are not written", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		sampler := platformalog.NewDefaultSampler(time.Hour, http.StatusInternalServerError, 0)
+		logger := platformalog.NewWideEventLogger(&buf, sampler, "json", nil)
+
+		logger.WriteEvent(context.Background(), platformalog.NewEvent("job"))
+
+		if buf.Len() != 0 {
+			t.Fatalf("expected no output, got %s", buf.String())
+		}
+	})
+
+	t.Run("max steps caps emitted steps", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil, platformalog.WithMaxSteps(2))
+
+		ev := platformalog.NewEvent("job")
+		for _, name := range []string{"first", "second", "third", "fourth"} {
+			ev.AddStep(platformalog.LevelInfo, name)
+		}
+		logger.WriteEvent(context.Background(), ev)
+
+		record := decodeRecord(t, buf.Bytes())
+		steps := recordSteps(t, record)
+		if len(steps) != 2 {
+			t.Fatalf("expected 2 steps, got %d", len(steps))
+		}
+
+		if steps[0]["name"] != "first" || steps[1]["name"] != "second" {
+			t.Fatalf("expected first steps to be kept, got %v", steps)
+		}
+
+		if record["stepsDropped"] != float64(2) {
+			t.Fatalf("expected stepsDropped to be 2, got %v", record["stepsDropped"])
+		}
+	})
+
+	t.Run("steps dropped omitted without cap", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil)
+
+		ev := platformalog.NewEvent("job")
+		ev.AddStep(platformalog.LevelInfo, "step")
+		ev.AddAttrs(map[string]any{"stepsDropped": 10, "sampled": false})
+		logger.WriteEvent(context.Background(), ev)
+
+		record := decodeRecord(t, buf.Bytes())
+		if _, ok := record["stepsDropped"]; ok {
+			t.Fatalf("expected no stepsDropped, got %v", record["stepsDropped"])
+		}
+
+		if record["sampled"] != true {
+			t.Fatalf("expected reserved sampled attr not to be overridden, got %v", record["sampled"])
+		}
+	})
+
+	t.Run("errorf records returned error", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil)
+
+		errNotFound := errors.New("not found")
+		ev := platformalog.NewEvent("job")
+		err := ev.Errorf("failed to load user %d: %w", 42, errNotFound)
+		logger.WriteEvent(context.Background(), ev)
+
+		if !errors.Is(err, errNotFound) {
+			t.Fatalf("expected returned error to wrap cause, got %v", err)
+		}
+
+		if ev.Level() != platformalog.LevelError {
+			t.Fatalf("expected event level to be error, got %v", ev.Level())
+		}
+
+		record := decodeRecord(t, buf.Bytes())
+		errs, ok := record["errors"].([]any)
+		if !ok || len(errs) != 1 {
+			t.Fatalf("expected 1 error in record, got %v", record["errors"])
+		}
+
+		recorded, _ := errs[0].(map[string]any)
+		if recorded["error"] != err.Error() {
+			t.Fatalf("expected recorded error %q, got %v", err.Error(), recorded["error"])
+		}
+	})
+
+	t.Run("grouped attrs are nested", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil)
+
+		ev := platformalog.NewEvent("job")
+		ev.AddGroup("request", map[string]any{"method": "GET"})
+		ev.AddGroup("request", map[string]any{"status": 200})
+		ev.AddGroup("steps", map[string]any{"count": 1})
+		logger.WriteEvent(context.Background(), ev)
+
+		record := decodeRecord(t, buf.Bytes())
+		request, ok := record["request"].(map[string]any)
+		if !ok {
+			t.Fatalf("expected request group in record, got %v", record)
+		}
+
+		if request["method"] != "GET" || request["status"] != float64(200) {
+			t.Fatalf("expected merged request group, got %v", request)
+		}
+
+		if _, ok := record["steps"]; ok {
+			t.Fatalf("expected group with builtin key not to be emitted, got %v", record["steps"])
+		}
+	})
+
+	t.Run("duration as integer milliseconds", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil, platformalog.WithDurationMs())
+
+		ev := platformalog.NewEvent("job")
+		ev.AddAttrs(map[string]any{"durationMs": "overridden"})
+		time.Sleep(15 * time.Millisecond)
+		logger.WriteEvent(context.Background(), ev)
+
+		record := decodeRecord(t, buf.Bytes())
+		if _, ok := record["duration"]; ok {
+			t.Fatalf("expected no duration string, got %v", record["duration"])
+		}
+
+		durationMs, ok := record["durationMs"].(float64)
+		if !ok {
+			t.Fatalf("expected numeric durationMs, got %v", record["durationMs"])
+		}
+
+		if durationMs != float64(ev.Duration().Milliseconds()) || durationMs < 15 {
+			t.Fatalf("expected durationMs to be %d, got %v", ev.Duration().Milliseconds(), durationMs)
+		}
+	})
+
+	t.Run("source points at the caller", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil, platformalog.WithSource())
+
+		_, file, line, _ := runtime.Caller(0)
+		ev := platformalog.NewEvent("job")
+		logger.WriteEvent(context.Background(), ev)
+		assertSource(t, decodeRecord(t, buf.Bytes()), file, line+1)
+
+		buf.Reset()
+		_, file, line, _ = runtime.Caller(0)
+		logger.Info("simple")
+		assertSource(t, decodeRecord(t, buf.Bytes()), file, line+1)
+	})
+
+	t.Run("source is omitted by default", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil)
+		logger.WriteEvent(context.Background(), platformalog.NewEvent("job"))
+
+		if source, ok := decodeRecord(t, buf.Bytes())["source"]; ok {
+			t.Fatalf("expected no source, got %v", source)
+		}
+	})
+
+	t.Run("field names can be renamed", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil, platformalog.WithFieldNames(map[string]string{
+			"name":           "message",
+			"duration":       "duration_ms",
+			"samplingReason": "sampling_reason",
+			"traceId":        "trace_id",
+		}))
+
+		ev := platformalog.NewEvent("job")
+		ev.AddStep(platformalog.LevelInfo, "step")
+		ctx := context.WithValue(context.Background(), platformalog.TraceIDKey, "trace-1")
+		logger.WriteEvent(ctx, ev)
+
+		record := decodeRecord(t, buf.Bytes())
+		for _, key := range []string{"name", "duration", "samplingReason", "traceId"} {
+			if _, ok := record[key]; ok {
+				t.Fatalf("expected %q to be renamed, got %v", key, record)
+			}
+		}
+
+		if record["message"] != "job" {
+			t.Fatalf("expected message to be %q, got %v", "job", record["message"])
+		}
+
+		if _, ok := record["duration_ms"]; !ok {
+			t.Fatalf("expected duration_ms in record, got %v", record)
+		}
+
+		if record["sampling_reason"] != platformalog.SamplingReasonSampler {
+			t.Fatalf("expected sampling_reason in record, got %v", record)
+		}
+
+		if record["trace_id"] != "trace-1" {
+			t.Fatalf("expected trace_id in record, got %v", record)
+		}
+
+		// defaults are kept for fields without overrides and nested step fields are untouched
+		if record["sampled"] != true {
+			t.Fatalf("expected sampled in record, got %v", record)
+		}
+
+		if steps := recordSteps(t, record); steps[0]["name"] != "step" {
+			t.Fatalf("expected step name to be kept, got %v", steps[0])
+		}
+	})
+}
+
+func TestWideEventLoggerSamplingPath(t *testing.T) {
+	t.Parallel()
+
+	t.Run("dropped events are not written", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, dropSampler(), "json", nil)
+		logger.WriteEvent(context.Background(), benchmarkEvent())
+
+		if buf.Len() != 0 {
+			t.Fatalf("expected dropped event not to be written, got %s", buf.String())
+		}
+	})
+
+	t.Run("kept events are written unchanged", func(t *testing.T) {
+		t.Parallel()
+
+		var sampledDuration time.Duration
+		sampler := platformalog.SamplerFunc(func(_ context.Context, e *platformalog.Event) bool {
+			sampledDuration = e.Duration()
+			return true
+		})
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, sampler, "json", nil)
+		ev := benchmarkEvent()
+		ev.AddError(errors.New("boom"))
+		logger.WriteEvent(context.Background(), ev)
+
+		// the sampler sees the finished event
+		if sampledDuration == 0 || sampledDuration != ev.Duration() {
+			t.Fatalf("expected sampler to see final duration %s, got %s", ev.Duration(), sampledDuration)
+		}
+
+		record := decodeRecord(t, buf.Bytes())
+		if record["user.id"] != "u1" || record["request.status"] != float64(200) {
+			t.Fatalf("expected attrs in record, got %v", record)
+		}
+
+		if steps := recordSteps(t, record); len(steps) != 3 {
+			t.Fatalf("expected 3 steps, got %d", len(steps))
+		}
+
+		if errs, ok := record["errors"].([]any); !ok || len(errs) != 1 {
+			t.Fatalf("expected 1 error in record, got %v", record["errors"])
+		}
+
+		// building the record does not modify the event
+		if value, ok := ev.Attr("user.id"); !ok || value != "u1" {
+			t.Fatalf("expected event attrs to be unchanged, got %v", value)
+		}
+	})
+}
+
+func TestEventAttr(t *testing.T) {
+	t.Parallel()
+
+	ev := platformalog.NewEvent("job")
+	ev.AddAttrs(map[string]any{"user.id": "u1"})
+	ev.AddGroup("request", map[string]any{"status": 200})
+
+	if value, ok := ev.Attr("user.id"); !ok || value != "u1" {
+		t.Fatalf("expected user.id u1, got %v, %v", value, ok)
+	}
+
+	if value, ok := ev.Attr("missing"); ok || value != nil {
+		t.Fatalf("expected missing attribute, got %v, %v", value, ok)
+	}
+
+	value, _ := ev.Attr("request")
+	group, ok := value.(map[string]any)
+	if !ok || group["status"] != 200 {
+		t.Fatalf("expected request group with status, got %v", value)
+	}
+
+	// the returned group is a copy
+	group["status"] = 500
+	if value, _ := ev.Attr("request"); value.(map[string]any)["status"] != 200 {
+		t.Fatalf("expected event group to be unchanged, got %v", value)
+	}
+}
+
+func TestEventReset(t *testing.T) {
+	t.Parallel()
+
+	ev := platformalog.NewEvent("job")
+	ev.AddAttrs(map[string]any{"user.id": "u1"})
+	ev.AddStep(platformalog.LevelWarn, "retry")
+	ev.AddError(errors.New("boom"))
+	ev.Finish()
+
+	ev.Reset()
+
+	if ev.Name() != "job" {
+		t.Fatalf("expected name to be kept, got %q", ev.Name())
+	}
+	if ev.Level() != platformalog.LevelDebug || ev.HasErrors() || ev.Duration() != 0 {
+		t.Fatalf("expected debug level, no errors and no duration, got %v, %v, %v", ev.Level(), ev.HasErrors(), ev.Duration())
+	}
+	if _, ok := ev.Attr("user.id"); ok {
+		t.Fatal("expected attributes to be cleared")
+	}
+
+	var buf bytes.Buffer
+	platformalog.NewWideEventLogger(&buf, nil, "json", nil).WriteEvent(context.Background(), ev)
+
+	record := decodeRecord(t, buf.Bytes())
+	if _, ok := record["steps"]; ok {
+		t.Fatalf("expected no leftover steps, got %v", record["steps"])
+	}
+	if _, ok := record["errors"]; ok {
+		t.Fatalf("expected no leftover errors, got %v", record["errors"])
+	}
+}
+
+func TestWideEventLoggerStartPooled(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil)
+
+	first := logger.StartPooled("first")
+	first.AddAttrs(map[string]any{"user.id": "u1"})
+	first.AddStep(platformalog.LevelInfo, "query")
+	first.AddError(errors.New("boom"))
+	logger.WriteEvent(context.Background(), first)
+
+	record := decodeRecord(t, buf.Bytes())
+	if record["name"] != "first" || record["user.id"] != "u1" || record["level"] != "ERROR" {
+		t.Fatalf("expected first event with its data, got %v", record)
+	}
+	buf.Reset()
+
+	second := logger.StartPooled("second")
+	logger.WriteEvent(context.Background(), second)
+
+	record = decodeRecord(t, buf.Bytes())
+	if record["name"] != "second" || record["level"] != "DEBUG" {
+		t.Fatalf("expected fresh second event, got %v", record)
+	}
+	for _, key := range []string{"user.id", "steps", "errors"} {
+		if _, ok := record[key]; ok {
+			t.Fatalf("expected no leftover %s in pooled event, got %v", key, record)
+		}
+	}
+}
+
+func BenchmarkWideEventLoggerWriteEvent(b *testing.B) {
+	samplers := map[string]platformalog.Sampler{
+		"dropped": dropSampler(),
+		"kept":    platformalog.SamplerFunc(func(_ context.Context, _ *platformalog.Event) bool { return true }),
+	}
+
+	for name, sampler := range samplers {
+		b.Run(name, func(b *testing.B) {
+			logger := platformalog.NewWideEventLogger(io.Discard, sampler, "json", nil)
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			for b.Loop() {
+				b.StopTimer()
+				ev := benchmarkEvent()
+				b.StartTimer()
+
+				logger.WriteEvent(ctx, ev)
+			}
+		})
+	}
+}
+
+func BenchmarkWideEventLoggerStartPooled(b *testing.B) {
+	logger := platformalog.NewWideEventLogger(io.Discard, dropSampler(), "json", nil)
+	ctx := context.Background()
+
+	b.Run("new", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			ev := platformalog.NewEvent("http.request")
+			fillBenchmarkEvent(ev)
+			logger.WriteEvent(ctx, ev)
+		}
+	})
+
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			ev := logger.StartPooled("http.request")
+			fillBenchmarkEvent(ev)
+			logger.WriteEvent(ctx, ev)
+		}
+	})
+}
+
+// dropSampler drops every event, like a DefaultSampler with zero keep rate for fast successful requests.
+func dropSampler() platformalog.Sampler {
+	return platformalog.NewDefaultSampler(time.Hour, 500, 0)
+}
+
+func benchmarkEvent() *platformalog.Event {
+	ev := platformalog.NewEvent("http.request")
+	fillBenchmarkEvent(ev)
+
+	return ev
+}
+
+func fillBenchmarkEvent(ev *platformalog.Event) {
+	ev.AddAttrs(map[string]any{
+		"request.method": http.MethodGet,
+		"request.path":   "/users",
+		"request.status": 200,
+		"user.id":        "u1",
+	})
+	ev.AddStep(platformalog.LevelInfo, "auth")
+	ev.AddStep(platformalog.LevelInfo, "query")
+	ev.AddStep(platformalog.LevelInfo, "render")
+}
+
+func assertSource(t *testing.T, record map[string]any, file string, line int) {
+	t.Helper()
+
+	source, ok := record["source"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected source object in record, got %v", record)
+	}
+
+	if source["file"] != file || source["line"] != float64(line) {
+		t.Fatalf("expected source %s:%d, got %v:%v", file, line, source["file"], source["line"])
+	}
+}
+
+func decodeRecord(t *testing.T, data []byte) map[string]any {
+	t.Helper()
+
+	var record map[string]any
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("failed to decode record %q: %v", string(data), err)
+	}
+
+	return record
+}
+
+func recordSteps(t *testing.T, record map[string]any) []map[string]any {
+	t.Helper()
+
+	rawSteps, ok := record["steps"].([]any)
+	if !ok {
+		t.Fatalf("expected steps in record, got %v", record)
+	}
+
+	steps := make([]map[string]any, 0, len(rawSteps))
+	for _, rawStep := range rawSteps {
+		step, ok := rawStep.(map[string]any)
+		if !ok {
+			t.Fatalf("expected step to be an object, got %v", rawStep)
+		}
+		steps = append(steps, step)
+	}
+
+	return steps
+}
+
+func TestWideEventMiddlewareCapturedHeaders(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil)
+	m := platformalog.NewWideEventMiddleware(logger, "", nil,
+		platformalog.WithCapturedHeaders("X-Tenant-Id", "Authorization", "X-Missing"))
+
+	handler := m.Wrap(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Tenant-Id", "tenant-1")
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("User-Agent", "test")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	record := decodeRecord(t, buf.Bytes())
+	if record["request.header.x-tenant-id"] != "tenant-1" {
+		t.Fatalf("expected tenant header to be captured, got %v", record["request.header.x-tenant-id"])
+	}
+
+	if record["request.header.authorization"] != "[REDACTED]" {
+		t.Fatalf("expected authorization header to be redacted, got %v", record["request.header.authorization"])
+	}
+
+	for _, key := range []string{"request.header.x-missing", "request.header.user-agent"} {
+		if _, ok := record[key]; ok {
+			t.Fatalf("expected %s not to be captured, got %v", key, record[key])
+		}
+	}
+}
+
+func TestWideEventMiddlewareHijack(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil)
+	m := platformalog.NewWideEventMiddleware(logger, "", nil)
+
+	done := make(chan struct{})
+	handler := m.Wrap(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			t.Error("expected response writer to implement http.Hijacker")
+			return
+		}
+
+		conn, rw, err := hijacker.Hijack()
+		if err != nil {
+			t.Errorf("expected hijack to succeed, got: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: test\r\nConnection: Upgrade\r\n\r\n")
+		_ = rw.Flush()
+	}))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to dial server: %v", err)
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("GET /ws HTTP/1.1\r\nHost: test\r\nUpgrade: test\r\nConnection: Upgrade\r\n\r\n"))
+	if err != nil {
+		t.Fatalf("failed to write request: %v", err)
+	}
+
+	status, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+
+	if !strings.HasPrefix(status, "HTTP/1.1 101") {
+		t.Fatalf("expected switching protocols response, got %q", status)
+	}
+
+	<-done
+
+	record := decodeRecord(t, buf.Bytes())
+	if record["request.hijacked"] != true {
+		t.Fatalf("expected event to note the hijack, got %v", record)
+	}
+
+	if record["request.status"] != float64(http.StatusSwitchingProtocols) {
+		t.Fatalf("expected status 101, got %v", record["request.status"])
+	}
+}
+
+func TestNewHTTPMiddleware(t *testing.T) {
+	t.Parallel()
+
+	t.Run("writes one event per request", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil)
+		handler := platformalog.NewHTTPMiddleware(logger).Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			platformalog.EventFromContext(r.Context()).AddAttrs(map[string]any{"user.id": "u1"})
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte("hello"))
+			_, _ = w.Write([]byte(" world"))
+		}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/orders", nil))
+
+		if lines := bytes.Count(buf.Bytes(), []byte("\n")); lines != 1 {
+			t.Fatalf("expected a single event, got %d: %s", lines, buf.String())
+		}
+
+		record := decodeRecord(t, buf.Bytes())
+		want := map[string]any{
+			"name":           "http.request",
+			"request.method": http.MethodPost,
+			"request.path":   "/orders",
+			"request.status": float64(http.StatusCreated),
+			"response.bytes": float64(len("hello world")),
+			"user.id":        "u1",
+		}
+		for key, value := range want {
+			if record[key] != value {
+				t.Errorf("expected %s=%v, got %v", key, value, record[key])
+			}
+		}
+	})
+
+	t.Run("writes event of panicking handler", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.NewWideEventLogger(&buf, nil, "json", nil)
+		handler := platformalog.NewHTTPMiddleware(logger).Wrap(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+			panic("boom")
+		}))
+
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected panic to be re-raised")
+				}
+			}()
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		}()
+
+		record := decodeRecord(t, buf.Bytes())
+		if record["request.status"] != float64(http.StatusInternalServerError) || record["response.bytes"] != float64(0) {
+			t.Fatalf("expected status 500 without body, got %v", record)
+		}
+
+		if record["level"] != "ERROR" {
+			t.Fatalf("expected error event, got %v", record["level"])
+		}
+	})
+}
@@ -7,9 +7,13 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"strings"
 )
 
-const defaultWideEventName = "http.request"
+const (
+	defaultWideEventName = "http.request"
+	redactedHeaderValue  = "[REDACTED]"
+)
 
 var errPanicRecovered = errors.New("panic recovered")
 
@@ -18,11 +22,12 @@ type WideEventMiddleware struct {
 	logger     *WideEventLogger
 	eventName  string
 	contextKey any
+	headers    []string
 }
 
 // NewWideEventMiddleware creates middleware that stores a wide event in request context
-// and writes it after request processing.
-func NewWideEventMiddleware(logger *WideEventLogger, eventName string, contextKey any) *WideEventMiddleware {
+// and writes it after request processing. Use WithCapturedHeaders to add request headers to the event.
+func NewWideEventMiddleware(logger *WideEventLogger, eventName string, contextKey any, opts ...Option) *WideEventMiddleware {
 	if logger == nil {
 		panic("WideEventMiddleware: logger is nil")
 	}
@@ -35,22 +40,36 @@ func NewWideEventMiddleware(logger *WideEventLogger, eventName string, contextKe
 		contextKey = WideEventKey
 	}
 
+	o := newOptions(opts)
+
 	return &WideEventMiddleware{
 		logger:     logger,
 		eventName:  eventName,
 		contextKey: contextKey,
+		headers:    o.capturedHeaders,
 	}
 }
 
+// NewHTTPMiddleware creates a WideEventMiddleware with the default event name and context key,
+// which writes one http.request event per request with the method, path, status and response size.
+func NewHTTPMiddleware(logger *WideEventLogger, opts ...Option) *WideEventMiddleware {
+	return NewWideEventMiddleware(logger, defaultWideEventName, WideEventKey, opts...)
+}
+
 // Wrap creates request-wide event, stores it in context and writes event after handling.
+// Hijacked connections, e.g. WebSocket upgrades, are marked with request.hijacked. Their status is
+// written by the handler to the raw connection, so 101 Switching Protocols is assumed for upgrade requests.
 func (m *WideEventMiddleware) Wrap(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		event := NewEvent(m.eventName)
+		event.setClock(m.logger.opts.now)
+		event.setCheckpointer(m.logger.WriteCheckpoint)
 		event.AddAttrs(map[string]any{
 			"request.method":     r.Method,
 			"request.path":       r.URL.Path,
 			"request.remoteAddr": r.RemoteAddr,
 		})
+		event.AddAttrs(m.headerAttrs(r))
 
 		ctx := context.WithValue(r.Context(), m.contextKey, event)
 		r = r.WithContext(ctx)
@@ -69,8 +88,18 @@ func (m *WideEventMiddleware) Wrap(next http.Handler) http.Handler {
 				}
 			}
 
+			if recorder.hijacked {
+				if !recorder.wroteHeader && r.Header.Get("Upgrade") != "" {
+					recorder.statusCode = http.StatusSwitchingProtocols
+				}
+				event.AddAttrs(map[string]any{
+					"request.hijacked": true,
+				})
+			}
+
 			event.AddAttrs(map[string]any{
 				"request.status": recorder.statusCode,
+				"response.bytes": recorder.bytes,
 			})
 			m.logger.WriteEvent(ctx, event)
 
@@ -83,10 +112,41 @@ func (m *WideEventMiddleware) Wrap(next http.Handler) http.Handler {
 	})
 }
 
+// headerAttrs returns captured request headers as event attributes.
+func (m *WideEventMiddleware) headerAttrs(r *http.Request) map[string]any {
+	attrs := make(map[string]any, len(m.headers))
+	for _, header := range m.headers {
+		values := r.Header.Values(header)
+		if len(values) == 0 {
+			continue
+		}
+
+		value := strings.Join(values, ", ")
+		if isSensitiveHeader(header) {
+			value = redactedHeaderValue
+		}
+
+		attrs["request.header."+strings.ToLower(header)] = value
+	}
+
+	return attrs
+}
+
+func isSensitiveHeader(header string) bool {
+	switch http.CanonicalHeaderKey(header) {
+	case "Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key":
+		return true
+	default:
+		return false
+	}
+}
+
 type statusResponseWriter struct {
 	http.ResponseWriter
 	statusCode  int
 	wroteHeader bool
+	hijacked    bool
+	bytes       int64
 }
 
 func (w *statusResponseWriter) WriteHeader(statusCode int) {
@@ -101,6 +161,7 @@ func (w *statusResponseWriter) Write(p []byte) (int, error) {
 	}
 
 	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
 	if err != nil {
 		return n, fmt.Errorf("write response body: %w", err)
 	}
@@ -124,6 +185,7 @@ func (w *statusResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	if err != nil {
 		return nil, nil, fmt.Errorf("hijack response writer: %w", err)
 	}
+	w.hijacked = true
 
 	return conn, rw, nil
 }
@@ -0,0 +1,19 @@
+package log
+
+import "context"
+
+// WithWorkerID returns a context that carries the worker ID, so that every record
+// logged with it by a logger from New includes the workerId attribute.
+func WithWorkerID(ctx context.Context, workerID string) context.Context {
+	return context.WithValue(ctx, WorkerIDKey, workerID)
+}
+
+// WorkerIDFromContext returns the worker ID from context or an empty string when it is not set.
+func WorkerIDFromContext(ctx context.Context) string {
+	workerID, ok := ctx.Value(WorkerIDKey).(string)
+	if !ok {
+		return ""
+	}
+
+	return workerID
+}
@@ -0,0 +1,41 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	platformalog "github.com/platforma-dev/platforma/log"
+)
+
+func TestWithWorkerID(t *testing.T) {
+	t.Parallel()
+
+	t.Run("worker-bound logs include worker id", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		logger := platformalog.New(&buf, "json", platformalog.LevelInfo, nil)
+
+		ctx := platformalog.WithWorkerID(context.Background(), "worker-1")
+		logger.InfoContext(ctx, "processing job")
+
+		record := decodeRecord(t, buf.Bytes())
+		if record["workerId"] != "worker-1" {
+			t.Fatalf("expected workerId %q, got %v", "worker-1", record["workerId"])
+		}
+	})
+
+	t.Run("worker id from context", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := platformalog.WithWorkerID(context.Background(), "worker-2")
+		if id := platformalog.WorkerIDFromContext(ctx); id != "worker-2" {
+			t.Fatalf("expected worker id %q, got %q", "worker-2", id)
+		}
+
+		if id := platformalog.WorkerIDFromContext(context.Background()); id != "" {
+			t.Fatalf("expected empty worker id, got %q", id)
+		}
+	})
+}
@@ -19,7 +19,11 @@ var ErrClosedQueue = errors.New("queue is closed")
 type ChanQueue[T any] struct {
 	ch             chan T
 	mu             sync.Mutex
+	sendMu         sync.RWMutex  // Held for reading by enqueues, for writing by Open, Snapshot and Close
+	closing        chan struct{} // Closed by Close to abort enqueues blocked on a full buffer
+	closeOnce      sync.Once
 	opened         bool
+	closed         bool
 	bufferSize     int
 	enqueueTimeout time.Duration
 }
@@ -31,11 +35,15 @@ func NewChanQueue[T any](bufferSize int, enqueueTimeout time.Duration) *ChanQueu
 
 // Open initializes the queue and makes it ready to accept jobs.
 func (q *ChanQueue[T]) Open(_ context.Context) error {
+	q.sendMu.Lock()
+	defer q.sendMu.Unlock()
+
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
 	if !q.opened {
 		q.ch = make(chan T, q.bufferSize)
+		q.closing = make(chan struct{})
 		q.opened = true
 	}
 
@@ -43,34 +51,130 @@ func (q *ChanQueue[T]) Open(_ context.Context) error {
 }
 
 // Close closes the queue and prevents further operations.
+// Enqueues blocked on a full buffer fail with ErrClosedQueue, and the channel is closed
+// only after in-flight enqueues returned, so that they never send on a closed channel.
 func (q *ChanQueue[T]) Close(_ context.Context) error {
+	q.mu.Lock()
+	if !q.opened || q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closeOnce.Do(func() { close(q.closing) })
+	q.mu.Unlock()
+
+	q.sendMu.Lock()
+	defer q.sendMu.Unlock()
+
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	if q.opened {
+	if !q.closed {
 		close(q.ch)
+		q.closed = true
 	}
 
 	return nil
 }
 
-// EnqueueJob adds a job to the queue with timeout support.
+// EnqueueJob adds a job to the queue, waiting at most for the queue's default enqueue timeout.
 func (q *ChanQueue[T]) EnqueueJob(ctx context.Context, job T) error {
-	if q.opened {
-		select {
-		case q.ch <- job:
-			return nil
-		case <-time.After(q.enqueueTimeout):
-			return ErrTimeout
-		case <-ctx.Done():
-			return fmt.Errorf("context cancelled: %w", ctx.Err())
-		}
+	return q.EnqueueJobWithTimeout(ctx, job, q.enqueueTimeout)
+}
+
+// EnqueueJobWithTimeout adds a job to the queue, waiting at most for timeout instead of the queue's default.
+// Context cancellation is respected regardless of the timeout.
+func (q *ChanQueue[T]) EnqueueJobWithTimeout(ctx context.Context, job T, timeout time.Duration) error {
+	q.sendMu.RLock()
+	defer q.sendMu.RUnlock()
+
+	if !q.opened || q.closed {
+		return ErrClosedQueue
+	}
+
+	select {
+	case q.ch <- job:
+		return nil
+	case <-q.closing:
+		return ErrClosedQueue
+	case <-time.After(timeout):
+		return ErrTimeout
+	case <-ctx.Done():
+		return fmt.Errorf("context cancelled: %w", ctx.Err())
+	}
+}
+
+// TryEnqueue adds a job to the queue without waiting. It returns false and no error
+// when the queue is full, so producers can shed load or retry later.
+func (q *ChanQueue[T]) TryEnqueue(ctx context.Context, job T) (bool, error) {
+	q.sendMu.RLock()
+	defer q.sendMu.RUnlock()
+
+	if !q.opened || q.closed {
+		return false, ErrClosedQueue
+	}
+
+	if err := ctx.Err(); err != nil {
+		return false, fmt.Errorf("context cancelled: %w", err)
+	}
+
+	select {
+	case q.ch <- job:
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// Full reports whether the queue buffer is full, so that EnqueueJob would block.
+// An unbuffered queue is always full; a queue that is not open or is closed is never full.
+func (q *ChanQueue[T]) Full() bool {
+	// mu rather than sendMu, which would wait for enqueues blocked on the full buffer
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.opened || q.closed {
+		return false
 	}
 
-	return ErrClosedQueue
+	return len(q.ch) == cap(q.ch)
 }
 
 // GetJobChan returns the underlying channel for reading jobs.
 func (q *ChanQueue[T]) GetJobChan(_ context.Context) (chan T, error) {
 	return q.ch, nil
 }
+
+// Snapshot returns a copy of the jobs currently buffered in the queue, oldest first, without consuming them,
+// e.g. to inspect a stuck queue. The jobs are drained and put back while enqueueing is paused, so Snapshot
+// waits for producers blocked in EnqueueJob to succeed or time out. Jobs taken by workers during the
+// snapshot are not included, and the remaining jobs may be delivered after them.
+// A queue that is not open or is closed has no snapshot.
+func (q *ChanQueue[T]) Snapshot() []T {
+	q.sendMu.Lock()
+	defer q.sendMu.Unlock()
+
+	// Close waits for the snapshot, so that jobs are not put back into a closed channel
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.opened || q.closed {
+		return nil
+	}
+
+	jobs := make([]T, 0, len(q.ch))
+	for drained := false; !drained; {
+		select {
+		case job := <-q.ch:
+			jobs = append(jobs, job)
+		default:
+			drained = true
+		}
+	}
+
+	// no producers can send while sendMu is held, so the drained jobs fit back into the buffer
+	for _, job := range jobs {
+		q.ch <- job
+	}
+
+	return jobs
+}
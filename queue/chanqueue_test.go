@@ -3,6 +3,7 @@ package queue_test
 import (
 	"context"
 	"errors"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -62,6 +63,61 @@ func TestChanQueue(t *testing.T) {
 		}
 	})
 
+	t.Run("per-call enqueue timeout", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		q := queue.NewChanQueue[job](0, 5*time.Second)
+
+		err := q.Open(ctx)
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+		defer q.Close(ctx)
+
+		ch, _ := q.GetJobChan(ctx)
+		go func() {
+			time.Sleep(200 * time.Millisecond)
+			<-ch
+		}()
+
+		start := time.Now()
+		err = q.EnqueueJobWithTimeout(ctx, job{data: 1}, 20*time.Millisecond)
+		if !errors.Is(err, queue.ErrTimeout) {
+			t.Fatalf("expected timeout error, got: %v", err)
+		}
+
+		if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+			t.Fatalf("expected per-call timeout to fail fast, took %s", elapsed)
+		}
+
+		// the default timeout is long enough for the consumer to pick the job up
+		err = q.EnqueueJob(ctx, job{data: 2})
+		if err != nil {
+			t.Fatalf("expected no error with default timeout, got: %s", err.Error())
+		}
+	})
+
+	t.Run("per-call enqueue timeout respects context cancellation", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		q := queue.NewChanQueue[job](0, time.Second)
+
+		err := q.Open(ctx)
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+		defer q.Close(ctx)
+
+		cancel()
+
+		err = q.EnqueueJobWithTimeout(ctx, job{data: 1}, time.Hour)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context cancelled error, got: %v", err)
+		}
+	})
+
 	t.Run("context cancellation", func(t *testing.T) {
 		t.Parallel()
 
@@ -97,4 +153,195 @@ func TestChanQueue(t *testing.T) {
 		}
 	})
 
+	t.Run("close aborts blocked enqueue", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		q := queue.NewChanQueue[job](0, time.Minute)
+		q.Open(ctx)
+
+		errs := make(chan error, 1)
+		go func() { errs <- q.EnqueueJob(ctx, job{data: 1}) }()
+
+		// give the producer time to block on the unbuffered channel
+		time.Sleep(20 * time.Millisecond)
+		q.Close(ctx)
+
+		select {
+		case err := <-errs:
+			if !errors.Is(err, queue.ErrClosedQueue) {
+				t.Fatalf("expected closed queue error, got: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected close to abort the blocked enqueue")
+		}
+
+		if err := q.EnqueueJob(ctx, job{data: 2}); !errors.Is(err, queue.ErrClosedQueue) {
+			t.Fatalf("expected closed queue error after close, got: %v", err)
+		}
+	})
+
+	t.Run("try enqueue", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		q := queue.NewChanQueue[job](1, time.Second)
+
+		err := q.Open(ctx)
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+		defer q.Close(ctx)
+
+		if q.Full() {
+			t.Fatal("expected empty queue not to be full")
+		}
+
+		ok, err := q.TryEnqueue(ctx, job{data: 1})
+		if err != nil || !ok {
+			t.Fatalf("expected job to be enqueued, got: %v, %v", ok, err)
+		}
+
+		if !q.Full() {
+			t.Fatal("expected queue to be full")
+		}
+
+		start := time.Now()
+		ok, err = q.TryEnqueue(ctx, job{data: 2})
+		if err != nil || ok {
+			t.Fatalf("expected full queue to reject job without error, got: %v, %v", ok, err)
+		}
+
+		if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+			t.Fatalf("expected try enqueue not to block, took %s", elapsed)
+		}
+	})
+
+	t.Run("try enqueue to closed queue", func(t *testing.T) {
+		t.Parallel()
+
+		q := queue.NewChanQueue[job](1, time.Second)
+
+		ok, err := q.TryEnqueue(context.Background(), job{data: 1})
+		if ok || !errors.Is(err, queue.ErrClosedQueue) {
+			t.Fatalf("expected closed queue error, got: %v, %v", ok, err)
+		}
+	})
+
+	t.Run("full during open and close", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		q := queue.NewChanQueue[job](1, time.Second)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for range 100 {
+				q.Full()
+			}
+		}()
+
+		if err := q.Open(ctx); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+		if err := q.Close(ctx); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+		<-done
+
+		if q.Full() {
+			t.Fatal("expected closed queue not to be full")
+		}
+	})
+}
+
+func TestChanQueueSnapshot(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns buffered jobs without consuming them", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		q := queue.NewChanQueue[job](5, time.Second)
+		if err := q.Open(ctx); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+		defer q.Close(ctx)
+
+		for i := range 3 {
+			if err := q.EnqueueJob(ctx, job{data: i}); err != nil {
+				t.Fatalf("expected no error, got: %s", err.Error())
+			}
+		}
+
+		snapshot := q.Snapshot()
+		if !slices.Equal(snapshot, []job{{data: 0}, {data: 1}, {data: 2}}) {
+			t.Fatalf("expected snapshot of enqueued jobs, got: %v", snapshot)
+		}
+
+		if err := q.EnqueueJob(ctx, job{data: 3}); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+
+		ch, _ := q.GetJobChan(ctx)
+		for i := range 4 {
+			select {
+			case j := <-ch:
+				if j.data != i {
+					t.Fatalf("expected job %d, got: %d", i, j.data)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("expected job %d to be delivered", i)
+			}
+		}
+	})
+
+	t.Run("waits for blocked producers", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		q := queue.NewChanQueue[job](1, 50*time.Millisecond)
+		if err := q.Open(ctx); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+		defer q.Close(ctx)
+
+		if err := q.EnqueueJob(ctx, job{data: 1}); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+
+		blocked := make(chan error, 1)
+		go func() { blocked <- q.EnqueueJob(ctx, job{data: 2}) }()
+
+		if snapshot := q.Snapshot(); !slices.Equal(snapshot, []job{{data: 1}}) {
+			t.Fatalf("expected snapshot with the buffered job, got: %v", snapshot)
+		}
+
+		if err := <-blocked; err != nil && !errors.Is(err, queue.ErrTimeout) {
+			t.Fatalf("expected blocked producer to time out, got: %v", err)
+		}
+	})
+
+	t.Run("closed queue", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		q := queue.NewChanQueue[job](1, time.Second)
+		if snapshot := q.Snapshot(); snapshot != nil {
+			t.Fatalf("expected no snapshot of unopened queue, got: %v", snapshot)
+		}
+
+		if err := q.Open(ctx); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+		if err := q.EnqueueJob(ctx, job{data: 1}); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+		q.Close(ctx)
+
+		if snapshot := q.Snapshot(); snapshot != nil {
+			t.Fatalf("expected no snapshot of closed queue, got: %v", snapshot)
+		}
+	})
 }
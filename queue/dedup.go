@@ -0,0 +1,78 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DedupKeyFunc returns the idempotency key of a job. Jobs with an empty key are never deduplicated.
+type DedupKeyFunc[T any] func(job T) string
+
+// DedupStore records idempotency keys of processed jobs.
+// Implementations backed by a shared store (e.g. Redis SET NX with expiry) allow deduplication
+// across several processors.
+type DedupStore interface {
+	// Add records key for the duration of window. It returns false if key was already
+	// recorded and has not expired yet.
+	Add(ctx context.Context, key string, window time.Duration) (bool, error)
+}
+
+// MemoryDedupStore is an in-memory DedupStore. Expired keys are removed lazily.
+type MemoryDedupStore struct {
+	mu        sync.Mutex
+	expiresAt map[string]time.Time
+	nextSweep time.Time
+}
+
+var _ DedupStore = (*MemoryDedupStore)(nil)
+
+// NewMemoryDedupStore creates a new empty MemoryDedupStore.
+func NewMemoryDedupStore() *MemoryDedupStore {
+	return &MemoryDedupStore{expiresAt: map[string]time.Time{}}
+}
+
+// Add records key for the duration of window and reports whether it was not recorded yet.
+func (s *MemoryDedupStore) Add(_ context.Context, key string, window time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	// sweep at most once per window so that Add stays cheap
+	if now.After(s.nextSweep) {
+		for k, expiresAt := range s.expiresAt {
+			if !now.Before(expiresAt) {
+				delete(s.expiresAt, k)
+			}
+		}
+		s.nextSweep = now.Add(window)
+	}
+
+	if expiresAt, ok := s.expiresAt[key]; ok && now.Before(expiresAt) {
+		return false, nil
+	}
+
+	s.expiresAt[key] = now.Add(window)
+
+	return true, nil
+}
+
+// Option configures a Processor.
+type Option[T any] func(*Processor[T])
+
+// WithDedup makes the processor skip jobs whose key was already seen within window.
+// Skipped jobs are not passed to the handler, are acknowledged on durable queues and are
+// counted as duplicates in ProcessorHealth. A nil store means an in-memory MemoryDedupStore.
+// If the store fails, the job is processed.
+func WithDedup[T any](keyFunc DedupKeyFunc[T], window time.Duration, store DedupStore) Option[T] {
+	return func(p *Processor[T]) {
+		if store == nil {
+			store = NewMemoryDedupStore()
+		}
+
+		p.dedupKey = keyFunc
+		p.dedupWindow = window
+		p.dedupStore = store
+	}
+}
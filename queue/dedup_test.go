@@ -0,0 +1,139 @@
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/platforma-dev/platforma/queue"
+)
+
+func TestMemoryDedupStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := queue.NewMemoryDedupStore()
+
+	for i, want := range []bool{true, false} {
+		added, err := store.Add(ctx, "key", 50*time.Millisecond)
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+
+		if added != want {
+			t.Fatalf("expected add %d to return %v, got %v", i, want, added)
+		}
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	added, _ := store.Add(ctx, "key", 50*time.Millisecond)
+	if !added {
+		t.Fatal("expected key to be added again after the window")
+	}
+}
+
+func TestProcessorDedup(t *testing.T) {
+	t.Parallel()
+
+	keyFunc := func(j job) string { return strconv.Itoa(j.data) }
+
+	tests := []struct {
+		name           string
+		window         time.Duration
+		pause          time.Duration
+		wantHandled    int32
+		wantDuplicates int64
+	}{
+		{name: "duplicate within window", window: time.Hour, wantHandled: 2, wantDuplicates: 1},
+		{name: "duplicate outside window", window: 20 * time.Millisecond, pause: 50 * time.Millisecond, wantHandled: 3, wantDuplicates: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var handled atomic.Int32
+			q := &mockQueue[job]{
+				jobChan: make(chan job, 10),
+			}
+
+			p := queue.New(queue.HandlerFunc[job](func(_ context.Context, _ job) {
+				handled.Add(1)
+			}), q, 1, time.Second, queue.WithDedup(keyFunc, tt.window, nil))
+
+			go p.Run(ctx)
+
+			p.Enqueue(ctx, job{data: 1})
+			p.Enqueue(ctx, job{data: 2})
+			waitForCount(t, &handled, 2)
+
+			time.Sleep(tt.pause)
+			p.Enqueue(ctx, job{data: 1})
+
+			if err := p.Stop(ctx); err != nil {
+				t.Fatalf("expected no error, got: %s", err.Error())
+			}
+
+			if handled.Load() != tt.wantHandled {
+				t.Fatalf("expected %d handled jobs, got: %d", tt.wantHandled, handled.Load())
+			}
+
+			health, ok := p.Healthcheck(ctx).(queue.ProcessorHealth)
+			if !ok {
+				t.Fatalf("expected ProcessorHealth, got: %T", p.Healthcheck(ctx))
+			}
+
+			if health.Duplicates != tt.wantDuplicates {
+				t.Fatalf("expected %d duplicates, got: %d", tt.wantDuplicates, health.Duplicates)
+			}
+		})
+	}
+
+	t.Run("store failure processes job", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var handled atomic.Int32
+		q := &mockQueue[job]{
+			jobChan: make(chan job, 10),
+		}
+
+		p := queue.New(queue.HandlerFunc[job](func(_ context.Context, _ job) {
+			handled.Add(1)
+		}), q, 1, time.Second, queue.WithDedup(keyFunc, time.Hour, failingDedupStore{}))
+
+		go p.Run(ctx)
+
+		p.Enqueue(ctx, job{data: 1})
+		p.Enqueue(ctx, job{data: 1})
+		waitForCount(t, &handled, 2)
+	})
+}
+
+type failingDedupStore struct{}
+
+func (failingDedupStore) Add(_ context.Context, _ string, _ time.Duration) (bool, error) {
+	return false, errors.New("store unavailable")
+}
+
+func waitForCount(t *testing.T, counter *atomic.Int32, want int32) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for counter.Load() < want && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if counter.Load() < want {
+		t.Fatalf("expected %d handled jobs, got: %d", want, counter.Load())
+	}
+}
@@ -0,0 +1,497 @@
+package queue
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/platforma-dev/platforma/log"
+)
+
+// ErrJobNotFound is returned when acknowledging a job that is not pending in the queue.
+var ErrJobNotFound = errors.New("job not found")
+
+// fileQueueRecord is a single line of the FileQueue append-only log.
+// Enqueue records carry the job payload, ack records only carry the ID.
+type fileQueueRecord struct {
+	ID  string          `json:"id"`
+	Job json.RawMessage `json:"job,omitempty"`
+	Ack bool            `json:"ack,omitempty"`
+}
+
+// fileQueueEntry is a job that was enqueued but not acknowledged yet.
+type fileQueueEntry struct {
+	id  string
+	job json.RawMessage
+	// key is the job encoded again after decoding, so that it matches the encoding of the job passed to Ack
+	// even if the record was written by another version of the job type.
+	key []byte
+	// seq is the position of the job in the channel since Open, or -1 while it is not in the channel.
+	seq int64
+}
+
+// FileQueue is a durable queue that persists jobs to an append-only file in JSON lines format.
+// Jobs stay in the file until they are acknowledged, so jobs that were not processed
+// before shutdown are replayed on the next Open. Jobs must be JSON-serializable.
+type FileQueue[T any] struct {
+	path              string
+	bufferSize        int
+	enqueueTimeout    time.Duration
+	visibilityTimeout time.Duration
+
+	// chMu guards the job channel lifecycle so that it is never closed during a send.
+	chMu   sync.RWMutex
+	ch     chan T
+	opened bool
+	// sendSem serializes sends, so that jobs leave the channel in the order of their seq.
+	sendSem chan struct{}
+	// closing is closed by Close to abort sends blocked on a full buffer.
+	closing      chan struct{}
+	closeClosing func()
+
+	mu      sync.Mutex
+	file    *os.File
+	pending []fileQueueEntry
+	// sent is the number of jobs sent to the channel since Open. Jobs with a seq below
+	// sent minus the number of buffered jobs were received, i.e. delivered to a worker.
+	sent int64
+}
+
+var _ VisibilityTimeoutProvider[any] = (*FileQueue[any])(nil)
+
+// NewFileQueue creates a new file-backed queue stored at path with the specified buffer size and enqueue timeout.
+func NewFileQueue[T any](path string, bufferSize int, enqueueTimeout time.Duration) *FileQueue[T] {
+	return &FileQueue[T]{path: path, bufferSize: bufferSize, enqueueTimeout: enqueueTimeout}
+}
+
+// SetVisibilityTimeout sets the time after which jobs picked up by a Processor but not acknowledged are redelivered.
+// Zero, the default, disables redelivery, so such jobs are only replayed on the next Open. Must be called before Open.
+func (q *FileQueue[T]) SetVisibilityTimeout(timeout time.Duration) {
+	q.visibilityTimeout = timeout
+}
+
+// VisibilityTimeout returns the visibility timeout set by SetVisibilityTimeout.
+func (q *FileQueue[T]) VisibilityTimeout() time.Duration {
+	return q.visibilityTimeout
+}
+
+// Open replays unacknowledged jobs from the file, compacts it and makes the queue ready to accept jobs.
+func (q *FileQueue[T]) Open(ctx context.Context) error {
+	q.chMu.Lock()
+	defer q.chMu.Unlock()
+
+	if q.opened {
+		return nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	pending, err := q.replay(ctx)
+	if err != nil {
+		return err
+	}
+
+	jobs := make([]T, 0, len(pending))
+	for i, entry := range pending {
+		var job T
+		if err := json.Unmarshal(entry.job, &job); err != nil {
+			return fmt.Errorf("failed to decode job %s: %w", entry.id, err)
+		}
+
+		key, err := json.Marshal(job)
+		if err != nil {
+			return fmt.Errorf("failed to encode job %s: %w", entry.id, err)
+		}
+
+		pending[i].key = key
+		pending[i].seq = int64(i)
+		jobs = append(jobs, job)
+	}
+
+	q.pending = pending
+	if err := q.compactNoLock(); err != nil {
+		return err
+	}
+
+	// Replayed jobs get extra capacity so that Open never blocks on a full buffer
+	q.ch = make(chan T, q.bufferSize+len(jobs))
+	for _, job := range jobs {
+		q.ch <- job
+	}
+	q.sent = int64(len(jobs))
+	q.sendSem = make(chan struct{}, 1)
+	q.closing = make(chan struct{})
+	q.closeClosing = sync.OnceFunc(func() { close(q.closing) })
+	q.opened = true
+
+	if len(jobs) > 0 {
+		log.InfoContext(ctx, "replayed pending jobs", "path", q.path, "jobs", len(jobs))
+	}
+
+	return nil
+}
+
+// Close closes the queue and the underlying file. Unacknowledged jobs are kept in the file.
+// Enqueues blocked on a full buffer fail with ErrClosedQueue.
+func (q *FileQueue[T]) Close(_ context.Context) error {
+	q.mu.Lock()
+	if q.closeClosing != nil {
+		q.closeClosing()
+	}
+	q.mu.Unlock()
+
+	q.chMu.Lock()
+	defer q.chMu.Unlock()
+
+	if !q.opened {
+		return nil
+	}
+
+	close(q.ch)
+	q.opened = false
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.file.Close(); err != nil {
+		return fmt.Errorf("failed to close queue file: %w", err)
+	}
+	q.file = nil
+
+	return nil
+}
+
+// EnqueueJob persists a job to the file and adds it to the queue, waiting at most for the queue's default enqueue timeout.
+func (q *FileQueue[T]) EnqueueJob(ctx context.Context, job T) error {
+	return q.EnqueueJobWithTimeout(ctx, job, q.enqueueTimeout)
+}
+
+// EnqueueJobWithTimeout persists a job to the file and adds it to the queue, waiting at most for timeout
+// instead of the queue's default. Context cancellation is respected regardless of the timeout.
+func (q *FileQueue[T]) EnqueueJobWithTimeout(ctx context.Context, job T, timeout time.Duration) error {
+	q.chMu.RLock()
+	defer q.chMu.RUnlock()
+
+	if !q.opened {
+		return ErrClosedQueue
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+
+	entry := fileQueueEntry{id: uuid.NewString(), job: data, key: data, seq: -1}
+
+	q.mu.Lock()
+	err = q.appendRecord(fileQueueRecord{ID: entry.id, Job: entry.job})
+	if err == nil {
+		q.pending = append(q.pending, entry)
+	}
+	q.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := q.send(ctx, entry.id, job, timeout); err != nil {
+		q.discard(ctx, entry.id)
+		return err
+	}
+
+	return nil
+}
+
+// send puts the job of the pending entry with id into the channel, waiting at most for timeout.
+// The caller must hold chMu for reading.
+func (q *FileQueue[T]) send(ctx context.Context, id string, job T, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case q.sendSem <- struct{}{}:
+	case <-timer.C:
+		return ErrTimeout
+	case <-ctx.Done():
+		return fmt.Errorf("context cancelled: %w", ctx.Err())
+	case <-q.closing:
+		return ErrClosedQueue
+	}
+	defer func() { <-q.sendSem }()
+
+	// the seq is taken before the send, so that a worker acknowledging the job right after
+	// receiving it finds it delivered
+	q.mu.Lock()
+	q.setSeqNoLock(id, q.sent)
+	q.sent++
+	q.mu.Unlock()
+
+	var err error
+	select {
+	case q.ch <- job:
+		return nil
+	case <-timer.C:
+		err = ErrTimeout
+	case <-ctx.Done():
+		err = fmt.Errorf("context cancelled: %w", ctx.Err())
+	case <-q.closing:
+		err = ErrClosedQueue
+	}
+
+	q.mu.Lock()
+	q.setSeqNoLock(id, -1)
+	q.sent--
+	q.mu.Unlock()
+
+	return err
+}
+
+// GetJobChan returns the underlying channel for reading jobs.
+func (q *FileQueue[T]) GetJobChan(_ context.Context) (chan T, error) {
+	return q.ch, nil
+}
+
+// Ack marks a job delivered to a worker as processed so it is not replayed after restart.
+// Jobs still waiting in the channel can't be acknowledged. If several equal jobs were delivered,
+// the one delivered first is acknowledged.
+func (q *FileQueue[T]) Ack(_ context.Context, job T) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+
+	q.chMu.RLock()
+	defer q.chMu.RUnlock()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := q.deliveredNoLock(data)
+	if i < 0 {
+		return ErrJobNotFound
+	}
+
+	if err := q.appendRecord(fileQueueRecord{ID: q.pending[i].id, Ack: true}); err != nil {
+		return err
+	}
+	q.pending = slices.Delete(q.pending, i, i+1)
+
+	return nil
+}
+
+// Nack returns a job delivered to a worker back to the queue for redelivery. If the job can't be put
+// back into the channel, e.g. because it is full, it stays in the file and is replayed on the next Open.
+func (q *FileQueue[T]) Nack(ctx context.Context, job T) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+
+	q.chMu.RLock()
+	defer q.chMu.RUnlock()
+
+	if !q.opened {
+		return ErrClosedQueue
+	}
+
+	q.mu.Lock()
+	i := q.deliveredNoLock(data)
+	var id string
+	if i >= 0 {
+		// Move the entry to the back, the order in which jobs are replayed after restart
+		entry := q.pending[i]
+		entry.seq = -1
+		id = entry.id
+		q.pending = append(slices.Delete(q.pending, i, i+1), entry)
+	}
+	q.mu.Unlock()
+
+	if i < 0 {
+		return ErrJobNotFound
+	}
+
+	return q.send(ctx, id, job, q.enqueueTimeout)
+}
+
+// Compact rewrites the file so that it only contains jobs that were not acknowledged yet.
+func (q *FileQueue[T]) Compact(_ context.Context) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.compactNoLock()
+}
+
+// Pending returns the number of jobs that were enqueued but not acknowledged yet.
+func (q *FileQueue[T]) Pending() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.pending)
+}
+
+// discard removes a job that could not be delivered to the channel.
+func (q *FileQueue[T]) discard(ctx context.Context, id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := slices.IndexFunc(q.pending, func(e fileQueueEntry) bool { return e.id == id })
+	if i < 0 {
+		return
+	}
+
+	if err := q.appendRecord(fileQueueRecord{ID: id, Ack: true}); err != nil {
+		log.ErrorContext(ctx, "failed to discard undelivered job", "error", err, "path", q.path)
+		return
+	}
+	q.pending = slices.Delete(q.pending, i, i+1)
+}
+
+// deliveredNoLock returns the index of the pending entry with the given job key that was received
+// from the channel first, or -1. The caller must hold chMu for reading.
+func (q *FileQueue[T]) deliveredNoLock(key []byte) int {
+	received := q.sent - int64(len(q.ch))
+
+	found := -1
+	for i, entry := range q.pending {
+		if entry.seq < 0 || entry.seq >= received || !bytes.Equal(entry.key, key) {
+			continue
+		}
+
+		if found < 0 || entry.seq < q.pending[found].seq {
+			found = i
+		}
+	}
+
+	return found
+}
+
+func (q *FileQueue[T]) setSeqNoLock(id string, seq int64) {
+	if i := slices.IndexFunc(q.pending, func(e fileQueueEntry) bool { return e.id == id }); i >= 0 {
+		q.pending[i].seq = seq
+	}
+}
+
+// replay reads the log and returns enqueued jobs that were not acknowledged, in enqueue order.
+func (q *FileQueue[T]) replay(ctx context.Context) ([]fileQueueEntry, error) {
+	file, err := os.Open(q.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open queue file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	pending := []fileQueueEntry{}
+	reader := bufio.NewReader(file)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return nil, fmt.Errorf("failed to read queue file: %w", readErr)
+		}
+
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			var record fileQueueRecord
+			if err := json.Unmarshal(line, &record); err != nil {
+				// A partially written last line is expected after a crash
+				log.WarnContext(ctx, "skipping malformed queue record", "error", err, "path", q.path)
+			} else if record.Ack {
+				pending = slices.DeleteFunc(pending, func(e fileQueueEntry) bool { return e.id == record.ID })
+			} else {
+				pending = append(pending, fileQueueEntry{id: record.ID, job: record.Job})
+			}
+		}
+
+		if errors.Is(readErr, io.EOF) {
+			return pending, nil
+		}
+	}
+}
+
+// compactNoLock writes pending jobs to a temporary file and atomically replaces the log with it.
+func (q *FileQueue[T]) compactNoLock() error {
+	tmpPath := q.path + ".tmp"
+
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create compacted queue file: %w", err)
+	}
+
+	writer := bufio.NewWriter(tmp)
+	for _, entry := range q.pending {
+		if err := writeRecord(writer, fileQueueRecord{ID: entry.id, Job: entry.job}); err != nil {
+			_ = tmp.Close()
+			return err
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write compacted queue file: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to sync compacted queue file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close compacted queue file: %w", err)
+	}
+
+	if q.file != nil {
+		_ = q.file.Close()
+		q.file = nil
+	}
+
+	if err := os.Rename(tmpPath, q.path); err != nil {
+		return fmt.Errorf("failed to replace queue file: %w", err)
+	}
+
+	file, err := os.OpenFile(q.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open queue file: %w", err)
+	}
+	q.file = file
+
+	return nil
+}
+
+func (q *FileQueue[T]) appendRecord(record fileQueueRecord) error {
+	if q.file == nil {
+		return ErrClosedQueue
+	}
+
+	if err := writeRecord(q.file, record); err != nil {
+		return err
+	}
+
+	if err := q.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync queue file: %w", err)
+	}
+
+	return nil
+}
+
+func writeRecord(w io.Writer, record fileQueueRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode queue record: %w", err)
+	}
+
+	if _, err := w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write queue record: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,389 @@
+package queue_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/platforma-dev/platforma/queue"
+)
+
+type durableJob struct {
+	Data int `json:"data"`
+}
+
+func TestFileQueue(t *testing.T) {
+	t.Parallel()
+
+	t.Run("simple enqueue", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		q := queue.NewFileQueue[durableJob](filepath.Join(t.TempDir(), "queue.jsonl"), 3, time.Second)
+
+		err := q.Open(ctx)
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+		defer q.Close(ctx)
+
+		err = q.EnqueueJob(ctx, durableJob{Data: 1})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+
+		ch, err := q.GetJobChan(ctx)
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+
+		select {
+		case j := <-ch:
+			if j.Data != 1 {
+				t.Fatalf("expected data to be 1, got: %d", j.Data)
+			}
+		default:
+			t.Fatalf("expected job to be received from channel")
+		}
+	})
+
+	t.Run("replays unacked jobs after reopen", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		path := filepath.Join(t.TempDir(), "queue.jsonl")
+
+		q := queue.NewFileQueue[durableJob](path, 10, time.Second)
+		if err := q.Open(ctx); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+
+		for i := range 3 {
+			if err := q.EnqueueJob(ctx, durableJob{Data: i + 1}); err != nil {
+				t.Fatalf("expected no error, got: %s", err.Error())
+			}
+		}
+
+		ch, _ := q.GetJobChan(ctx)
+		processed := <-ch
+		if err := q.Ack(ctx, processed); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+
+		// simulate restart
+		if err := q.Close(ctx); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+
+		reopened := queue.NewFileQueue[durableJob](path, 10, time.Second)
+		if err := reopened.Open(ctx); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+		defer reopened.Close(ctx)
+
+		if reopened.Pending() != 2 {
+			t.Fatalf("expected 2 pending jobs, got: %d", reopened.Pending())
+		}
+
+		ch, _ = reopened.GetJobChan(ctx)
+		for _, want := range []int{2, 3} {
+			select {
+			case j := <-ch:
+				if j.Data != want {
+					t.Fatalf("expected data to be %d, got: %d", want, j.Data)
+				}
+			default:
+				t.Fatalf("expected job %d to be replayed", want)
+			}
+		}
+	})
+
+	t.Run("compacts acked entries", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		path := filepath.Join(t.TempDir(), "queue.jsonl")
+
+		q := queue.NewFileQueue[durableJob](path, 10, time.Second)
+		if err := q.Open(ctx); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+		defer q.Close(ctx)
+
+		for i := range 3 {
+			q.EnqueueJob(ctx, durableJob{Data: i + 1})
+		}
+
+		// jobs can only be acked once they were delivered
+		ch, _ := q.GetJobChan(ctx)
+		for range 3 {
+			<-ch
+		}
+		q.Ack(ctx, durableJob{Data: 1})
+		q.Ack(ctx, durableJob{Data: 3})
+
+		// 3 enqueue records + 2 ack records
+		if lines := countLines(t, path); lines != 5 {
+			t.Fatalf("expected 5 lines before compaction, got: %d", lines)
+		}
+
+		if err := q.Compact(ctx); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+
+		if lines := countLines(t, path); lines != 1 {
+			t.Fatalf("expected 1 line after compaction, got: %d", lines)
+		}
+
+		// queue keeps appending after compaction
+		q.EnqueueJob(ctx, durableJob{Data: 4})
+		if lines := countLines(t, path); lines != 2 {
+			t.Fatalf("expected 2 lines after enqueue, got: %d", lines)
+		}
+	})
+
+	t.Run("ack unknown job", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		q := queue.NewFileQueue[durableJob](filepath.Join(t.TempDir(), "queue.jsonl"), 3, time.Second)
+		if err := q.Open(ctx); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+		defer q.Close(ctx)
+
+		err := q.Ack(ctx, durableJob{Data: 42})
+		if !errors.Is(err, queue.ErrJobNotFound) {
+			t.Fatalf("expected job not found error, got: %v", err)
+		}
+	})
+
+	t.Run("ack only matches delivered jobs", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		q := queue.NewFileQueue[durableJob](filepath.Join(t.TempDir(), "queue.jsonl"), 3, time.Second)
+		if err := q.Open(ctx); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+		defer q.Close(ctx)
+
+		if err := q.EnqueueJob(ctx, durableJob{Data: 1}); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+
+		// the job is still waiting in the channel, acknowledging it would lose it on a crash
+		if err := q.Ack(ctx, durableJob{Data: 1}); !errors.Is(err, queue.ErrJobNotFound) {
+			t.Fatalf("expected job not found error, got: %v", err)
+		}
+
+		ch, _ := q.GetJobChan(ctx)
+		if err := q.Ack(ctx, <-ch); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+
+		if q.Pending() != 0 {
+			t.Fatalf("expected no pending jobs, got: %d", q.Pending())
+		}
+	})
+
+	t.Run("acks replayed job written by another version", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		path := filepath.Join(t.TempDir(), "queue.jsonl")
+
+		record := `{"id":"1","job":{"priority":2, "data":1}}` + "\n"
+		if err := os.WriteFile(path, []byte(record), 0o600); err != nil {
+			t.Fatalf("failed to write queue file: %v", err)
+		}
+
+		q := queue.NewFileQueue[durableJob](path, 3, time.Second)
+		if err := q.Open(ctx); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+		defer q.Close(ctx)
+
+		ch, _ := q.GetJobChan(ctx)
+		if err := q.Ack(ctx, <-ch); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+
+		if q.Pending() != 0 {
+			t.Fatalf("expected no pending jobs, got: %d", q.Pending())
+		}
+	})
+
+	t.Run("close aborts enqueue blocked on full buffer", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		q := queue.NewFileQueue[durableJob](filepath.Join(t.TempDir(), "queue.jsonl"), 1, time.Minute)
+		if err := q.Open(ctx); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+
+		if err := q.EnqueueJob(ctx, durableJob{Data: 1}); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+
+		errs := make(chan error, 1)
+		go func() { errs <- q.EnqueueJob(ctx, durableJob{Data: 2}) }()
+
+		// let the second enqueue block on the full buffer
+		time.Sleep(50 * time.Millisecond)
+
+		closed := make(chan error, 1)
+		go func() { closed <- q.Close(ctx) }()
+
+		select {
+		case err := <-closed:
+			if err != nil {
+				t.Fatalf("expected no error, got: %s", err.Error())
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected close not to wait for the blocked enqueue")
+		}
+
+		if err := <-errs; !errors.Is(err, queue.ErrClosedQueue) {
+			t.Fatalf("expected closed queue error, got: %v", err)
+		}
+	})
+
+	t.Run("nack redelivers job", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		q := queue.NewFileQueue[durableJob](filepath.Join(t.TempDir(), "queue.jsonl"), 3, time.Second)
+		if err := q.Open(ctx); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+		defer q.Close(ctx)
+
+		q.EnqueueJob(ctx, durableJob{Data: 1})
+		ch, _ := q.GetJobChan(ctx)
+		j := <-ch
+
+		if err := q.Nack(ctx, j); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+
+		select {
+		case redelivered := <-ch:
+			if redelivered.Data != 1 {
+				t.Fatalf("expected data to be 1, got: %d", redelivered.Data)
+			}
+		default:
+			t.Fatalf("expected job to be redelivered")
+		}
+
+		if q.Pending() != 1 {
+			t.Fatalf("expected 1 pending job, got: %d", q.Pending())
+		}
+	})
+
+	t.Run("enqueue timeout is not replayed", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		path := filepath.Join(t.TempDir(), "queue.jsonl")
+
+		q := queue.NewFileQueue[durableJob](path, 0, 10*time.Millisecond)
+		if err := q.Open(ctx); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+
+		err := q.EnqueueJob(ctx, durableJob{Data: 1})
+		if !errors.Is(err, queue.ErrTimeout) {
+			t.Fatalf("expected timeout error, got: %v", err)
+		}
+		q.Close(ctx)
+
+		reopened := queue.NewFileQueue[durableJob](path, 0, 10*time.Millisecond)
+		if err := reopened.Open(ctx); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+		defer reopened.Close(ctx)
+
+		if reopened.Pending() != 0 {
+			t.Fatalf("expected no pending jobs, got: %d", reopened.Pending())
+		}
+	})
+
+	t.Run("enqueue to closed queue", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		q := queue.NewFileQueue[durableJob](filepath.Join(t.TempDir(), "queue.jsonl"), 0, time.Second)
+
+		err := q.EnqueueJob(ctx, durableJob{Data: 1})
+		if !errors.Is(err, queue.ErrClosedQueue) {
+			t.Fatalf("expected closed queue error, got: %v", err)
+		}
+	})
+
+	t.Run("processor acks handled jobs", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		path := filepath.Join(t.TempDir(), "queue.jsonl")
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+
+		q := queue.NewFileQueue[durableJob](path, 10, time.Second)
+		p := queue.New(queue.HandlerFunc[durableJob](func(_ context.Context, _ durableJob) {
+			wg.Done()
+		}), q, 1, time.Millisecond)
+
+		done := make(chan struct{})
+		go func() {
+			p.Run(ctx)
+			close(done)
+		}()
+
+		deadline := time.Now().Add(5 * time.Second)
+		for p.Enqueue(ctx, durableJob{Data: 1}) != nil && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		p.Enqueue(ctx, durableJob{Data: 2})
+
+		wg.Wait()
+		cancel()
+		<-done
+
+		reopened := queue.NewFileQueue[durableJob](path, 10, time.Second)
+		if err := reopened.Open(context.Background()); err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+		defer reopened.Close(context.Background())
+
+		if reopened.Pending() != 0 {
+			t.Fatalf("expected no pending jobs after processing, got: %d", reopened.Pending())
+		}
+	})
+}
+
+func countLines(t *testing.T, path string) int {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open file: %s", err.Error())
+	}
+	defer file.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines++
+	}
+
+	return lines
+}
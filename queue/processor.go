@@ -2,14 +2,24 @@ package queue
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/platforma-dev/platforma/log"
 )
 
+// ErrProcessorStopped is returned when enqueueing a job after Stop was called
+// or after Run started shutting down because its context was cancelled.
+var ErrProcessorStopped = errors.New("processor is stopped")
+
+// ErrProcessorNotRunning is returned by Enqueue of a processor created with WithRequireRunning
+// when Run has not opened the queue yet or has already returned.
+var ErrProcessorNotRunning = errors.New("processor is not running")
+
 // Handler defines the interface for processing jobs.
 type Handler[T any] interface {
 	Handle(ctx context.Context, job T)
@@ -31,6 +41,34 @@ type Provider[T any] interface {
 	GetJobChan(ctx context.Context) (chan T, error)
 }
 
+// DurableProvider is a Provider that keeps jobs until they are acknowledged.
+// Processor acknowledges a job after its handler returns. Jobs that were never
+// acknowledged (e.g. because the handler panicked) are redelivered by the provider.
+type DurableProvider[T any] interface {
+	Provider[T]
+	Ack(ctx context.Context, job T) error
+	Nack(ctx context.Context, job T) error
+}
+
+// ProcessorHealth contains job counters of a Processor.
+type ProcessorHealth struct {
+	// Processed is the number of jobs passed to the handler, including drained ones.
+	Processed int64 `json:"processed"`
+	// Drained is the number of jobs processed after shutdown was requested.
+	Drained int64 `json:"drained"`
+	// Unprocessed is the number of jobs left in the queue when the processor stopped.
+	Unprocessed int64 `json:"unprocessed"`
+	// Duplicates is the number of jobs skipped by deduplication.
+	Duplicates int64 `json:"duplicates"`
+	// Redelivered is the number of jobs returned to the queue after exceeding the visibility timeout.
+	Redelivered int64 `json:"redelivered"`
+	// AvgWait is the average time jobs spent in the queue between Enqueue and being picked up by a worker.
+	// Only jobs that embed EnqueueTime are measured.
+	AvgWait time.Duration `json:"avgWait"`
+	// MaxWait is the longest time a job spent in the queue.
+	MaxWait time.Duration `json:"maxWait"`
+}
+
 // Processor manages a pool of workers to process jobs from a queue.
 type Processor[T any] struct {
 	handler         Handler[T]
@@ -38,15 +76,80 @@ type Processor[T any] struct {
 	wg              sync.WaitGroup
 	workersAmount   int
 	shutdownTimeout time.Duration
+
+	processed   atomic.Int64
+	drained     atomic.Int64
+	unprocessed atomic.Int64
+	duplicates  atomic.Int64
+	redelivered atomic.Int64
+	waits       waitTracker
+
+	dedupKey    DedupKeyFunc[T]
+	dedupWindow time.Duration
+	dedupStore  DedupStore
+
+	// stop is closed by Stop, done is closed when Run returns
+	stop     chan struct{}
+	stopOnce sync.Once
+	stopped  atomic.Bool
+	running  atomic.Bool
+	// opened is true while Run has the queue open, see WithRequireRunning
+	opened         atomic.Bool
+	requireRunning bool
+	// shuttingDown rejects enqueues once workers stop taking new jobs. Enqueues hold enqueueMu
+	// for reading, so that Run closes the queue only after in-flight enqueues returned.
+	shuttingDown atomic.Bool
+	enqueueMu    sync.RWMutex
+	done         chan struct{}
+	doneOnce     sync.Once
 }
 
 // New creates a new Processor with the specified handler, queue, and configuration.
-func New[T any](handler Handler[T], queue Provider[T], workersAmount int, shutdownTimeout time.Duration) *Processor[T] {
-	return &Processor[T]{handler: handler, queue: queue, workersAmount: workersAmount, shutdownTimeout: shutdownTimeout}
+func New[T any](handler Handler[T], queue Provider[T], workersAmount int, shutdownTimeout time.Duration, opts ...Option[T]) *Processor[T] {
+	p := &Processor[T]{
+		handler:         handler,
+		queue:           queue,
+		workersAmount:   workersAmount,
+		shutdownTimeout: shutdownTimeout,
+		stop:            make(chan struct{}),
+		done:            make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// WithRequireRunning makes Enqueue fail with ErrProcessorNotRunning unless Run has opened the queue,
+// instead of passing jobs to a queue that no worker reads yet, or anymore.
+func WithRequireRunning[T any]() Option[T] {
+	return func(p *Processor[T]) {
+		p.requireRunning = true
+	}
 }
 
 // Enqueue adds a job to the queue for processing.
+// Jobs are rejected with ErrProcessorStopped once the processor is shutting down.
 func (p *Processor[T]) Enqueue(ctx context.Context, job T) error {
+	p.enqueueMu.RLock()
+	defer p.enqueueMu.RUnlock()
+
+	if p.stopped.Load() {
+		return ErrProcessorStopped
+	}
+
+	if p.requireRunning && !p.opened.Load() {
+		return ErrProcessorNotRunning
+	}
+
+	if p.shuttingDown.Load() {
+		return ErrProcessorStopped
+	}
+
+	stampEnqueueTime(&job, time.Now())
+
 	err := p.queue.EnqueueJob(ctx, job)
 	if err != nil {
 		return fmt.Errorf("failed to enqueue job: %w", err)
@@ -55,22 +158,46 @@ func (p *Processor[T]) Enqueue(ctx context.Context, job T) error {
 }
 
 // Run starts the queue processor and blocks until all workers complete.
+// Shutdown happens in order: workers stop taking new jobs and enqueues are rejected, workers drain
+// buffered jobs within the shutdown timeout, Run waits for in-flight enqueues to return and only
+// then closes the queue, so that no job is sent to a closed queue.
 func (p *Processor[T]) Run(ctx context.Context) error {
+	p.running.Store(true)
+	defer p.doneOnce.Do(func() { close(p.done) })
+
 	err := p.queue.Open(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to open queue: %w", err)
 	}
+	p.opened.Store(true)
+	p.shuttingDown.Store(false)
 
 	p.wg.Add(p.workersAmount)
 	for range p.workersAmount {
-		workerCtx := context.WithValue(ctx, log.WorkerIDKey, uuid.NewString())
+		workerCtx := log.WithWorkerID(ctx, uuid.NewString())
 
 		go p.worker(workerCtx)
 	}
 
 	p.wg.Wait()
 
-	log.InfoContext(ctx, "all workers shut down")
+	// enqueues that started before shutdown may still be sending, wait for them before closing the queue
+	p.enqueueMu.Lock()
+	p.shuttingDown.Store(true)
+	p.opened.Store(false)
+	p.enqueueMu.Unlock()
+
+	// jobs that are still buffered were abandoned; durable queues will replay them
+	unprocessed := 0
+	if jobChan, err := p.queue.GetJobChan(ctx); err == nil {
+		unprocessed = len(jobChan)
+	}
+	p.unprocessed.Store(int64(unprocessed))
+
+	log.InfoContext(ctx, "all workers shut down", "drained", p.drained.Load(), "unprocessed", unprocessed)
+	if unprocessed > 0 {
+		log.WarnContext(ctx, "jobs left unprocessed after shutdown", "unprocessed", unprocessed)
+	}
 
 	err = p.queue.Close(ctx)
 	if err != nil {
@@ -80,6 +207,25 @@ func (p *Processor[T]) Run(ctx context.Context) error {
 	return nil
 }
 
+// Stop stops the processor without cancelling the context passed to Run, so in-flight handlers keep their context.
+// New jobs are rejected with ErrProcessorStopped and buffered jobs are drained within the shutdown timeout.
+// Stop returns when Run has returned or ctx expires, whichever comes first.
+func (p *Processor[T]) Stop(ctx context.Context) error {
+	p.stopped.Store(true)
+	p.stopOnce.Do(func() { close(p.stop) })
+
+	if !p.running.Load() {
+		return nil
+	}
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to wait for processor to stop: %w", ctx.Err())
+	}
+}
+
 func (p *Processor[T]) worker(ctx context.Context) {
 	defer p.wg.Done()
 	defer log.InfoContext(ctx, "worker finished")
@@ -106,14 +252,20 @@ func (p *Processor[T]) worker(ctx context.Context) {
 		case <-ctx.Done():
 			log.InfoContext(ctx, "skipping job due to shutdown")
 			breakLoop = true
+		case <-p.stop:
+			log.InfoContext(ctx, "skipping job due to stop")
+			breakLoop = true
 		default:
 			select {
 			case job := <-jobChan:
-				p.handler.Handle(ctx, job)
+				p.handle(ctx, job)
 
 			case <-ctx.Done():
 				log.InfoContext(ctx, "shutting down worker")
 				breakLoop = true
+			case <-p.stop:
+				log.InfoContext(ctx, "stopping worker")
+				breakLoop = true
 			}
 		}
 
@@ -122,26 +274,112 @@ func (p *Processor[T]) worker(ctx context.Context) {
 		}
 	}
 
+	// reject new jobs, so that the drain below ends once buffered jobs are handled
+	p.shuttingDown.Store(true)
+
 	// after context is cancelled we try to drain remaining jobs from channel
-	// before shutdown time expired
+	// before shutdown time expired. Stop rejects new jobs, so when stopped
+	// the worker also returns as soon as the channel is empty
+	untilEmpty := ctx.Err() == nil
 	shutdownCtx := context.WithoutCancel(ctx)
 	shutdownCtx, cancel := context.WithTimeout(shutdownCtx, p.shutdownTimeout)
 	defer cancel()
 
-	// same logic with nested select statements as in main loop
+	// same logic with separate select statements as in main loop
 	for {
 		select {
 		case <-shutdownCtx.Done():
 			log.InfoContext(shutdownCtx, "shutdown timeout expired")
 			return
 		default:
+		}
+
+		if untilEmpty {
 			select {
 			case job := <-jobChan:
-				p.handler.Handle(shutdownCtx, job)
-			case <-shutdownCtx.Done():
-				log.InfoContext(shutdownCtx, "shutdown timeout expired")
+				p.handle(shutdownCtx, job)
+				p.drained.Add(1)
+			default:
+				log.InfoContext(shutdownCtx, "queue drained")
 				return
 			}
+
+			continue
+		}
+
+		select {
+		case job := <-jobChan:
+			p.handle(shutdownCtx, job)
+			p.drained.Add(1)
+		case <-shutdownCtx.Done():
+			log.InfoContext(shutdownCtx, "shutdown timeout expired")
+			return
+		}
+	}
+}
+
+// handle passes job to the handler and acknowledges it when the queue is durable.
+// Duplicate jobs are acknowledged without being handled. Jobs that were redelivered
+// after exceeding the visibility timeout are not acknowledged.
+func (p *Processor[T]) handle(ctx context.Context, job T) {
+	p.waits.dequeued(job)
+	release := p.lease(ctx, job)
+
+	if p.isDuplicate(ctx, job) {
+		p.duplicates.Add(1)
+	} else {
+		p.handler.Handle(ctx, job)
+		p.processed.Add(1)
+	}
+
+	if !release() {
+		log.WarnContext(ctx, "job finished after visibility timeout, skipping ack")
+		return
+	}
+
+	if durable, ok := p.queue.(DurableProvider[T]); ok {
+		if err := durable.Ack(ctx, job); err != nil {
+			log.ErrorContext(ctx, "failed to ack job", "error", err)
 		}
 	}
 }
+
+// isDuplicate reports whether the job's key was already seen within the dedup window.
+func (p *Processor[T]) isDuplicate(ctx context.Context, job T) bool {
+	if p.dedupKey == nil {
+		return false
+	}
+
+	key := p.dedupKey(job)
+	if key == "" {
+		return false
+	}
+
+	added, err := p.dedupStore.Add(ctx, key, p.dedupWindow)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to check job for duplicates", "error", err, "key", key)
+		return false
+	}
+
+	if !added {
+		log.DebugContext(ctx, "skipping duplicate job", "key", key)
+	}
+
+	return !added
+}
+
+// Healthcheck returns ProcessorHealth with the number of processed, drained, unprocessed, duplicate
+// and redelivered jobs and the time jobs spent in the queue.
+func (p *Processor[T]) Healthcheck(_ context.Context) any {
+	avgWait, maxWait := p.waits.stats()
+
+	return ProcessorHealth{
+		Processed:   p.processed.Load(),
+		Drained:     p.drained.Load(),
+		Unprocessed: p.unprocessed.Load(),
+		Duplicates:  p.duplicates.Load(),
+		Redelivered: p.redelivered.Load(),
+		AvgWait:     avgWait,
+		MaxWait:     maxWait,
+	}
+}
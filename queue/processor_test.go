@@ -3,6 +3,7 @@ package queue_test
 import (
 	"context"
 	"errors"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -67,6 +68,166 @@ func TestProcessor(t *testing.T) {
 		}
 	})
 
+	t.Run("reports drained and unprocessed jobs", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name            string
+			shutdownTimeout time.Duration
+			wantDrained     int64
+			wantUnprocessed int64
+		}{
+			{name: "abandoned", shutdownTimeout: 0, wantDrained: 0, wantUnprocessed: 5},
+			{name: "drained", shutdownTimeout: 200 * time.Millisecond, wantDrained: 5, wantUnprocessed: 0},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				q := &mockQueue[job]{
+					jobChan: make(chan job, 10),
+				}
+				for i := range 5 {
+					q.jobChan <- job{data: i}
+				}
+
+				p := queue.New(queue.HandlerFunc[job](func(_ context.Context, _ job) {}), q, 1, tt.shutdownTimeout)
+
+				// cancelled before start so that all buffered jobs are left to the shutdown drain
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				err := p.Run(ctx)
+				if err != nil {
+					t.Fatalf("expected no error, got: %s", err.Error())
+				}
+
+				health, ok := p.Healthcheck(context.Background()).(queue.ProcessorHealth)
+				if !ok {
+					t.Fatalf("expected ProcessorHealth, got: %T", p.Healthcheck(context.Background()))
+				}
+
+				if health.Drained != tt.wantDrained {
+					t.Fatalf("expected %d drained jobs, got: %d", tt.wantDrained, health.Drained)
+				}
+
+				if health.Unprocessed != tt.wantUnprocessed {
+					t.Fatalf("expected %d unprocessed jobs, got: %d", tt.wantUnprocessed, health.Unprocessed)
+				}
+
+				if health.Processed != tt.wantDrained {
+					t.Fatalf("expected %d processed jobs, got: %d", tt.wantDrained, health.Processed)
+				}
+			})
+		}
+	})
+
+	t.Run("stop drains without cancelling run context", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		handling := make(chan struct{}, 3)
+		release := make(chan struct{})
+		var handled atomic.Int32
+		var cancelledInHandler atomic.Bool
+
+		q := &mockQueue[job]{
+			jobChan: make(chan job, 10),
+		}
+
+		p := queue.New(queue.HandlerFunc[job](func(ctx context.Context, _ job) {
+			handling <- struct{}{}
+			<-release
+			if ctx.Err() != nil {
+				cancelledInHandler.Store(true)
+			}
+			handled.Add(1)
+		}), q, 1, 5*time.Second)
+
+		runErr := make(chan error, 1)
+		go func() { runErr <- p.Run(ctx) }()
+
+		for range 3 {
+			if err := p.Enqueue(ctx, job{data: 1}); err != nil {
+				t.Fatalf("expected no error, got: %s", err.Error())
+			}
+		}
+
+		// Run is in progress once the first job is being handled
+		<-handling
+
+		stopErr := make(chan error, 1)
+		go func() { stopErr <- p.Stop(context.Background()) }()
+
+		// Stop waits for in-flight and buffered jobs
+		select {
+		case err := <-stopErr:
+			t.Fatalf("expected stop to wait for jobs, got: %v", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+		close(release)
+
+		if err := <-stopErr; err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+
+		if err := <-runErr; err != nil {
+			t.Fatalf("expected no error, got: %s", err.Error())
+		}
+
+		if handled.Load() != 3 {
+			t.Fatalf("expected 3 handled jobs, got: %d", handled.Load())
+		}
+
+		if cancelledInHandler.Load() {
+			t.Fatal("expected handler context not to be cancelled")
+		}
+
+		if ctx.Err() != nil {
+			t.Fatal("expected run context to stay live")
+		}
+
+		err := p.Enqueue(ctx, job{data: 1})
+		if !errors.Is(err, queue.ErrProcessorStopped) {
+			t.Fatalf("expected processor stopped error, got: %v", err)
+		}
+	})
+
+	t.Run("stop returns when context expires", func(t *testing.T) {
+		t.Parallel()
+
+		release := make(chan struct{})
+		defer close(release)
+
+		q := &mockQueue[job]{
+			jobChan: make(chan job, 10),
+		}
+		q.jobChan <- job{data: 1}
+
+		p := queue.New(queue.HandlerFunc[job](func(_ context.Context, _ job) {
+			<-release
+		}), q, 1, 5*time.Second)
+
+		go p.Run(context.Background())
+
+		// wait until the job is being handled
+		deadline := time.Now().Add(5 * time.Second)
+		for len(q.jobChan) != 0 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err := p.Stop(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded error, got: %v", err)
+		}
+	})
+
 	t.Run("run fail", func(t *testing.T) {
 		t.Parallel()
 
@@ -158,3 +319,184 @@ func (q *mockQueue[T]) EnqueueJob(ctx context.Context, job T) error {
 func (q *mockQueue[T]) GetJobChan(_ context.Context) (chan T, error) {
 	return q.jobChan, nil
 }
+
+func TestProcessorEnqueueDuringShutdown(t *testing.T) {
+	t.Parallel()
+
+	for range 20 {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		p := queue.New(queue.HandlerFunc[job](func(_ context.Context, _ job) {}), queue.NewChanQueue[job](4, 10*time.Millisecond), 2, 50*time.Millisecond)
+
+		done := make(chan struct{})
+		go func() {
+			p.Run(ctx)
+			close(done)
+		}()
+
+		var producers sync.WaitGroup
+		errs := make(chan error, 1)
+		for i := range 8 {
+			producers.Go(func() {
+				for {
+					err := p.Enqueue(context.Background(), job{data: i})
+					switch {
+					case err == nil, errors.Is(err, queue.ErrTimeout), errors.Is(err, queue.ErrClosedQueue):
+					case errors.Is(err, queue.ErrProcessorStopped):
+						return
+					default:
+						select {
+						case errs <- err:
+						default:
+						}
+						return
+					}
+
+					select {
+					case <-done:
+						return
+					default:
+					}
+				}
+			})
+		}
+
+		time.Sleep(5 * time.Millisecond)
+		cancel()
+		<-done
+		producers.Wait()
+
+		select {
+		case err := <-errs:
+			t.Fatalf("expected enqueues during shutdown to fail cleanly, got: %v", err)
+		default:
+		}
+
+		if err := p.Enqueue(context.Background(), job{}); !errors.Is(err, queue.ErrProcessorStopped) {
+			t.Fatalf("expected stopped error after shutdown, got: %v", err)
+		}
+	}
+}
+
+type timedJob struct {
+	queue.EnqueueTime
+
+	data int
+}
+
+// runDelayed enqueues jobs, waits for delay before starting the processor and returns
+// its health once all jobs are handled.
+func runDelayed[T any](t *testing.T, delay time.Duration, jobs ...T) queue.ProcessorHealth {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var handled atomic.Int32
+	p := queue.New(queue.HandlerFunc[T](func(_ context.Context, _ T) {
+		handled.Add(1)
+	}), &mockQueue[T]{jobChan: make(chan T, len(jobs))}, 1, time.Second)
+
+	for _, j := range jobs {
+		if err := p.Enqueue(ctx, j); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+	time.Sleep(delay)
+
+	go p.Run(ctx)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for int(handled.Load()) != len(jobs) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	health, ok := p.Healthcheck(ctx).(queue.ProcessorHealth)
+	if !ok {
+		t.Fatalf("expected ProcessorHealth, got: %T", p.Healthcheck(ctx))
+	}
+
+	return health
+}
+
+func TestProcessorWaitTime(t *testing.T) {
+	t.Parallel()
+
+	delay := 50 * time.Millisecond
+
+	t.Run("jobs embedding enqueue time", func(t *testing.T) {
+		t.Parallel()
+
+		// equal jobs are measured too
+		health := runDelayed(t, delay, timedJob{data: 1}, timedJob{data: 1}, timedJob{data: 2})
+
+		if health.AvgWait < delay {
+			t.Fatalf("expected average wait of at least %s, got: %s", delay, health.AvgWait)
+		}
+
+		if health.MaxWait < health.AvgWait {
+			t.Fatalf("expected max wait %s to be at least average wait %s", health.MaxWait, health.AvgWait)
+		}
+	})
+
+	t.Run("pointer jobs", func(t *testing.T) {
+		t.Parallel()
+
+		health := runDelayed(t, delay, &timedJob{data: 1}, &timedJob{data: 2})
+
+		if health.AvgWait < delay {
+			t.Fatalf("expected average wait of at least %s, got: %s", delay, health.AvgWait)
+		}
+	})
+
+	t.Run("jobs without enqueue time", func(t *testing.T) {
+		t.Parallel()
+
+		health := runDelayed(t, delay, job{data: 1}, job{data: 2})
+
+		if health.AvgWait != 0 || health.MaxWait != 0 {
+			t.Fatalf("expected no measured wait, got: avg %s, max %s", health.AvgWait, health.MaxWait)
+		}
+	})
+}
+
+func TestProcessorRequireRunning(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var handled atomic.Int32
+	p := queue.New(queue.HandlerFunc[job](func(_ context.Context, _ job) {
+		handled.Add(1)
+	}), queue.NewChanQueue[job](10, time.Second), 1, time.Second, queue.WithRequireRunning[job]())
+
+	if err := p.Enqueue(ctx, job{data: 1}); !errors.Is(err, queue.ErrProcessorNotRunning) {
+		t.Fatalf("expected not running error before Run, got: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	err := p.Enqueue(ctx, job{data: 1})
+	for errors.Is(err, queue.ErrProcessorNotRunning) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		err = p.Enqueue(ctx, job{data: 1})
+	}
+	if err != nil {
+		t.Fatalf("expected enqueue to succeed after Run, got: %v", err)
+	}
+
+	waitForCount(t, &handled, 1)
+
+	cancel()
+	<-done
+
+	if err := p.Enqueue(context.Background(), job{data: 2}); !errors.Is(err, queue.ErrProcessorNotRunning) {
+		t.Fatalf("expected not running error after Run returned, got: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package queue
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// SyncQueue is a Provider for tests that passes every enqueued job to a handler synchronously,
+// on the goroutine of the caller of EnqueueJob. Jobs are processed when EnqueueJob returns,
+// so tests don't need to poll for results. A panic of the handler propagates to the caller.
+//
+// Its job channel never receives jobs, so a Processor running on a SyncQueue idles until it is stopped.
+// Jobs passed through Processor.Enqueue bypass the processor's deduplication and health counters.
+type SyncQueue[T any] struct {
+	handler Handler[T]
+	jobs    chan T
+	closed  atomic.Bool
+}
+
+var _ Provider[any] = (*SyncQueue[any])(nil)
+
+// NewSyncQueue creates a SyncQueue that processes jobs with handler. The queue accepts jobs
+// until it is closed, without having to be opened first.
+func NewSyncQueue[T any](handler Handler[T]) *SyncQueue[T] {
+	return &SyncQueue[T]{handler: handler, jobs: make(chan T)}
+}
+
+// Open makes a closed queue accept jobs again.
+func (q *SyncQueue[T]) Open(_ context.Context) error {
+	q.closed.Store(false)
+
+	return nil
+}
+
+// Close makes EnqueueJob fail with ErrClosedQueue.
+func (q *SyncQueue[T]) Close(_ context.Context) error {
+	q.closed.Store(true)
+
+	return nil
+}
+
+// EnqueueJob processes the job with the handler and returns after the handler returned.
+func (q *SyncQueue[T]) EnqueueJob(ctx context.Context, job T) error {
+	if q.closed.Load() {
+		return ErrClosedQueue
+	}
+
+	q.handler.Handle(ctx, job)
+
+	return nil
+}
+
+// GetJobChan returns a channel that never receives jobs, as they are processed by EnqueueJob.
+func (q *SyncQueue[T]) GetJobChan(_ context.Context) (chan T, error) {
+	return q.jobs, nil
+}
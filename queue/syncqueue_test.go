@@ -0,0 +1,98 @@
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/platforma-dev/platforma/queue"
+)
+
+func TestSyncQueue(t *testing.T) {
+	t.Parallel()
+
+	t.Run("enqueue processes job synchronously", func(t *testing.T) {
+		t.Parallel()
+
+		var processed []int
+		q := queue.NewSyncQueue(queue.HandlerFunc[job](func(_ context.Context, j job) {
+			processed = append(processed, j.data)
+		}))
+
+		for i := range 3 {
+			if err := q.EnqueueJob(context.Background(), job{data: i + 1}); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			if len(processed) != i+1 {
+				t.Fatalf("expected job %d to be processed when EnqueueJob returned, got %v", i+1, processed)
+			}
+		}
+
+		if !slices.Equal(processed, []int{1, 2, 3}) {
+			t.Fatalf("expected jobs processed in order, got %v", processed)
+		}
+	})
+
+	t.Run("processor enqueue processes job synchronously", func(t *testing.T) {
+		t.Parallel()
+
+		var processed []int
+		handler := queue.HandlerFunc[job](func(_ context.Context, j job) {
+			processed = append(processed, j.data)
+		})
+		p := queue.New(handler, queue.NewSyncQueue[job](handler), 1, 0)
+
+		if err := p.Enqueue(context.Background(), job{data: 42}); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		if !slices.Equal(processed, []int{42}) {
+			t.Fatalf("expected job 42 to be processed, got %v", processed)
+		}
+	})
+
+	t.Run("handler can enqueue follow-up jobs", func(t *testing.T) {
+		t.Parallel()
+
+		var processed []int
+		var q *queue.SyncQueue[job]
+		q = queue.NewSyncQueue(queue.HandlerFunc[job](func(ctx context.Context, j job) {
+			processed = append(processed, j.data)
+			if j.data < 3 {
+				q.EnqueueJob(ctx, job{data: j.data + 1})
+			}
+		}))
+
+		if err := q.EnqueueJob(context.Background(), job{data: 1}); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		if !slices.Equal(processed, []int{1, 2, 3}) {
+			t.Fatalf("expected follow-up jobs processed, got %v", processed)
+		}
+	})
+
+	t.Run("closed queue rejects jobs", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		called := false
+		q := queue.NewSyncQueue(queue.HandlerFunc[job](func(context.Context, job) { called = true }))
+
+		q.Close(ctx)
+		if err := q.EnqueueJob(ctx, job{}); !errors.Is(err, queue.ErrClosedQueue) {
+			t.Fatalf("expected closed queue error, got: %v", err)
+		}
+
+		if called {
+			t.Fatal("expected handler not to be called")
+		}
+
+		q.Open(ctx)
+		if err := q.EnqueueJob(ctx, job{}); err != nil || !called {
+			t.Fatalf("expected reopened queue to process job, got error %v, called %v", err, called)
+		}
+	})
+}
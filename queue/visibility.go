@@ -0,0 +1,37 @@
+package queue
+
+import (
+	"context"
+	"time"
+
+	"github.com/platforma-dev/platforma/log"
+)
+
+// VisibilityTimeoutProvider is a DurableProvider with a visibility timeout. A job that was picked up by a worker
+// but not acknowledged within the timeout, e.g. because the handler hangs or panicked, becomes visible again:
+// Processor nacks it so that the provider redelivers it. A zero timeout disables redelivery.
+type VisibilityTimeoutProvider[T any] interface {
+	DurableProvider[T]
+	VisibilityTimeout() time.Duration
+}
+
+// lease starts the visibility timeout of a job picked up by a worker. The returned function stops the timeout
+// and reports whether the job can still be acknowledged, i.e. it was not redelivered yet.
+func (p *Processor[T]) lease(ctx context.Context, job T) func() bool {
+	provider, ok := p.queue.(VisibilityTimeoutProvider[T])
+	if !ok || provider.VisibilityTimeout() <= 0 {
+		return func() bool { return true }
+	}
+
+	nackCtx := context.WithoutCancel(ctx)
+	timer := time.AfterFunc(provider.VisibilityTimeout(), func() {
+		p.redelivered.Add(1)
+		log.WarnContext(nackCtx, "job visibility timeout expired, redelivering", "timeout", provider.VisibilityTimeout())
+
+		if err := provider.Nack(nackCtx, job); err != nil {
+			log.ErrorContext(nackCtx, "failed to redeliver job", "error", err)
+		}
+	})
+
+	return timer.Stop
+}
@@ -0,0 +1,107 @@
+package queue_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/platforma-dev/platforma/queue"
+)
+
+func TestProcessorVisibilityTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("job not acked within timeout is redelivered", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		q := newDurableMockQueue(20 * time.Millisecond)
+		unblock := make(chan struct{})
+		var calls atomic.Int32
+		p := queue.New(queue.HandlerFunc[job](func(_ context.Context, _ job) {
+			// the first delivery hangs past the visibility timeout
+			if calls.Add(1) == 1 {
+				<-unblock
+			}
+		}), q, 2, time.Second)
+
+		go p.Run(ctx)
+
+		if err := p.Enqueue(ctx, job{data: 1}); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		waitForCount(t, &q.acks, 1)
+		if calls.Load() != 2 || q.nacks.Load() != 1 {
+			t.Fatalf("expected job to be redelivered once, got %d calls and %d nacks", calls.Load(), q.nacks.Load())
+		}
+
+		// the hung delivery finishing late must not ack the job again
+		close(unblock)
+		time.Sleep(50 * time.Millisecond)
+		if q.acks.Load() != 1 {
+			t.Fatalf("expected 1 ack, got %d", q.acks.Load())
+		}
+
+		health, _ := p.Healthcheck(ctx).(queue.ProcessorHealth)
+		if health.Redelivered != 1 {
+			t.Fatalf("expected 1 redelivered job, got %d", health.Redelivered)
+		}
+	})
+
+	t.Run("job acked within timeout is not redelivered", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		q := newDurableMockQueue(20 * time.Millisecond)
+		p := queue.New(queue.HandlerFunc[job](func(_ context.Context, _ job) {}), q, 1, time.Second)
+
+		go p.Run(ctx)
+
+		if err := p.Enqueue(ctx, job{data: 1}); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		waitForCount(t, &q.acks, 1)
+		time.Sleep(50 * time.Millisecond)
+		if q.nacks.Load() != 0 {
+			t.Fatalf("expected no redelivery, got %d nacks", q.nacks.Load())
+		}
+	})
+}
+
+// durableMockQueue is a mockQueue with a visibility timeout that redelivers nacked jobs.
+type durableMockQueue struct {
+	mockQueue[job]
+
+	visibilityTimeout time.Duration
+	acks              atomic.Int32
+	nacks             atomic.Int32
+}
+
+func newDurableMockQueue(visibilityTimeout time.Duration) *durableMockQueue {
+	return &durableMockQueue{
+		mockQueue:         mockQueue[job]{jobChan: make(chan job, 10)},
+		visibilityTimeout: visibilityTimeout,
+	}
+}
+
+func (q *durableMockQueue) Ack(_ context.Context, _ job) error {
+	q.acks.Add(1)
+	return nil
+}
+
+func (q *durableMockQueue) Nack(_ context.Context, j job) error {
+	q.nacks.Add(1)
+	q.jobChan <- j
+	return nil
+}
+
+func (q *durableMockQueue) VisibilityTimeout() time.Duration {
+	return q.visibilityTimeout
+}
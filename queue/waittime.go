@@ -0,0 +1,85 @@
+package queue
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// EnqueueTime carries the time a job was passed to Processor.Enqueue. Embed it in a job type to have
+// the processor report how long jobs wait in the queue as AvgWait and MaxWait of ProcessorHealth:
+//
+//	type EmailJob struct {
+//		queue.EnqueueTime
+//		To string `json:"to"`
+//	}
+//
+// The time travels with the job, so it is kept by durable queues across restarts, and a job that is
+// redelivered is measured again from its original enqueue time. Jobs without it are not measured.
+type EnqueueTime struct {
+	EnqueuedAt time.Time `json:"enqueuedAt,omitzero"`
+}
+
+func (t *EnqueueTime) setEnqueuedAt(at time.Time) {
+	t.EnqueuedAt = at
+}
+
+func (t EnqueueTime) enqueuedAt() time.Time {
+	return t.EnqueuedAt
+}
+
+type enqueueTimeSetter interface {
+	setEnqueuedAt(at time.Time)
+}
+
+type enqueueTimeGetter interface {
+	enqueuedAt() time.Time
+}
+
+// stampEnqueueTime sets the enqueue time of a job that embeds EnqueueTime, by value or behind a pointer.
+func stampEnqueueTime[T any](job *T, at time.Time) {
+	if setter, ok := any(job).(enqueueTimeSetter); ok {
+		setter.setEnqueuedAt(at)
+		return
+	}
+
+	if setter, ok := any(*job).(enqueueTimeSetter); ok {
+		setter.setEnqueuedAt(at)
+	}
+}
+
+// waitTracker aggregates the time jobs waited in the queue between Enqueue and the moment
+// a worker picked them up, as read from their EnqueueTime.
+type waitTracker struct {
+	count atomic.Int64
+	total atomic.Int64
+	max   atomic.Int64
+}
+
+// dequeued records the wait time of a job picked up by a worker if it carries an enqueue time.
+func (w *waitTracker) dequeued(job any) {
+	getter, ok := job.(enqueueTimeGetter)
+	if !ok || getter.enqueuedAt().IsZero() {
+		return
+	}
+
+	wait := int64(time.Since(getter.enqueuedAt()))
+
+	w.count.Add(1)
+	w.total.Add(wait)
+	for {
+		current := w.max.Load()
+		if wait <= current || w.max.CompareAndSwap(current, wait) {
+			break
+		}
+	}
+}
+
+// stats returns the average and maximum wait time of measured jobs.
+func (w *waitTracker) stats() (time.Duration, time.Duration) {
+	count := w.count.Load()
+	if count == 0 {
+		return 0, 0
+	}
+
+	return time.Duration(w.total.Load() / count), time.Duration(w.max.Load())
+}
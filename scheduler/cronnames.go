@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidCronName is returned when a cron expression contains a month or weekday name
+// that doesn't exist, e.g. "FUN", or a name in a field that doesn't accept names.
+var ErrInvalidCronName = errors.New("invalid name in cron expression")
+
+// validateCronNames checks named tokens such as MON-FRI or JAN,JUL before the expression is parsed,
+// so that a misspelled name is reported clearly instead of as a failed integer conversion.
+// Names are case-insensitive and only allowed in the month and weekday fields.
+func validateCronNames(cronExpr string, withSeconds bool) error {
+	fields := strings.Fields(cronExpr)
+	if len(fields) > 0 && (strings.HasPrefix(fields[0], "TZ=") || strings.HasPrefix(fields[0], "CRON_TZ=")) {
+		fields = fields[1:]
+	}
+
+	if len(fields) == 0 || strings.HasPrefix(fields[0], "@") {
+		return nil
+	}
+
+	fieldNames := []string{"minute", "hour", "day of month", "month", "day of week"}
+	if withSeconds {
+		fieldNames = append([]string{"second"}, fieldNames...)
+	}
+
+	names := map[string][]string{
+		"month":       {"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"},
+		"day of week": {"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"},
+	}
+
+	// the parser reports a wrong number of fields
+	for i, field := range fields[:min(len(fields), len(fieldNames))] {
+		for _, token := range cronNameTokens(field) {
+			if !slices.Contains(names[fieldNames[i]], strings.ToUpper(token)) {
+				return fmt.Errorf("%w: %q in %s field", ErrInvalidCronName, token, fieldNames[i])
+			}
+		}
+	}
+
+	return nil
+}
+
+// cronNameTokens returns the tokens of a cron field that contain letters, ignoring steps.
+func cronNameTokens(field string) []string {
+	var tokens []string
+	for item := range strings.SplitSeq(field, ",") {
+		item, _, _ = strings.Cut(item, "/")
+		for token := range strings.SplitSeq(item, "-") {
+			if strings.ContainsFunc(token, func(r rune) bool { return (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') }) {
+				tokens = append(tokens, token)
+			}
+		}
+	}
+
+	return tokens
+}
@@ -0,0 +1,151 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/platforma-dev/platforma/log"
+	"github.com/robfig/cron/v3"
+)
+
+// Locker acquires a distributed lock before scheduled executions, so that only one
+// of several replicas running the same schedule executes the runner.
+type Locker interface {
+	// TryLock tries to acquire the lock named key without waiting. If the lock is acquired,
+	// it returns a function that releases it; otherwise acquired is false.
+	TryLock(ctx context.Context, key string) (unlock func(context.Context) error, acquired bool, err error)
+}
+
+// WithLocker makes the scheduler acquire a lock from locker before each scheduled execution.
+// Every tick has its own lock, named after key and the end of the tick's period, e.g. "report:1735732800",
+// and the lock is held until the end of that period or until the execution finishes, whichever is later.
+// A replica whose tick arrives after the holder finished therefore still finds the lock taken.
+// For cron expressions the period ends at the following tick, which is the same on every replica.
+// @every schedules tick relative to the start of each process, so their periods are aligned to multiples
+// of the interval since the Unix epoch instead, and each of them is executed by one replica.
+//
+// Replicas that don't get the lock skip the execution, which is counted as skipped in Health.
+// Trigger doesn't take the lock. Since the lock is held for the whole period, a Locker holding a resource
+// per lock, like PostgresLocker, holds it for as long as the interval between ticks.
+func WithLocker(locker Locker, key string) Option {
+	return func(s *Scheduler) {
+		s.locker = locker
+		s.lockKey = key
+	}
+}
+
+// lockPeriodEnd returns the end of the period of the tick at now, which names the lock of the tick.
+func lockPeriodEnd(schedule cron.Schedule, now time.Time) time.Time {
+	if every, ok := schedule.(cron.ConstantDelaySchedule); ok {
+		epoch := time.Unix(0, 0).UTC()
+		elapsed := now.Sub(epoch)
+
+		return epoch.Add(elapsed - elapsed%every.Delay + every.Delay)
+	}
+
+	return schedule.Next(now)
+}
+
+// executeLocked runs a scheduled execution if the lock of the period ending at next can be acquired.
+func (s *Scheduler) executeLocked(ctx context.Context, next time.Time) {
+	if s.locker == nil {
+		_ = s.execute(ctx)
+		return
+	}
+
+	lockKey := s.lockKey + ":" + strconv.FormatInt(next.Unix(), 10)
+
+	unlock, acquired, err := s.locker.TryLock(ctx, lockKey)
+	if err != nil {
+		s.skipped.Add(1)
+		log.ErrorContext(ctx, "failed to acquire scheduler lock", "lockKey", lockKey, "error", err)
+		return
+	}
+
+	if !acquired {
+		s.skipped.Add(1)
+		log.DebugContext(ctx, "scheduler task skipped, lock is held by another instance", "lockKey", lockKey)
+		return
+	}
+
+	defer func() {
+		waitUntil(ctx, next)
+
+		// release the lock even if ctx was canceled during the execution
+		if err := unlock(context.WithoutCancel(ctx)); err != nil {
+			log.ErrorContext(ctx, "failed to release scheduler lock", "lockKey", lockKey, "error", err)
+		}
+	}()
+
+	_ = s.execute(ctx)
+}
+
+// waitUntil waits until t or until ctx is canceled.
+func waitUntil(ctx context.Context, t time.Time) {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// PostgresLocker is a Locker based on PostgreSQL session-level advisory locks.
+// The lock is held on a dedicated connection until it is released, so it doesn't need renewal
+// and is released by the server if the holder crashes and its connection is closed.
+// With WithLocker the connection is taken from the pool for the whole interval between ticks,
+// e.g. for a day with @every 24h, so the pool needs a spare connection for every locked schedule.
+type PostgresLocker struct {
+	db connector
+}
+
+type connector interface {
+	Conn(ctx context.Context) (*sql.Conn, error)
+}
+
+// NewPostgresLocker creates a PostgresLocker using connections from db,
+// e.g. a *sql.DB or the *sqlx.DB returned by database.Database.Connection.
+func NewPostgresLocker(db connector) *PostgresLocker {
+	return &PostgresLocker{db: db}
+}
+
+// TryLock tries to acquire the advisory lock for key with pg_try_advisory_lock.
+func (l *PostgresLocker) TryLock(ctx context.Context, key string) (func(context.Context) error, bool, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", key).Scan(&acquired); err != nil {
+		return nil, false, errors.Join(fmt.Errorf("failed to acquire advisory lock: %w", err), conn.Close())
+	}
+
+	if !acquired {
+		if err := conn.Close(); err != nil {
+			return nil, false, fmt.Errorf("failed to close connection: %w", err)
+		}
+
+		return nil, false, nil
+	}
+
+	unlock := func(ctx context.Context) error {
+		_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtext($1))", key)
+		if err != nil {
+			err = fmt.Errorf("failed to release advisory lock: %w", err)
+		}
+
+		if closeErr := conn.Close(); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to close connection: %w", closeErr))
+		}
+
+		return err
+	}
+
+	return unlock, true, nil
+}
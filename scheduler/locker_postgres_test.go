@@ -0,0 +1,77 @@
+//go:build linux
+
+package scheduler_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/platforma-dev/platforma/database"
+	"github.com/platforma-dev/platforma/scheduler"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+)
+
+func TestPostgresLocker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ctr, err := postgres.Run(
+		ctx,
+		"postgres:18-alpine",
+		postgres.WithDatabase("platforma"),
+		postgres.WithUsername("platforma"),
+		postgres.WithPassword("platforma"),
+		postgres.BasicWaitStrategies(),
+	)
+	if err != nil {
+		t.Fatalf("failed to initialize database: %s", err.Error())
+	}
+
+	t.Cleanup(func() {
+		_ = ctr.Terminate(ctx)
+	})
+
+	dbURL, err := ctr.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		t.Fatalf("failed to get connection string: %s", err.Error())
+	}
+
+	db, err := database.New(dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to database: %s", err.Error())
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	// two lockers on one pool behave like two replicas, since advisory locks are held per connection
+	leader := scheduler.NewPostgresLocker(db.Connection())
+	follower := scheduler.NewPostgresLocker(db.Connection())
+
+	unlock, acquired, err := leader.TryLock(ctx, "report")
+	if err != nil || !acquired {
+		t.Fatalf("expected leader to acquire lock, got acquired=%v err=%v", acquired, err)
+	}
+
+	if _, acquired, err := follower.TryLock(ctx, "report"); err != nil || acquired {
+		t.Fatalf("expected follower not to acquire held lock, got acquired=%v err=%v", acquired, err)
+	}
+
+	otherUnlock, acquired, err := follower.TryLock(ctx, "cleanup")
+	if err != nil || !acquired {
+		t.Fatalf("expected follower to acquire lock with another key, got acquired=%v err=%v", acquired, err)
+	}
+	if err := otherUnlock(ctx); err != nil {
+		t.Fatalf("failed to release lock: %v", err)
+	}
+
+	if err := unlock(ctx); err != nil {
+		t.Fatalf("failed to release lock: %v", err)
+	}
+
+	followerUnlock, acquired, err := follower.TryLock(ctx, "report")
+	if err != nil || !acquired {
+		t.Fatalf("expected follower to acquire released lock, got acquired=%v err=%v", acquired, err)
+	}
+	if err := followerUnlock(ctx); err != nil {
+		t.Fatalf("failed to release lock: %v", err)
+	}
+}
@@ -0,0 +1,246 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/platforma-dev/platforma/application"
+	"github.com/platforma-dev/platforma/scheduler"
+)
+
+func TestWithLocker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("only the lock holder executes", func(t *testing.T) {
+		t.Parallel()
+
+		locker := &memoryLocker{}
+
+		var leaderRuns, followerRuns atomic.Int64
+		leader, err := scheduler.New("* * * * * *", application.RunnerFunc(func(_ context.Context) error {
+			leaderRuns.Add(1)
+			return nil
+		}), scheduler.WithSeconds(), scheduler.WithLocker(locker.instance(0), "report"))
+		if err != nil {
+			t.Fatalf("failed to create scheduler: %v", err)
+		}
+
+		// the follower's ticks arrive later, after the leader's execution has already finished
+		follower, err := scheduler.New("* * * * * *", application.RunnerFunc(func(_ context.Context) error {
+			followerRuns.Add(1)
+			return nil
+		}), scheduler.WithSeconds(), scheduler.WithLocker(locker.instance(300*time.Millisecond), "report"))
+		if err != nil {
+			t.Fatalf("failed to create scheduler: %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() { _ = leader.Run(ctx) }()
+		go func() { _ = follower.Run(ctx) }()
+
+		waitFor(t, func() bool {
+			health, _ := follower.Healthcheck(ctx).(scheduler.Health)
+			return leaderRuns.Load() >= 2 && health.Skipped >= 2 && locker.unlocks.Load() >= 2
+		})
+
+		if followerRuns.Load() != 0 {
+			t.Fatalf("expected follower not to execute ticks of the leader, got %d runs", followerRuns.Load())
+		}
+	})
+
+	t.Run("lock is held until the next tick", func(t *testing.T) {
+		t.Parallel()
+
+		locker := &memoryLocker{}
+
+		var runs atomic.Int64
+		s, err := scheduler.New("* * * * * *", application.RunnerFunc(func(_ context.Context) error {
+			runs.Add(1)
+			return nil
+		}), scheduler.WithSeconds(), scheduler.WithLocker(locker.instance(0), "report"))
+		if err != nil {
+			t.Fatalf("failed to create scheduler: %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() { _ = s.Run(ctx) }()
+
+		waitFor(t, func() bool { return runs.Load() >= 1 })
+
+		// the execution has finished, but another replica must not get the lock of this tick
+		time.Sleep(100 * time.Millisecond)
+		keys := locker.heldKeys()
+		if len(keys) != 1 || !strings.HasPrefix(keys[0], "report:") {
+			t.Fatalf("expected the lock of the tick to be held after the execution, got %v", keys)
+		}
+
+		cancel()
+		waitFor(t, func() bool { return len(locker.heldKeys()) == 0 })
+	})
+
+	t.Run("@every schedules started at different times share locks", func(t *testing.T) {
+		t.Parallel()
+
+		locker := &memoryLocker{}
+
+		var runs atomic.Int64
+		newReplica := func() *scheduler.Scheduler {
+			s, err := scheduler.New("@every 2s", application.RunnerFunc(func(_ context.Context) error {
+				runs.Add(1)
+				return nil
+			}), scheduler.WithLocker(locker.instance(0), "report"))
+			if err != nil {
+				t.Fatalf("failed to create scheduler: %v", err)
+			}
+
+			return s
+		}
+		first, second := newReplica(), newReplica()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// the replicas tick a second apart, as their intervals start when they are started
+		go func() { _ = first.Run(ctx) }()
+		time.Sleep(time.Second)
+		go func() { _ = second.Run(ctx) }()
+
+		waitFor(t, func() bool {
+			firstHealth, _ := first.Healthcheck(ctx).(scheduler.Health)
+			secondHealth, _ := second.Healthcheck(ctx).(scheduler.Health)
+			return firstHealth.Skipped+secondHealth.Skipped >= 1
+		})
+
+		keys := locker.acquiredKeys()
+		if int64(len(keys)) != runs.Load() {
+			t.Fatalf("expected one execution per lock, got %d executions for locks %v", runs.Load(), keys)
+		}
+		if len(slices.Compact(slices.Sorted(slices.Values(keys)))) != len(keys) {
+			t.Fatalf("expected every lock to be acquired once, got %v", keys)
+		}
+	})
+
+	t.Run("skips execution if lock fails", func(t *testing.T) {
+		t.Parallel()
+
+		var runs atomic.Int64
+		s, err := scheduler.New("* * * * * *", application.RunnerFunc(func(_ context.Context) error {
+			runs.Add(1)
+			return nil
+		}), scheduler.WithSeconds(), scheduler.WithLocker(lockerFunc(func(_ context.Context, _ string) (func(context.Context) error, bool, error) {
+			return nil, false, errors.New("database unavailable")
+		}), "report"))
+		if err != nil {
+			t.Fatalf("failed to create scheduler: %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() { _ = s.Run(ctx) }()
+
+		waitFor(t, func() bool {
+			health, _ := s.Healthcheck(ctx).(scheduler.Health)
+			return health.Skipped >= 1
+		})
+
+		if runs.Load() != 0 {
+			t.Fatalf("expected no executions, got %d", runs.Load())
+		}
+	})
+
+	t.Run("trigger doesn't take the lock", func(t *testing.T) {
+		t.Parallel()
+
+		var runs atomic.Int64
+		s, err := scheduler.New("@hourly", application.RunnerFunc(func(_ context.Context) error {
+			runs.Add(1)
+			return nil
+		}), scheduler.WithLocker(lockerFunc(func(_ context.Context, _ string) (func(context.Context) error, bool, error) {
+			return nil, false, nil
+		}), "report"))
+		if err != nil {
+			t.Fatalf("failed to create scheduler: %v", err)
+		}
+
+		if err := s.Trigger(context.Background()); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		if runs.Load() != 1 {
+			t.Fatalf("expected 1 execution, got %d", runs.Load())
+		}
+	})
+}
+
+type lockerFunc func(ctx context.Context, key string) (func(context.Context) error, bool, error)
+
+func (f lockerFunc) TryLock(ctx context.Context, key string) (func(context.Context) error, bool, error) {
+	return f(ctx, key)
+}
+
+// memoryLocker is an in-memory lock shared by simulated scheduler replicas.
+type memoryLocker struct {
+	mu       sync.Mutex
+	held     map[string]bool
+	acquired []string
+	unlocks  atomic.Int64
+}
+
+// instance returns the Locker used by one replica, which tries to lock after delay,
+// like a replica whose ticks arrive later than those of the others.
+func (l *memoryLocker) instance(delay time.Duration) scheduler.Locker {
+	return lockerFunc(func(_ context.Context, key string) (func(context.Context) error, bool, error) {
+		time.Sleep(delay)
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if l.held[key] {
+			return nil, false, nil
+		}
+
+		if l.held == nil {
+			l.held = map[string]bool{}
+		}
+		l.held[key] = true
+		l.acquired = append(l.acquired, key)
+
+		unlock := func(_ context.Context) error {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+
+			delete(l.held, key)
+			l.unlocks.Add(1)
+
+			return nil
+		}
+
+		return unlock, true, nil
+	})
+}
+
+func (l *memoryLocker) heldKeys() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return slices.Collect(maps.Keys(l.held))
+}
+
+func (l *memoryLocker) acquiredKeys() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return slices.Clone(l.acquired)
+}
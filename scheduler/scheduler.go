@@ -5,6 +5,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/platforma-dev/platforma/application"
@@ -16,6 +18,10 @@ import (
 
 var errEmptyCronExpression = errors.New("cron expression cannot be empty")
 
+// ErrRunnerPanicked is returned when the runner panics during an execution.
+// The panic is recovered and counted as a failure, and the schedule continues.
+var ErrRunnerPanicked = errors.New("scheduler runner panicked")
+
 const cronParseOptions = cron.Minute |
 	cron.Hour |
 	cron.Dom |
@@ -23,10 +29,52 @@ const cronParseOptions = cron.Minute |
 	cron.Dow |
 	cron.Descriptor
 
+// Option configures a Scheduler.
+type Option func(*Scheduler)
+
+// WithSeconds enables 6-field cron expressions with a leading seconds field:
+// "second minute hour day month weekday" (e.g., "*/30 * * * * *" runs every 30 seconds).
+// 5-field expressions are rejected when this option is set.
+func WithSeconds() Option {
+	return func(s *Scheduler) {
+		s.parseOptions |= cron.Second
+	}
+}
+
+// WithStopTimeout limits how long Run waits for executions in progress after its context is canceled.
+// When the timeout expires, Run returns and the still running executions are abandoned and logged.
+// Zero, the default, waits until they finish.
+func WithStopTimeout(timeout time.Duration) Option {
+	return func(s *Scheduler) {
+		s.stopTimeout = timeout
+	}
+}
+
+// Health contains run counters of a Scheduler.
+type Health struct {
+	// Runs is the number of executions, both scheduled and triggered.
+	Runs int64 `json:"runs"`
+	// Failures is the number of executions that returned an error or panicked.
+	Failures int64 `json:"failures"`
+	// Skipped is the number of scheduled executions skipped because the lock set
+	// with WithLocker was held by another instance or couldn't be acquired.
+	Skipped int64 `json:"skipped"`
+}
+
 // Scheduler represents a periodic task runner that executes an action based on a cron expression.
 type Scheduler struct {
-	cronExpr string             // The cron expression
-	runner   application.Runner // The runner to execute periodically
+	cronExpr     string             // The cron expression
+	parseOptions cron.ParseOption   // Fields accepted in the cron expression
+	mu           sync.RWMutex       // Guards runner
+	runner       application.Runner // The runner to execute periodically
+	locker       Locker             // Optional distributed lock for scheduled executions
+	lockKey      string             // Name of the lock
+	stopTimeout  time.Duration      // Maximum wait for executions in progress on shutdown
+
+	runs     atomic.Int64
+	failures atomic.Int64
+	skipped  atomic.Int64
+	running  atomic.Int64
 }
 
 // New creates a new Scheduler instance with a cron expression.
@@ -45,59 +93,149 @@ type Scheduler struct {
 //   - "@every 30m" - Every 30 minutes
 //   - "@every 1s" - Every second (for intervals, use @every syntax)
 //
+// With WithSeconds, expressions have a leading seconds field instead (e.g., "*/30 * * * * *").
+//
+// Month (JAN-DEC) and weekday (SUN-SAT) names are case-insensitive. Unknown names, or names in
+// other fields, are rejected with ErrInvalidCronName.
+//
 // Returns an error if the cron expression is invalid.
-func New(cronExpr string, runner application.Runner) (*Scheduler, error) {
+func New(cronExpr string, runner application.Runner, opts ...Option) (*Scheduler, error) {
 	// Check for empty expression first to avoid parser errors
 	if cronExpr == "" {
 		return nil, fmt.Errorf("invalid cron expression %q: %w", cronExpr, errEmptyCronExpression)
 	}
 
-	parser := cron.NewParser(cronParseOptions)
+	s := &Scheduler{
+		cronExpr:     cronExpr,
+		parseOptions: cronParseOptions,
+		runner:       runner,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if err := validateCronNames(cronExpr, s.parseOptions&cron.Second != 0); err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %w", cronExpr, err)
+	}
 
 	// Validate expression eagerly so errors are returned from constructor
-	if _, err := parser.Parse(cronExpr); err != nil {
+	if _, err := cron.NewParser(s.parseOptions).Parse(cronExpr); err != nil {
 		return nil, fmt.Errorf("invalid cron expression %q: %w", cronExpr, err)
 	}
 
-	return &Scheduler{
-		cronExpr: cronExpr,
-		runner:   runner,
-	}, nil
+	return s, nil
+}
+
+// SetRunner replaces the runner executed by the scheduler.
+// The schedule is not affected; the next execution uses the new runner
+// while an execution that is already in progress finishes with the old one.
+func (s *Scheduler) SetRunner(runner application.Runner) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.runner = runner
+}
+
+func (s *Scheduler) currentRunner() application.Runner {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.runner
 }
 
 // Run starts the scheduler and executes the runner according to the cron schedule.
-// The scheduler will continue running until the context is canceled.
+// The scheduler will continue running until the context is canceled, then waits for
+// scheduled executions in progress, at most for the timeout set with WithStopTimeout.
 func (s *Scheduler) Run(ctx context.Context) error {
-	parser := cron.NewParser(cronParseOptions)
+	parser := cron.NewParser(s.parseOptions)
 
 	cronScheduler := cron.New(
 		cron.WithLocation(time.UTC),
 		cron.WithParser(parser),
 	)
 
-	// Wrap runner to maintain consistent logging with trace IDs
-	_, err := cronScheduler.AddFunc(s.cronExpr, func() {
-		runCtx := context.WithValue(ctx, log.TraceIDKey, uuid.NewString())
-		log.InfoContext(runCtx, "scheduler task started")
-
-		err := s.runner.Run(runCtx)
-		if err != nil {
-			log.ErrorContext(runCtx, "error in scheduler", "error", err)
-			return
-		}
-
-		log.InfoContext(runCtx, "scheduler task finished")
-	})
+	schedule, err := parser.Parse(s.cronExpr)
 	if err != nil {
 		return fmt.Errorf("failed to add cron task: %w", err)
 	}
 
+	cronScheduler.Schedule(schedule, cron.FuncJob(func() {
+		s.executeLocked(ctx, lockPeriodEnd(schedule, time.Now().UTC()))
+	}))
+
 	cronScheduler.Start()
 
 	<-ctx.Done()
 
-	stopCtx := cronScheduler.Stop()
-	<-stopCtx.Done()
+	s.waitForExecutions(ctx, cronScheduler.Stop())
 
 	return fmt.Errorf("scheduler context canceled: %w", ctx.Err())
 }
+
+// waitForExecutions waits until stopCtx from cron.Stop is done or the stop timeout expires.
+func (s *Scheduler) waitForExecutions(ctx, stopCtx context.Context) {
+	if s.stopTimeout <= 0 {
+		<-stopCtx.Done()
+		return
+	}
+
+	timer := time.NewTimer(s.stopTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-stopCtx.Done():
+	case <-timer.C:
+		log.WarnContext(ctx, "abandoning scheduler task still running after stop timeout",
+			"cron", s.cronExpr, "running", s.running.Load(), "stopTimeout", s.stopTimeout)
+	}
+}
+
+// Trigger executes the runner once immediately, independently of the schedule, and returns its error.
+// Like scheduled executions, a triggered execution may overlap with one that is already in progress.
+func (s *Scheduler) Trigger(ctx context.Context) error {
+	log.InfoContext(ctx, "scheduler task triggered")
+
+	return s.execute(ctx)
+}
+
+// Healthcheck returns Health with the number of runs, failures and skipped executions.
+func (s *Scheduler) Healthcheck(_ context.Context) any {
+	return Health{
+		Runs:     s.runs.Load(),
+		Failures: s.failures.Load(),
+		Skipped:  s.skipped.Load(),
+	}
+}
+
+// execute runs the current runner with a new trace ID and updates the run counters.
+func (s *Scheduler) execute(ctx context.Context) error {
+	// Wrap runner to maintain consistent logging with trace IDs
+	runCtx := context.WithValue(ctx, log.TraceIDKey, uuid.NewString())
+	log.InfoContext(runCtx, "scheduler task started")
+
+	s.runs.Add(1)
+	s.running.Add(1)
+	err := runRecovered(runCtx, s.currentRunner())
+	s.running.Add(-1)
+	if err != nil {
+		s.failures.Add(1)
+		log.ErrorContext(runCtx, "error in scheduler", "error", err)
+		return fmt.Errorf("scheduler task failed: %w", err)
+	}
+
+	log.InfoContext(runCtx, "scheduler task finished")
+
+	return nil
+}
+
+// runRecovered runs runner and converts a panic into an error wrapping ErrRunnerPanicked.
+func runRecovered(ctx context.Context, runner application.Runner) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.ErrorContext(ctx, "scheduler task panicked", "panic", r)
+			err = fmt.Errorf("%w: %v", ErrRunnerPanicked, r)
+		}
+	}()
+
+	return runner.Run(ctx)
+}
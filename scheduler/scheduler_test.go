@@ -99,6 +99,11 @@ func TestNew_ValidExpression(t *testing.T) {
 		{"every 2 hours interval", "@every 2h"},
 		{"weekday mornings", "0 9 * * 1-5"},
 		{"specific time", "30 14 * * *"},
+		{"named weekday range", "0 9 * * MON-FRI"},
+		{"lowercase weekday names", "0 9 * * mon,wed,fri"},
+		{"named month range with step", "0 0 1 JAN-DEC/3 *"},
+		{"named month and weekday", "0 9 * JAN,JUL SUN"},
+		{"time zone prefix", "CRON_TZ=Europe/Berlin 0 9 * * MON"},
 	}
 
 	for _, tc := range testCases {
@@ -154,6 +159,71 @@ func TestNew_InvalidExpression(t *testing.T) {
 	}
 }
 
+func TestNew_InvalidCronName(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		expr string
+	}{
+		{"unknown weekday", "0 9 * * FUN"},
+		{"unknown weekday in range", "0 9 * * MON-FUN"},
+		{"unknown month", "0 9 * FOO *"},
+		{"month name in weekday field", "0 9 * * JAN"},
+		{"weekday name in month field", "0 9 * MON *"},
+		{"name in minute field", "MON 9 * * *"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := scheduler.New(tc.expr, application.RunnerFunc(func(_ context.Context) error {
+				return nil
+			}))
+
+			if !errors.Is(err, scheduler.ErrInvalidCronName) {
+				t.Errorf("expected invalid name error for %q, got: %v", tc.expr, err)
+			}
+		})
+	}
+}
+
+func TestNew_WithSeconds(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		expr    string
+		opts    []scheduler.Option
+		wantErr bool
+	}{
+		{"6-field with seconds option", "*/30 * * * * *", []scheduler.Option{scheduler.WithSeconds()}, false},
+		{"descriptor with seconds option", "@every 10s", []scheduler.Option{scheduler.WithSeconds()}, false},
+		{"6-field without seconds option", "*/30 * * * * *", nil, true},
+		{"5-field with seconds option", "*/5 * * * *", []scheduler.Option{scheduler.WithSeconds()}, true},
+		{"invalid seconds", "60 * * * * *", []scheduler.Option{scheduler.WithSeconds()}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := scheduler.New(tc.expr, application.RunnerFunc(func(_ context.Context) error {
+				return nil
+			}), tc.opts...)
+
+			if tc.wantErr && err == nil {
+				t.Errorf("expected error for expression %q, got nil", tc.expr)
+			}
+
+			if !tc.wantErr && err != nil {
+				t.Errorf("expected no error for expression %q, got: %v", tc.expr, err)
+			}
+		})
+	}
+}
+
 func TestCronScheduling_ExecutionTiming(t *testing.T) {
 	t.Parallel()
 
@@ -260,3 +330,208 @@ func TestScheduling_HourlyDescriptor(t *testing.T) {
 		t.Error("@hourly task should not execute within 100ms")
 	}
 }
+
+func TestSetRunner(t *testing.T) {
+	t.Parallel()
+
+	var oldCalls, newCalls atomic.Int32
+
+	s, err := scheduler.New("@every 1s", application.RunnerFunc(func(_ context.Context) error {
+		oldCalls.Add(1)
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("failed to create scheduler: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	waitFor(t, func() bool { return oldCalls.Load() > 0 })
+
+	s.SetRunner(application.RunnerFunc(func(_ context.Context) error {
+		newCalls.Add(1)
+		return nil
+	}))
+	oldCallsAtSwap := oldCalls.Load()
+
+	waitFor(t, func() bool { return newCalls.Load() > 0 })
+
+	cancel()
+	<-done
+
+	if oldCalls.Load() != oldCallsAtSwap {
+		t.Fatalf("expected old runner not to be called after swap, got %d calls (was %d)", oldCalls.Load(), oldCallsAtSwap)
+	}
+}
+
+func TestTrigger(t *testing.T) {
+	t.Parallel()
+
+	t.Run("runs the runner once", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int32
+		s, err := scheduler.New("@hourly", application.RunnerFunc(func(_ context.Context) error {
+			calls.Add(1)
+			return nil
+		}))
+		if err != nil {
+			t.Fatalf("failed to create scheduler: %v", err)
+		}
+
+		err = s.Trigger(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		if calls.Load() != 1 {
+			t.Fatalf("expected 1 call, got %d", calls.Load())
+		}
+
+		health, ok := s.Healthcheck(context.Background()).(scheduler.Health)
+		if !ok {
+			t.Fatalf("expected scheduler.Health, got %T", s.Healthcheck(context.Background()))
+		}
+
+		if health.Runs != 1 || health.Failures != 0 {
+			t.Fatalf("expected 1 run and 0 failures, got %+v", health)
+		}
+	})
+
+	t.Run("returns runner error", func(t *testing.T) {
+		t.Parallel()
+
+		runErr := errors.New("some error")
+		s, err := scheduler.New("@hourly", application.RunnerFunc(func(_ context.Context) error {
+			return runErr
+		}))
+		if err != nil {
+			t.Fatalf("failed to create scheduler: %v", err)
+		}
+
+		err = s.Trigger(context.Background())
+		if !errors.Is(err, runErr) {
+			t.Fatalf("expected runner error, got: %v", err)
+		}
+
+		health, _ := s.Healthcheck(context.Background()).(scheduler.Health)
+		if health.Runs != 1 || health.Failures != 1 {
+			t.Fatalf("expected 1 run and 1 failure, got %+v", health)
+		}
+	})
+}
+
+func TestRunnerPanic(t *testing.T) {
+	t.Parallel()
+
+	t.Run("trigger returns panic as error", func(t *testing.T) {
+		t.Parallel()
+
+		s, err := scheduler.New("@hourly", application.RunnerFunc(func(_ context.Context) error {
+			panic("boom")
+		}))
+		if err != nil {
+			t.Fatalf("failed to create scheduler: %v", err)
+		}
+
+		err = s.Trigger(context.Background())
+		if !errors.Is(err, scheduler.ErrRunnerPanicked) {
+			t.Fatalf("expected runner panicked error, got: %v", err)
+		}
+
+		health, _ := s.Healthcheck(context.Background()).(scheduler.Health)
+		if health.Runs != 1 || health.Failures != 1 {
+			t.Fatalf("expected 1 run and 1 failure, got %+v", health)
+		}
+	})
+
+	t.Run("schedule continues after panic", func(t *testing.T) {
+		t.Parallel()
+
+		s, err := scheduler.New("* * * * * *", application.RunnerFunc(func(_ context.Context) error {
+			panic("boom")
+		}), scheduler.WithSeconds())
+		if err != nil {
+			t.Fatalf("failed to create scheduler: %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		runErr := make(chan error, 1)
+		go func() { runErr <- s.Run(ctx) }()
+
+		waitFor(t, func() bool {
+			health, _ := s.Healthcheck(ctx).(scheduler.Health)
+			return health.Failures >= 2
+		})
+
+		select {
+		case err := <-runErr:
+			t.Fatalf("expected scheduler to keep running, got: %v", err)
+		default:
+		}
+
+		cancel()
+		if err := <-runErr; !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context canceled error, got: %v", err)
+		}
+	})
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStopTimeout(t *testing.T) {
+	t.Parallel()
+
+	var started atomic.Bool
+	s, err := scheduler.New("* * * * * *", application.RunnerFunc(func(_ context.Context) error {
+		started.Store(true)
+		time.Sleep(5 * time.Second)
+		return nil
+	}), scheduler.WithSeconds(), scheduler.WithStopTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("failed to create scheduler: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- s.Run(ctx) }()
+
+	waitFor(t, started.Load)
+
+	cancel()
+	stopped := time.Now()
+
+	select {
+	case err := <-runErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context canceled error, got: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected Run to return after the stop timeout")
+	}
+
+	if elapsed := time.Since(stopped); elapsed < 100*time.Millisecond {
+		t.Fatalf("expected Run to wait for the stop timeout, returned after %s", elapsed)
+	}
+}
@@ -0,0 +1,117 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const DefaultCookieName = "session"
+
+// CookieConfig configures the session cookie set by Service.
+// Cookies are always HttpOnly and Secure unless Insecure is set, e.g. for local development over HTTP.
+type CookieConfig struct {
+	Name     string        // Cookie name, DefaultCookieName if empty
+	Domain   string        // Cookie domain, host-only if empty
+	Path     string        // Cookie path, "/" if empty
+	SameSite http.SameSite // SameSite mode, http.SameSiteLaxMode if zero
+	Insecure bool          // Allow sending the cookie over plain HTTP
+}
+
+func (c CookieConfig) withDefaults() CookieConfig {
+	if c.Name == "" {
+		c.Name = DefaultCookieName
+	}
+	if c.Path == "" {
+		c.Path = "/"
+	}
+	if c.SameSite == 0 {
+		c.SameSite = http.SameSiteLaxMode
+	}
+	return c
+}
+
+// SetCookieConfig replaces the configuration of session cookies.
+func (s *Service) SetCookieConfig(config CookieConfig) {
+	s.cookie = config.withDefaults()
+}
+
+// CookieName returns the name of the session cookie.
+func (s *Service) CookieName() string {
+	return s.cookie.Name
+}
+
+// SetCookie writes the session cookie with the session ID to w.
+func (s *Service) SetCookie(w http.ResponseWriter, session *Session) {
+	http.SetCookie(w, s.newCookie(session.ID, session.Expires))
+}
+
+// ClearCookie writes an expired session cookie to w so that the browser removes it.
+func (s *Service) ClearCookie(w http.ResponseWriter) {
+	cookie := s.newCookie("", time.Unix(0, 0))
+	cookie.MaxAge = -1
+	http.SetCookie(w, cookie)
+}
+
+// StartSession creates a session for the user and sets its cookie.
+func (s *Service) StartSession(ctx context.Context, w http.ResponseWriter, userId string) (*Session, error) {
+	session := newSession(userId)
+
+	if err := s.repo.Create(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	s.SetCookie(w, session)
+	return session, nil
+}
+
+// SessionFromRequest resolves the session of the request cookie.
+// It returns ErrNoSessionCookie if the request has no session cookie and ErrSessionExpired if the session expired.
+func (s *Service) SessionFromRequest(ctx context.Context, r *http.Request) (*Session, error) {
+	cookie, err := r.Cookie(s.cookie.Name)
+	if err != nil {
+		return nil, ErrNoSessionCookie
+	}
+
+	session, err := s.repo.Get(ctx, cookie.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.IsExpired() {
+		return nil, ErrSessionExpired
+	}
+
+	return session, nil
+}
+
+// EndSession deletes the session of the request cookie, if any, and clears the cookie.
+func (s *Service) EndSession(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	cookie, err := r.Cookie(s.cookie.Name)
+	if errors.Is(err, http.ErrNoCookie) {
+		s.ClearCookie(w)
+		return nil
+	}
+
+	if err := s.repo.Delete(ctx, cookie.Value); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	s.ClearCookie(w)
+	return nil
+}
+
+func (s *Service) newCookie(value string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     s.cookie.Name,
+		Value:    value,
+		Domain:   s.cookie.Domain,
+		Path:     s.cookie.Path,
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   !s.cookie.Insecure,
+		SameSite: s.cookie.SameSite,
+	}
+}
@@ -0,0 +1,191 @@
+package session_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/platforma-dev/platforma/session"
+)
+
+func TestServiceCookies(t *testing.T) {
+	t.Parallel()
+
+	t.Run("start session sets secure cookie", func(t *testing.T) {
+		t.Parallel()
+
+		service := session.NewService(session.NewRepository(newMemoryDB()))
+		service.SetCookieConfig(session.CookieConfig{Name: "sid", Domain: "example.com", SameSite: http.SameSiteStrictMode})
+
+		w := httptest.NewRecorder()
+		created, err := service.StartSession(context.Background(), w, "user-1")
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		cookies := w.Result().Cookies()
+		if len(cookies) != 1 {
+			t.Fatalf("expected 1 cookie, got %d", len(cookies))
+		}
+
+		cookie := cookies[0]
+		if cookie.Name != "sid" || cookie.Value != created.ID {
+			t.Errorf("expected sid=%s, got %s=%s", created.ID, cookie.Name, cookie.Value)
+		}
+
+		if !cookie.HttpOnly || !cookie.Secure {
+			t.Errorf("expected HttpOnly and Secure cookie, got %+v", cookie)
+		}
+
+		if cookie.SameSite != http.SameSiteStrictMode || cookie.Domain != "example.com" || cookie.Path != "/" {
+			t.Errorf("expected configured SameSite, domain and default path, got %+v", cookie)
+		}
+	})
+
+	t.Run("insecure cookie for local development", func(t *testing.T) {
+		t.Parallel()
+
+		service := session.NewService(session.NewRepository(newMemoryDB()))
+		service.SetCookieConfig(session.CookieConfig{Insecure: true})
+
+		w := httptest.NewRecorder()
+		service.SetCookie(w, &session.Session{ID: "id", Expires: time.Now().Add(time.Hour)})
+
+		cookie := w.Result().Cookies()[0]
+		if cookie.Secure || !cookie.HttpOnly || cookie.Name != session.DefaultCookieName || cookie.SameSite != http.SameSiteLaxMode {
+			t.Errorf("expected insecure HttpOnly cookie with defaults, got %+v", cookie)
+		}
+	})
+
+	t.Run("session from request resolves cookie", func(t *testing.T) {
+		t.Parallel()
+
+		service := session.NewService(session.NewRepository(newMemoryDB()))
+
+		w := httptest.NewRecorder()
+		created, err := service.StartSession(context.Background(), w, "user-1")
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.AddCookie(w.Result().Cookies()[0])
+
+		resolved, err := service.SessionFromRequest(context.Background(), r)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		if resolved.ID != created.ID || resolved.User != "user-1" {
+			t.Errorf("expected session %s of user-1, got %+v", created.ID, resolved)
+		}
+	})
+
+	t.Run("session from request without cookie", func(t *testing.T) {
+		t.Parallel()
+
+		service := session.NewService(session.NewRepository(newMemoryDB()))
+
+		_, err := service.SessionFromRequest(context.Background(), httptest.NewRequest(http.MethodGet, "/", nil))
+		if !errors.Is(err, session.ErrNoSessionCookie) {
+			t.Fatalf("expected no session cookie error, got: %v", err)
+		}
+	})
+
+	t.Run("expired session", func(t *testing.T) {
+		t.Parallel()
+
+		repo := session.NewRepository(newMemoryDB())
+		service := session.NewService(repo)
+		repo.Create(context.Background(), &session.Session{ID: "old", User: "user-1", Expires: time.Now().Add(-time.Hour)})
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.AddCookie(&http.Cookie{Name: service.CookieName(), Value: "old"})
+
+		_, err := service.SessionFromRequest(context.Background(), r)
+		if !errors.Is(err, session.ErrSessionExpired) {
+			t.Fatalf("expected session expired error, got: %v", err)
+		}
+	})
+
+	t.Run("end session deletes session and clears cookie", func(t *testing.T) {
+		t.Parallel()
+
+		service := session.NewService(session.NewRepository(newMemoryDB()))
+
+		created, err := service.StartSession(context.Background(), httptest.NewRecorder(), "user-1")
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		r := httptest.NewRequest(http.MethodPost, "/logout", nil)
+		r.AddCookie(&http.Cookie{Name: service.CookieName(), Value: created.ID})
+		w := httptest.NewRecorder()
+
+		if err := service.EndSession(context.Background(), w, r); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		cookie := w.Result().Cookies()[0]
+		if cookie.Value != "" || cookie.MaxAge >= 0 {
+			t.Errorf("expected cleared cookie, got %+v", cookie)
+		}
+
+		if _, err := service.Get(context.Background(), created.ID); err == nil {
+			t.Error("expected session to be deleted")
+		}
+	})
+}
+
+// memoryDB stores sessions in memory, implementing the queries used by session.Repository.
+type memoryDB struct {
+	mu       sync.Mutex
+	sessions map[string]session.Session
+}
+
+func newMemoryDB() *memoryDB {
+	return &memoryDB{sessions: map[string]session.Session{}}
+}
+
+func (db *memoryDB) NamedExecContext(_ context.Context, _ string, arg any) (sql.Result, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	s, ok := arg.(*session.Session)
+	if !ok {
+		return nil, errors.New("unexpected argument")
+	}
+	db.sessions[s.ID] = *s
+
+	return nil, nil
+}
+
+func (db *memoryDB) GetContext(_ context.Context, dest any, _ string, args ...any) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	s, ok := db.sessions[args[0].(string)]
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	*dest.(*session.Session) = s
+	return nil
+}
+
+func (db *memoryDB) SelectContext(_ context.Context, _ any, _ string, _ ...any) error {
+	return errors.New("not implemented")
+}
+
+func (db *memoryDB) ExecContext(_ context.Context, _ string, args ...any) (sql.Result, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	delete(db.sessions, args[0].(string))
+	return nil, nil
+}
@@ -0,0 +1,8 @@
+package session
+
+import "errors"
+
+var (
+	ErrNoSessionCookie = errors.New("no session cookie")
+	ErrSessionExpired  = errors.New("session expired")
+)
@@ -6,6 +6,8 @@ import (
 	"embed"
 	"fmt"
 	"io/fs"
+
+	"github.com/jmoiron/sqlx"
 )
 
 type db interface {
@@ -15,6 +17,12 @@ type db interface {
 	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
 }
 
+// Repositories run on a connection pool or inside a transaction, see WithTx.
+var (
+	_ db = (*sqlx.DB)(nil)
+	_ db = (*sqlx.Tx)(nil)
+)
+
 type Repository struct {
 	db db
 }
@@ -25,6 +33,14 @@ func NewRepository(db db) *Repository {
 	}
 }
 
+// WithTx returns a copy of the repository that runs its queries in tx,
+// e.g. inside database.Database.RunInTx. Both *sqlx.DB and *sqlx.Tx can be used.
+func (r *Repository) WithTx(tx db) *Repository {
+	return &Repository{
+		db: tx,
+	}
+}
+
 //go:embed migrations/*.sql
 var migrations embed.FS
 
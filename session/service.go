@@ -9,12 +9,14 @@ import (
 )
 
 type Service struct {
-	repo *Repository
+	repo   *Repository
+	cookie CookieConfig
 }
 
 func NewService(repo *Repository) *Service {
 	return &Service{
-		repo: repo,
+		repo:   repo,
+		cookie: CookieConfig{}.withDefaults(),
 	}
 }
 
@@ -44,12 +46,7 @@ func (s *Service) GetUserIdFromSessionId(ctx context.Context, id string) (string
 }
 
 func (s *Service) CreateSessionForUser(ctx context.Context, userId string) (string, error) {
-	session := &Session{
-		ID:      uuid.NewString(),
-		User:    userId,
-		Created: time.Now(),
-		Expires: time.Now().Add(100 * 24 * time.Hour),
-	}
+	session := newSession(userId)
 
 	err := s.repo.Create(ctx, session)
 	if err != nil {
@@ -62,3 +59,12 @@ func (s *Service) CreateSessionForUser(ctx context.Context, userId string) (stri
 func (s *Service) DeleteSessionsByUserId(ctx context.Context, userId string) error {
 	return s.repo.DeleteByUserId(ctx, userId)
 }
+
+func newSession(userId string) *Session {
+	return &Session{
+		ID:      uuid.NewString(),
+		User:    userId,
+		Created: time.Now(),
+		Expires: time.Now().Add(100 * 24 * time.Hour),
+	}
+}